@@ -0,0 +1,212 @@
+//go:build cgo
+
+// Command capi builds the extractor into a shared library with a C ABI, so other languages can
+// call the parser in-process:
+//
+//	go build -buildmode=c-shared -o libsqlextract.so ./capi
+//
+// which also writes the libsqlextract.h header. A dump is opened once with sde_open and then
+// referred to by its handle. Functions report failures by returning NULL, 0 or -1 and storing a
+// message in *err. Every string returned, including error messages, must be released with sde_free.
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+)
+
+// Dumps opened through sde_open, by handle. Callers may use the library from several threads.
+var (
+	dumpsMu    sync.Mutex
+	dumps            = make(map[int64]*extractor.Dump)
+	nextHandle int64 = 1
+)
+
+func main() {}
+
+// sde_open loads the dump at path, parsed with the named dialect or mysql if dialect is NULL or
+// empty, or with the dialect detected from the dump if it is "auto". It returns a handle greater than zero, or 0 on failure.
+//
+//export sde_open
+func sde_open(path, dialect *C.char, err **C.char) C.longlong {
+	dump, e := extractor.Open(C.GoString(path))
+	if e != nil {
+		setError(err, e)
+		return 0
+	}
+	if name := C.GoString(dialect); name != "" {
+		if e := dump.SetDialect(name); e != nil {
+			setError(err, e)
+			return 0
+		}
+	}
+
+	dumpsMu.Lock()
+	defer dumpsMu.Unlock()
+	handle := nextHandle
+	nextHandle++
+	dumps[handle] = dump
+	return C.longlong(handle)
+}
+
+// sde_close releases a dump opened with sde_open.
+//
+//export sde_close
+func sde_close(handle C.longlong) {
+	dumpsMu.Lock()
+	defer dumpsMu.Unlock()
+	delete(dumps, int64(handle))
+}
+
+// sde_tables returns the names of the tables of a dump as a JSON array.
+//
+//export sde_tables
+func sde_tables(handle C.longlong, err **C.char) *C.char {
+	dump, e := lookupDump(handle)
+	if e != nil {
+		setError(err, e)
+		return nil
+	}
+	tables, e := dump.Tables()
+	if e != nil {
+		setError(err, e)
+		return nil
+	}
+	return jsonString(tables, err)
+}
+
+// sde_columns returns the columns of a table as a JSON array of {"name", "type"} objects.
+//
+//export sde_columns
+func sde_columns(handle C.longlong, table *C.char, err **C.char) *C.char {
+	dump, e := lookupDump(handle)
+	if e != nil {
+		setError(err, e)
+		return nil
+	}
+	columns, e := dump.Columns(C.GoString(table))
+	if e != nil {
+		setError(err, e)
+		return nil
+	}
+	return jsonString(columns, err)
+}
+
+// sde_extract encodes the rows of a table in the named format, json if format is NULL or empty.
+// columns is a comma-separated list of the columns to include, all of them if NULL or empty. The
+// length of the returned buffer is stored in *length, the buffer is also NUL-terminated.
+//
+//export sde_extract
+func sde_extract(handle C.longlong, table, columns, format *C.char, length *C.size_t, err **C.char) *C.char {
+	var buf bytes.Buffer
+	if _, e := extract(handle, table, columns, format, &buf); e != nil {
+		setError(err, e)
+		return nil
+	}
+	if length != nil {
+		*length = C.size_t(buf.Len())
+	}
+	return C.CString(buf.String())
+}
+
+// sde_extract_file is like sde_extract but writes the output to the file at path. It returns the
+// number of rows written, or -1 on failure.
+//
+//export sde_extract_file
+func sde_extract_file(handle C.longlong, table, columns, format, path *C.char, err **C.char) C.longlong {
+	file, e := os.Create(C.GoString(path))
+	if e != nil {
+		setError(err, e)
+		return -1
+	}
+	defer file.Close()
+	count, e := extract(handle, table, columns, format, file)
+	if e == nil {
+		e = file.Close()
+	}
+	if e != nil {
+		setError(err, e)
+		return -1
+	}
+	return C.longlong(count)
+}
+
+// sde_free releases a string returned by the library.
+//
+//export sde_free
+func sde_free(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+// extract writes the rows of a table to w, returning the number of rows written.
+func extract(handle C.longlong, table, columns, format *C.char, w io.Writer) (int, error) {
+	dump, err := lookupDump(handle)
+	if err != nil {
+		return 0, err
+	}
+	formatName := C.GoString(format)
+	if formatName == "" {
+		formatName = "json"
+	}
+	f, err := output.Lookup(formatName)
+	if err != nil {
+		return 0, err
+	}
+	var opts extractor.Options
+	if names := C.GoString(columns); names != "" {
+		opts.Columns = strings.Split(names, ",")
+	}
+
+	tableName := C.GoString(table)
+	it, err := dump.Iterate(tableName, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+	buffered := bufio.NewWriter(w)
+	count, err := output.Copy(f.New(buffered), tableName, it)
+	if err != nil {
+		return count, err
+	}
+	return count, buffered.Flush()
+}
+
+func lookupDump(handle C.longlong) (*extractor.Dump, error) {
+	dumpsMu.Lock()
+	defer dumpsMu.Unlock()
+	dump, ok := dumps[int64(handle)]
+	if !ok {
+		return nil, fmt.Errorf("unknown dump handle %d", int64(handle))
+	}
+	return dump, nil
+}
+
+func setError(err **C.char, e error) {
+	if err != nil {
+		*err = C.CString(e.Error())
+	}
+}
+
+func jsonString(value interface{}, err **C.char) *C.char {
+	data, e := json.Marshal(value)
+	if e != nil {
+		setError(err, e)
+		return nil
+	}
+	return C.CString(string(data))
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/metrics"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+	"github.com/elvisgraho/sql-data-extractor/pkg/pipeline"
+	"gopkg.in/yaml.v3"
+)
+
+// Options a job of a manifest may set, named like the options of extract.
+var jobKeys = map[string]bool{"file": true, "dialect": true, "input-charset": true, "table": true, "column": true, "format": true, "o": true, "script": true}
+
+// batchManifest is the file read by the batch command. Every job is a mapping of extract options,
+// completed by the defaults.
+type batchManifest struct {
+	Defaults map[string]interface{}   `yaml:"defaults"`
+	Jobs     []map[string]interface{} `yaml:"jobs"`
+}
+
+// batchJob is a job of the manifest with its options resolved.
+type batchJob struct {
+	dumpFlags
+	table      string
+	columns    []string
+	format     output.Format
+	output     string
+	hooks      *scriptHooks
+	provenance bool
+}
+
+// runBatch implements the batch command.
+func runBatch(args []string) (err error) {
+	flags := newFlagSet("batch", `Run the extractions of a manifest:
+  Runs every job listed in a YAML manifest as one run, loading each dump only once however many
+  jobs read it, and prints a report of all jobs. A failing job does not stop the others.
+  Relative paths are resolved against the current directory.
+
+  defaults:
+    file: dump.sql
+    format: hashcat
+  jobs:
+    - table: users
+      column: [user_email, user_pass]
+      o: users.txt
+    - file: other.sql
+      table: accounts
+      format: json
+
+Usage:
+  sql-data-extractor batch -manifest <manifest.yaml> [options]
+
+Options:
+  -manifest   The YAML manifest listing the jobs. (required)
+`+summaryFlagUsage+provenanceFlagUsage)
+	manifestFilename := flags.String("manifest", "", "YAML manifest listing the jobs")
+	summaryPath := flags.String("summary", "", "File to write a JSON summary of the run to")
+	withProvenance := flags.Bool("provenance", false, "Write a provenance record next to every output")
+	if err := parseArgs(flags, args); err != nil {
+		return err
+	}
+	if *manifestFilename == "" {
+		flags.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("the -manifest flag is required"))
+	}
+	summary := newRunSummary("batch", &dumpFlags{filename: *manifestFilename})
+	defer func() { err = summary.finish(*summaryPath, err) }()
+
+	jobs, err := loadManifest(*manifestFilename)
+	if err != nil {
+		return withExitCode(exitUsage, fmt.Errorf("Error reading manifest: %s", err))
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	// Dumps are shared by the jobs reading them, keyed by file name and dialect.
+	dumps := make(map[dumpFlags]*extractor.Dump)
+	report := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(report, "JOB\tFILE\tTABLE\tROWS\tOUTPUT\tRESULT")
+	var failed []error
+	for i, job := range jobs {
+		job.provenance = *withProvenance
+		dump, ok := dumps[job.dumpFlags]
+		if !ok {
+			if dump, err = job.open(flags); err != nil {
+				failed = append(failed, err)
+				fmt.Fprintf(report, "%d\t%s\t%s\t-\t%s\t%s\n", i+1, job.filename, job.table, job.output, err)
+				continue
+			}
+			dumps[job.dumpFlags] = dump
+		}
+
+		count, err := job.run(ctx, dump, summary)
+		if isInterrupted(err) {
+			report.Flush()
+			return interrupted(checkpoint{
+				File:    job.filename,
+				Dialect: job.dialect,
+				Table:   job.table,
+				Columns: job.columns,
+				Format:  job.format.Name,
+				Output:  job.output,
+				Rows:    count,
+			})
+		}
+		result := "ok"
+		if err != nil {
+			failed = append(failed, err)
+			result = err.Error()
+		}
+		fmt.Fprintf(report, "%d\t%s\t%s\t%d\t%s\t%s\n", i+1, job.filename, job.table, count, job.output, result)
+	}
+	report.Flush()
+
+	if len(failed) > 0 {
+		return withExitCode(exitCode(failed[0]), fmt.Errorf("%d of %d jobs failed", len(failed), len(jobs)))
+	}
+	banner("%d jobs completed successfully", len(jobs))
+	return nil
+}
+
+// run writes the output of a job, returning the number of rows written. The outcome is added to
+// summary unless it is nil.
+func (job batchJob) run(ctx context.Context, dump *extractor.Dump, summary *runSummary) (int, error) {
+	skipped := newSkippedRows(job.output)
+	it, err := dump.Iterate(job.table, extractor.Options{Columns: job.columns, OnStatement: traceStatement, OnMalformed: skipped.add})
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+	records := pipeline.New(it)
+	if job.hooks != nil {
+		job.hooks.addTo(records)
+	}
+	start := time.Now()
+	count, err := writeToFile(ctx, job.output, job.table, records, job.format)
+	metrics.RowsExtracted.Add(float64(count))
+	metrics.ParseErrors.Add(float64(it.Malformed()), "malformed_row")
+	metrics.ExtractionDuration.ObserveSince(start)
+	if summary != nil {
+		summary.addTable(job.table, job.format.Name, job.output, records, count)
+		summary.Tables[len(summary.Tables)-1].Input = job.filename
+	}
+	if closeErr := skipped.close(); err == nil {
+		err = closeErr
+	}
+	if isInterrupted(err) {
+		return count, err
+	}
+	if err != nil {
+		return count, writeError(job.format, err)
+	}
+	removeCheckpoint(job.output)
+	if job.provenance {
+		if err := writeProvenance(job.dumpFlags, job.table, records, job.format.Name, job.output, count); err != nil {
+			return count, withExitCode(exitIOError, err)
+		}
+	}
+	checkRowCount(it)
+	if err := checkMalformed(job.table, it, skipped); err != nil {
+		return count, err
+	}
+	if count == 0 {
+		return count, withExitCode(exitNoRows, fmt.Errorf("no rows extracted from table %s", job.table))
+	}
+	return count, nil
+}
+
+// loadManifest reads the jobs of a manifest and checks them before any is run, so a mistake
+// in the last job does not surface after the others have already been written.
+func loadManifest(filename string) ([]batchJob, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var manifest batchManifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, err
+	}
+	if len(manifest.Jobs) == 0 {
+		return nil, fmt.Errorf("no jobs listed")
+	}
+
+	outputs := make(map[string]int)
+	scripts := make(map[string]*scriptHooks)
+	jobs := make([]batchJob, len(manifest.Jobs))
+	for i, raw := range manifest.Jobs {
+		values := map[string]string{"dialect": extractor.AutoDetect, "format": "json"}
+		for _, source := range []map[string]interface{}{manifest.Defaults, raw} {
+			for key, value := range source {
+				if !jobKeys[key] {
+					return nil, fmt.Errorf("job %d: unknown option %s", i+1, key)
+				}
+				if values[key], err = configString(key, value); err != nil {
+					return nil, fmt.Errorf("job %d: %s", i+1, err)
+				}
+			}
+		}
+
+		job := batchJob{
+			dumpFlags: dumpFlags{filename: values["file"], dialect: values["dialect"], charset: values["input-charset"]},
+			table:     values["table"],
+			columns:   parseIncludedColumns(values["column"]),
+			output:    values["o"],
+		}
+		if job.filename == "" || job.table == "" {
+			return nil, fmt.Errorf("job %d: both file and table are required", i+1)
+		}
+		if _, err := extractor.LookupDialect(job.dialect); err != nil && job.dialect != extractor.AutoDetect {
+			return nil, fmt.Errorf("job %d: %s", i+1, err)
+		}
+		if job.format, err = output.Lookup(values["format"]); err != nil {
+			return nil, fmt.Errorf("job %d: %s", i+1, err)
+		}
+		if job.output == "" {
+			job.output = defaultOutputFilename(job.filename, job.table, job.format)
+		}
+		if script := values["script"]; script != "" {
+			if _, loaded := scripts[script]; !loaded {
+				if scripts[script], err = loadScript(script); err != nil {
+					return nil, fmt.Errorf("job %d: %s", i+1, err)
+				}
+			}
+			job.hooks = scripts[script]
+		}
+		if other, taken := outputs[job.output]; taken {
+			return nil, fmt.Errorf("jobs %d and %d both write %s", other, i+1, job.output)
+		}
+		outputs[job.output] = i + 1
+		jobs[i] = job
+	}
+	return jobs, nil
+}
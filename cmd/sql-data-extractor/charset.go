@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Charsets -input-charset decodes dumps from, besides UTF-8.
+var charsets = map[string]encoding.Encoding{
+	"latin1":       charmap.Windows1252,
+	"iso-8859-1":   charmap.ISO8859_1,
+	"iso-8859-2":   charmap.ISO8859_2,
+	"iso-8859-15":  charmap.ISO8859_15,
+	"windows-1250": charmap.Windows1250,
+	"windows-1251": charmap.Windows1251,
+	"windows-1252": charmap.Windows1252,
+	"koi8-r":       charmap.KOI8R,
+	"utf-16le":     unicode.UTF16(unicode.LittleEndian, unicode.UseBOM),
+	"utf-16be":     unicode.UTF16(unicode.BigEndian, unicode.UseBOM),
+}
+
+// doubleUTF8 names the repair of text encoded to UTF-8 twice, once too many as if it were latin1.
+const doubleUTF8 = "double-utf-8"
+
+// charsetNames lists the values -input-charset accepts, for its usage.
+func charsetNames() []string {
+	names := []string{"utf-8", doubleUTF8}
+	for name := range charsets {
+		names = append(names, name)
+	}
+	sort.Strings(names[2:])
+	return names
+}
+
+// charsetDecoder returns the transformer converting a dump in the given charset to UTF-8 as it
+// is read, or nil for UTF-8 dumps. Besides a charset name, it takes "utf-8,<charset>" for dumps
+// mixing encodings, whose invalid UTF-8 is decoded from a single-byte charset, and double-utf-8.
+func charsetDecoder(name string) (transform.Transformer, error) {
+	name = strings.ToLower(name)
+	switch {
+	case name == "" || name == "utf-8" || name == "utf8":
+		return nil, nil
+	case name == doubleUTF8:
+		return doubleUTF8Decoder{}, nil
+	case strings.HasPrefix(name, "utf-8,"):
+		fallback, ok := charsets[strings.TrimPrefix(name, "utf-8,")].(*charmap.Charmap)
+		if !ok {
+			return nil, fmt.Errorf("unknown fallback charset in %s, expected a single-byte charset", name)
+		}
+		return mixedDecoder{fallback: fallback}, nil
+	}
+
+	enc, ok := charsets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown charset %s, expected one of %s", name, strings.Join(charsetNames(), ", "))
+	}
+	return enc.NewDecoder(), nil
+}
+
+// decodedFile reads a file through a charset decoder.
+type decodedFile struct {
+	io.Reader
+	io.Closer
+}
+
+// mixedDecoder keeps the valid UTF-8 it reads and decodes every other byte from fallback.
+type mixedDecoder struct {
+	transform.NopResetter
+	fallback *charmap.Charmap
+}
+
+func (d mixedDecoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if c := src[nSrc]; c < utf8.RuneSelf {
+			if nDst == len(dst) {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+			dst[nDst] = c
+			nDst++
+			nSrc++
+			continue
+		}
+		if !atEOF && !utf8.FullRune(src[nSrc:]) {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if r == utf8.RuneError && size == 1 {
+			r = d.fallback.DecodeByte(src[nSrc])
+		}
+		if len(dst)-nDst < utf8.RuneLen(r) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], r)
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
+
+// Runs of non-ASCII bytes longer than this are repaired in parts by doubleUTF8Decoder, rather
+// than waiting for their end.
+const maxDoubleUTF8Run = 1024
+
+// doubleUTF8Decoder repairs text encoded to UTF-8 twice as it is read, run by run of non-ASCII
+// characters like repairDoubleUTF8.
+type doubleUTF8Decoder struct {
+	transform.NopResetter
+}
+
+// doubleUTF8Cut returns where to cut a long run of non-ASCII bytes read by doubleUTF8Decoder:
+// before the last character that starts a character of the original text, so the characters
+// the run encodes are repaired whole.
+func doubleUTF8Cut(run []byte) int {
+	end := len(run)
+	// The last character may be incomplete.
+	for start := end - 1; start >= 0 && start > end-utf8.UTFMax; start-- {
+		if utf8.RuneStart(run[start]) {
+			if !utf8.FullRune(run[start:end]) {
+				end = start
+			}
+			break
+		}
+	}
+	for i := 0; i < utf8.UTFMax && end > 0; i++ {
+		r, size := utf8.DecodeLastRune(run[:end])
+		end -= size
+		original, ok := windows1252Bytes(string(r))
+		if !ok || original[0] >= 0xc0 {
+			break
+		}
+	}
+	if end == 0 {
+		return len(run)
+	}
+	return end
+}
+
+func (doubleUTF8Decoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		end := nSrc
+		for end < len(src) && src[end] < utf8.RuneSelf {
+			end++
+		}
+		if end == nSrc {
+			for end < len(src) && src[end] >= utf8.RuneSelf {
+				end++
+			}
+			if end == len(src) && !atEOF {
+				if end-nSrc < maxDoubleUTF8Run {
+					return nDst, nSrc, transform.ErrShortSrc
+				}
+				end = nSrc + doubleUTF8Cut(src[nSrc:end])
+			}
+		}
+		// Repairing a run never makes it longer.
+		if len(dst)-nDst < end-nSrc {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], repairDoubleUTF8(string(src[nSrc:end])))
+		nSrc = end
+	}
+	return nDst, nSrc, nil
+}
+
+// repairDoubleUTF8 undoes a second UTF-8 encoding: every run of non-ASCII characters is turned
+// back into the bytes windows-1252 has for them, and replaced by them if they are valid UTF-8.
+// Runs that don't form UTF-8, such as a lone "é", are kept.
+func repairDoubleUTF8(s string) string {
+	if !hasNonASCII(s) {
+		return s
+	}
+	var repaired strings.Builder
+	for len(s) > 0 {
+		start := strings.IndexFunc(s, func(r rune) bool { return r >= utf8.RuneSelf })
+		if start < 0 {
+			repaired.WriteString(s)
+			break
+		}
+		repaired.WriteString(s[:start])
+		s = s[start:]
+		end := strings.IndexFunc(s, func(r rune) bool { return r < utf8.RuneSelf })
+		if end < 0 {
+			end = len(s)
+		}
+		run := s[:end]
+		if original, ok := windows1252Bytes(run); ok && utf8.Valid(original) {
+			repaired.Write(original)
+		} else {
+			repaired.WriteString(run)
+		}
+		s = s[end:]
+	}
+	return repaired.String()
+}
+
+// windows1252Bytes encodes s as windows-1252, as MySQL's latin1 does. The bytes windows-1252
+// leaves undefined come out of latin1 as C1 control characters and are mapped back as such.
+func windows1252Bytes(s string) ([]byte, bool) {
+	encoded := make([]byte, 0, len(s))
+	for _, r := range s {
+		if b, ok := charmap.Windows1252.EncodeRune(r); ok {
+			encoded = append(encoded, b)
+		} else if r >= 0x80 && r <= 0xff {
+			encoded = append(encoded, byte(r))
+		} else {
+			return nil, false
+		}
+	}
+	return encoded, true
+}
+
+func hasNonASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
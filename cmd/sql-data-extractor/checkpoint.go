@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// checkpoint records how far an interrupted extraction got. It is written next to the output.
+type checkpoint struct {
+	File        string    `json:"file"`
+	Dialect     string    `json:"dialect"`
+	Table       string    `json:"table"`
+	Columns     []string  `json:"columns,omitempty"`
+	Format      string    `json:"format"`
+	Output      string    `json:"output"`
+	Rows        int       `json:"rows"`
+	Interrupted time.Time `json:"interrupted"`
+
+	// path overrides where the checkpoint is written, for outputs that are not local files.
+	path string
+}
+
+func checkpointPath(outputFilename string) string {
+	return outputFilename + ".checkpoint"
+}
+
+// write saves the checkpoint next to its output and returns the path written.
+func (c checkpoint) write() (string, error) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := c.path
+	if path == "" {
+		path = checkpointPath(c.Output)
+	}
+	return path, os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// removeCheckpoint deletes the checkpoint left by an earlier interrupted run, once the output is complete.
+func removeCheckpoint(outputFilename string) {
+	os.Remove(checkpointPath(outputFilename))
+}
+
+// interruptContext returns a context that is cancelled on SIGINT or SIGTERM. A second signal
+// terminates the process immediately.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// interrupted saves c and returns the error reporting the interruption of its extraction.
+func interrupted(c checkpoint) error {
+	c.Interrupted = time.Now().UTC()
+	path, err := c.write()
+	if err != nil {
+		return withExitCode(exitIOError, fmt.Errorf("interrupted after writing %d rows to %s, error writing checkpoint: %s", c.Rows, c.Output, err))
+	}
+	return withExitCode(exitInterrupted, fmt.Errorf("interrupted after writing %d rows to %s, checkpoint written to %s", c.Rows, c.Output, path))
+}
+
+func isInterrupted(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
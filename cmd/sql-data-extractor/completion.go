@@ -0,0 +1,276 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+)
+
+// Dumps without an index are only parsed for completions up to this size, so pressing tab
+// never stalls on a huge file.
+const maxCompletionParseSize = 64 << 20
+
+// Printed instead of candidates when the shell should complete file names itself.
+const completeFiles = ":files"
+
+// Flags whose values are file or directory names.
+var fileFlags = map[string]bool{"file": true, "o": true, "dir": true, "config": true, "data-dir": true, "summary": true, "manifest": true, "rules": true, "out": true, "done": true, "failed": true, "script": true, "potfile": true, "new": true}
+
+var completionScripts = map[string]string{
+	"bash": `# bash completion for sql-data-extractor
+_sql_data_extractor() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local IFS=$'\n'
+    local candidates=($(sql-data-extractor __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+    if [[ "${candidates[0]}" == "` + completeFiles + `" ]]; then
+        COMPREPLY=($(compgen -f -- "$cur"))
+    else
+        COMPREPLY=("${candidates[@]}")
+    fi
+}
+complete -F _sql_data_extractor sql-data-extractor
+`,
+	"zsh": `#compdef sql-data-extractor
+# zsh completion for sql-data-extractor
+_sql_data_extractor() {
+    local -a candidates
+    candidates=("${(@f)$(sql-data-extractor __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    if [[ "${candidates[1]}" == "` + completeFiles + `" ]]; then
+        _files
+    else
+        compadd -a candidates
+    fi
+}
+compdef _sql_data_extractor sql-data-extractor
+`,
+	"fish": `# fish completion for sql-data-extractor
+function __sql_data_extractor_complete
+    set -l current (commandline -ct)
+    set -l tokens (commandline -opc)[2..-1] "$current"
+    set -l candidates (sql-data-extractor __complete $tokens 2>/dev/null)
+    if test "$candidates[1]" = "` + completeFiles + `"
+        __fish_complete_path "$current"
+    else
+        printf '%s\n' $candidates
+    end
+end
+complete -c sql-data-extractor -f -a '(__sql_data_extractor_complete)'
+`,
+}
+
+// runCompletion implements the completion command, printing the script of a shell.
+func runCompletion(args []string) error {
+	flags := newFlagSet("completion", `Generate shell completion:
+  Prints a completion script for bash, zsh or fish. Besides commands and options, it completes
+  the table and column names of the dump given with -file, using its index when one exists.
+
+Usage:
+  sql-data-extractor completion bash|zsh|fish
+
+  bash: source <(sql-data-extractor completion bash)
+  zsh:  source <(sql-data-extractor completion zsh)
+  fish: sql-data-extractor completion fish | source
+
+Options:
+`)
+	if err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("a shell name is required"))
+	}
+	script, ok := completionScripts[flags.Arg(0)]
+	if !ok {
+		return withExitCode(exitUsage, fmt.Errorf("unsupported shell %s, expected bash, zsh or fish", flags.Arg(0)))
+	}
+	fmt.Print(script)
+	return nil
+}
+
+// runComplete implements the hidden __complete command called by the completion scripts. Its
+// arguments are the words of the command line after the program name, the last one being the
+// word under the cursor. It prints one candidate per line.
+func runComplete(args []string) error {
+	for _, candidate := range completeWords(args) {
+		fmt.Println(candidate)
+	}
+	return nil
+}
+
+func completeWords(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+
+	if len(words) == 1 && !strings.HasPrefix(current, "-") {
+		var names []string
+		for _, cmd := range commands {
+			if cmd.summary != "" {
+				names = append(names, cmd.name)
+			}
+		}
+		return filterPrefix(names, current)
+	}
+
+	cmdName, args := words[0], words[1:]
+	if strings.HasPrefix(cmdName, "-") {
+		cmdName, args = "extract", words
+	}
+	switch cmdName {
+	case "help":
+		return completeWords(args)
+	case "completion":
+		return filterPrefix([]string{"bash", "fish", "zsh"}, current)
+	}
+	cmd, ok := lookupCommand(cmdName)
+	if !ok {
+		return nil
+	}
+	flags := collectFlags(cmd)
+	if flags == nil {
+		return nil
+	}
+
+	if len(args) >= 2 {
+		if f := flags.Lookup(strings.TrimLeft(args[len(args)-2], "-")); f != nil && strings.HasPrefix(args[len(args)-2], "-") && !isBoolFlag(f) {
+			return completeFlagValue(f.Name, args, current)
+		}
+	}
+
+	if strings.HasPrefix(current, "-") {
+		var names []string
+		flags.VisitAll(func(f *flag.Flag) {
+			names = append(names, "-"+f.Name)
+		})
+		return filterPrefix(names, current)
+	}
+	return nil
+}
+
+// completeFlagValue returns the candidates for the value of the named flag.
+func completeFlagValue(name string, args []string, current string) []string {
+	switch {
+	case fileFlags[name]:
+		return []string{completeFiles}
+	case name == "format":
+		return filterPrefix(output.Formats(), current)
+	case name == "dialect":
+		return filterPrefix(extractor.Dialects(), current)
+	case name == "table" || name == "describe":
+		var tables []string
+		for _, table := range completionTables(args) {
+			tables = append(tables, table.Name)
+		}
+		return filterPrefix(tables, current)
+	case name == "column" || name == "mask-stats":
+		tableName := flagValue(args, "table")
+		for _, table := range completionTables(args) {
+			if table.Name != tableName {
+				continue
+			}
+			var columns []string
+			for _, column := range table.Columns {
+				columns = append(columns, column.Name)
+			}
+			// -column takes a comma-separated list, so only the part after the last comma is completed.
+			prefix := current[:strings.LastIndex(current, ",")+1]
+			candidates := filterPrefix(columns, current[len(prefix):])
+			for i := range candidates {
+				candidates[i] = prefix + candidates[i]
+			}
+			return candidates
+		}
+	}
+	return nil
+}
+
+// completionTables describes the tables of the dump named by -file in args, from its index if it is
+// up to date, or by parsing it if it is small enough.
+func completionTables(args []string) []extractor.TableIndex {
+	filename := flagValue(args, "file")
+	if idx := loadFreshIndex(filename); idx != nil {
+		return idx.Tables
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil || info.Size() > maxCompletionParseSize {
+		return nil
+	}
+	dump, err := extractor.Open(filename)
+	if err != nil {
+		return nil
+	}
+	if err := dump.SetDialect(defaultString(flagValue(args, "dialect"), extractor.AutoDetect)); err != nil {
+		return nil
+	}
+
+	schemas, err := dump.Schemas()
+	if err != nil {
+		return nil
+	}
+	var tables []extractor.TableIndex
+	for _, schema := range schemas {
+		tables = append(tables, extractor.TableIndex{Name: schema.Name, Columns: schema.Columns})
+	}
+	return tables
+}
+
+// flagValue returns the value given to the named flag in args, in either -name value or -name=value form.
+func flagValue(args []string, name string) string {
+	for i, arg := range args {
+		trimmed := strings.TrimLeft(arg, "-")
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if trimmed == name && i+1 < len(args) {
+			return args[i+1]
+		}
+		if value, ok := strings.CutPrefix(trimmed, name+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+func filterPrefix(candidates []string, prefix string) []string {
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+// Returned by parseArgs while flags are being collected, stopping the command before it does anything.
+var errFlagsCollected = errors.New("flags collected")
+
+// collecting is set while collectFlags runs a command; parseArgs then stores the command's flag set
+// in collected instead of parsing arguments.
+var (
+	collecting bool
+	collected  *flag.FlagSet
+)
+
+// collectFlags returns the flag set a command defines, without running the command.
+func collectFlags(cmd command) *flag.FlagSet {
+	collecting, collected = true, nil
+	defer func() { collecting = false }()
+	if err := cmd.run(nil); err != errFlagsCollected {
+		return nil
+	}
+	return collected
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Usage line of the -config flag, appended to the help of every command.
+const configFlagUsage = `  -config     YAML file providing values for the options above. Options given on the command line take precedence.
+
+Every option can also be set through an environment variable named SQLEXTRACT_<OPTION>, or
+SQLEXTRACT_<COMMAND>_<OPTION> for this command only, e.g. SQLEXTRACT_FILE. Environment
+variables override the config file and are overridden by the command line.
+`
+
+// Prefix of the environment variables configuring flags, see envValues.
+const envPrefix = "SQLEXTRACT_"
+
+// parseArgs parses the arguments of a command and then fills every flag not given on the
+// command line, first from SQLEXTRACT_* environment variables and then from the -config file,
+// if one was named. Precedence is therefore: command line, environment, config file, default.
+// Finally the logger is set up from the resulting logging flags.
+//
+// The keys of the config file are the flag names of the command. A key whose value is a mapping
+// is a section named after a command, and only applies to that command:
+//
+//	file: dump.sql
+//	extract:
+//	  table: users
+//	  column: [user_email, user_pass]
+//	  format: hashcat
+//
+// Lists are joined with commas, matching flags such as -column.
+func parseArgs(flags *flag.FlagSet, args []string) error {
+	if collecting {
+		collected = flags
+		return errFlagsCollected
+	}
+	flags.Parse(args)
+	if err := resolveFlags(flags); err != nil {
+		return withExitCode(exitUsage, err)
+	}
+	return nil
+}
+
+// resolveFlags fills the flags not given on the command line and sets up logging, see parseArgs.
+func resolveFlags(flags *flag.FlagSet) error {
+	if err := applyValues(flags, envValues(flags), "environment"); err != nil {
+		return err
+	}
+
+	if configFile := flags.Lookup("config").Value.String(); configFile != "" {
+		values, err := loadConfig(configFile, flags.Name())
+		if err != nil {
+			return fmt.Errorf("Error reading config file: %s", err)
+		}
+		if err := applyValues(flags, values, "config file "+configFile); err != nil {
+			return err
+		}
+	}
+	return setupLogging(flags)
+}
+
+// loadConfig reads the values a config file assigns to the flags of the named command.
+func loadConfig(filename, commandName string) (map[string]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	var section map[string]interface{}
+	for key, value := range raw {
+		if nested, ok := value.(map[string]interface{}); ok {
+			if _, known := lookupCommand(key); !known {
+				return nil, fmt.Errorf("unknown command section %s", key)
+			}
+			if key == commandName {
+				section = nested
+			}
+			continue
+		}
+		if values[key], err = configString(key, value); err != nil {
+			return nil, err
+		}
+	}
+	for key, value := range section {
+		if values[key], err = configString(key, value); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// configString renders a config value the way it would be written on the command line.
+func configString(key string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		return "", fmt.Errorf("option %s cannot be a mapping", key)
+	case nil:
+		return "", nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// envValues collects the environment variables naming flags of the command. Each flag can be set
+// with SQLEXTRACT_<FLAG>, or SQLEXTRACT_<COMMAND>_<FLAG> to only affect one command, which wins
+// over the generic variable. Names are upper case with dashes replaced by underscores, e.g.
+// SQLEXTRACT_MASK_STATS or SQLEXTRACT_SERVE_LISTEN.
+func envValues(flags *flag.FlagSet) map[string]string {
+	values := make(map[string]string)
+	flags.VisitAll(func(f *flag.Flag) {
+		name := strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		commandName := strings.ToUpper(flags.Name())
+		if value, ok := os.LookupEnv(envPrefix + commandName + "_" + name); ok {
+			values[f.Name] = value
+		} else if value, ok := os.LookupEnv(envPrefix + name); ok {
+			values[f.Name] = value
+		}
+	})
+	return values
+}
+
+// applyValues sets every flag that was not given on the command line and has an entry in values.
+// source names where the values came from, for error messages.
+func applyValues(flags *flag.FlagSet, values map[string]string, source string) error {
+	given := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	for name, value := range values {
+		if flags.Lookup(name) == nil {
+			return fmt.Errorf("%s: unknown option %s for %s", source, name, flags.Name())
+		}
+		if given[name] {
+			continue
+		}
+		if err := flags.Set(name, value); err != nil {
+			return fmt.Errorf("%s: invalid value %q for option %s: %s", source, value, name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/destination"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+	"github.com/elvisgraho/sql-data-extractor/pkg/where"
+)
+
+// runConvert implements the convert command, writing every table of a dump to its own file in
+// one pass over the dump.
+func runConvert(args []string) (err error) {
+	flags := newFlagSet("convert", fmt.Sprintf(`Convert a whole dump:
+  Writes every table of the dump to <dir>/<table> with the extension of the chosen format, or
+  sends them all to the destination given with -dest.
+
+Usage:
+  sql-data-extractor convert -file <path_to_sql_dump> -dir <output_dir> [options]
+  sql-data-extractor convert -file <path_to_sql_dump> -dest <url> [options]
+
+Options:
+%s  -dir        Directory to write the table files to. Created if missing. (required unless -dest is given)
+%s  -format     Output format, one of: %s. Defaults to json.
+%s%s%s%s%s`, dumpFlagsUsage, whereFlagUsage, strings.Join(output.Formats(), ", "), destFlagUsage, malformedFlagsUsage, scriptFlagUsage, summaryFlagUsage, provenanceFlagUsage))
+	df := addDumpFlags(flags)
+	dir := flags.String("dir", "", "Directory to write the table files to")
+	whereText := flags.String("where", "", "Only write the rows matching a condition")
+	formatName := flags.String("format", "json", "Output format")
+	destURL := flags.String("dest", "", "Database or service to send the tables to")
+	strict := flags.Bool("strict", false, "Stop at the first malformed row")
+	lenient := flags.Bool("lenient", false, "Skip malformed rows and report them, the default")
+	scriptFilename := flags.String("script", "", "Starlark file with filter and on_row hooks")
+	summaryPath := flags.String("summary", "", "File to write a JSON summary of the run to")
+	withProvenance := flags.Bool("provenance", false, "Write a provenance record next to every output")
+	if err := parseArgs(flags, args); err != nil {
+		return err
+	}
+	summary := newRunSummary("convert", df)
+	defer func() { err = summary.finish(*summaryPath, err) }()
+
+	if df.filename == "" || (*dir == "") == (*destURL == "") {
+		flags.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("-file and one of -dir or -dest are required"))
+	}
+	if *destURL != "" && *withProvenance {
+		return withExitCode(exitUsage, fmt.Errorf("-dest cannot be combined with -provenance"))
+	}
+	if *strict && *lenient {
+		return withExitCode(exitUsage, fmt.Errorf("-strict and -lenient cannot be combined"))
+	}
+
+	format, err := output.Lookup(*formatName)
+	if err != nil {
+		return err
+	}
+	var condition *where.Filter
+	if *whereText != "" {
+		if condition, err = where.Parse(*whereText); err != nil {
+			return withExitCode(exitUsage, fmt.Errorf("-where: %s", err))
+		}
+	}
+	var hooks *scriptHooks
+	if *scriptFilename != "" {
+		if hooks, err = loadScript(*scriptFilename); err != nil {
+			return withExitCode(exitUsage, err)
+		}
+	}
+
+	// Installed before the dump is loaded so an early interrupt still ends with a checkpoint.
+	ctx, stop := interruptContext()
+	defer stop()
+
+	dump, err := df.open(flags)
+	if err != nil {
+		return err
+	}
+
+	target := *dir
+	var dest destination.Destination
+	if *destURL != "" {
+		if dest, target, err = openDestination(ctx, *destURL, format, df.dialect); err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := dest.Close(); err == nil && closeErr != nil {
+				err = withExitCode(exitIOError, fmt.Errorf("Error writing to %s: %s", target, closeErr))
+			}
+		}()
+	}
+
+	plan := tablesPlan{format: format, dir: *dir, dest: dest, target: target, where: condition, hooks: hooks, strict: *strict, provenance: *withProvenance, allowEmpty: true}
+	return extractTables(ctx, dump, df, plan, summary)
+}
@@ -0,0 +1,9 @@
+package main
+
+// Usage lines of the flags dropping duplicate rows and limiting the rows written.
+const dedupeFlagsUsage = `  -dedupe     Skip the rows whose written values all equal those of a row written before.
+  -dedupe-disk With -dedupe, keep the rows written in a temporary file instead of memory, for tables with more
+              distinct rows than memory holds.
+  -offset     Skip this many rows before writing any, counted after every other filter.
+  -limit      Write at most this many rows of a table, and stop reading it once they are written.
+`
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+)
+
+// runDiffData implements the diff-data command, comparing a table between two dumps.
+func runDiffData(args []string) error {
+	flags := newFlagSet("diff-data", fmt.Sprintf(`Compare a table between two dumps:
+  Matches the rows of a table in an older and a newer dump by primary key and reports the rows
+  added, removed and changed, with the values that changed. Both dumps are read with the same
+  dialect and charset.
+
+Usage:
+  sql-data-extractor diff-data -file <old_dump> -new <new_dump> -table <table_name> [options]
+
+Options:
+%s  -new        The path to the newer SQL dump. (required)
+  -table      The table to compare. (required)
+  -key        Comma-separated list of columns identifying a row. Defaults to the primary key.
+  -show       Number of changed rows printed with their changes. Defaults to 10.
+  -dir        Directory to write the added, removed and changed rows to, as <table>_added,
+              <table>_removed and <table>_changed. Changed rows are written as they are in the
+              newer dump.
+  -format     Format of the files written to -dir, one of: %s. Defaults to json.
+`, dumpFlagsUsage, strings.Join(output.Formats(), ", ")))
+	df := addDumpFlags(flags)
+	newFilename := flags.String("new", "", "Path to the newer SQL dump")
+	tableName := flags.String("table", "", "Table to compare")
+	keyColumns := flags.String("key", "", "Comma-separated list of columns identifying a row")
+	show := flags.Int("show", 10, "Number of changed rows printed with their changes")
+	dir := flags.String("dir", "", "Directory to write the differing rows to")
+	formatName := flags.String("format", "json", "Format of the files written to -dir")
+	if err := parseArgs(flags, args); err != nil {
+		return err
+	}
+	if df.filename == "" || *newFilename == "" || *tableName == "" {
+		flags.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("-file, -new and -table are required"))
+	}
+	format, err := output.Lookup(*formatName)
+	if err != nil {
+		return err
+	}
+
+	oldDump, err := df.open(flags)
+	if err != nil {
+		return err
+	}
+	newFlags := *df
+	newFlags.filename = *newFilename
+	newDump, err := newFlags.open(flags)
+	if err != nil {
+		return err
+	}
+
+	key := parseIncludedColumns(*keyColumns)
+	if key == nil {
+		if key, err = newDump.PrimaryKey(*tableName); err != nil {
+			return err
+		}
+		if key == nil {
+			return withExitCode(exitUsage, fmt.Errorf("table %s has no primary key, name the columns identifying a row with -key", *tableName))
+		}
+	}
+
+	diff, err := diffTable(oldDump, newDump, *tableName, key)
+	if err != nil {
+		return err
+	}
+	diff.print(os.Stdout, *show)
+
+	if *dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		return withExitCode(exitIOError, err)
+	}
+	outputs := []struct {
+		name    string
+		columns []extractor.Column
+		records []extractor.Record
+	}{
+		{"added", diff.newColumns, diff.added},
+		{"removed", diff.oldColumns, diff.removed},
+		{"changed", diff.newColumns, diff.changedRecords()},
+	}
+	for _, o := range outputs {
+		outputFilename := filepath.Join(*dir, fmt.Sprintf("%s_%s%s", *tableName, o.name, format.Extension))
+		records := &recordList{columns: o.columns, records: o.records}
+		if _, err := writeToFile(context.Background(), outputFilename, *tableName, records, format); err != nil {
+			return writeError(format, err)
+		}
+		banner("%d %s rows written to %s", len(o.records), o.name, outputFilename)
+	}
+	return nil
+}
+
+// rowChange is a row found in both dumps whose values differ.
+type rowChange struct {
+	key      string
+	old, new extractor.Record
+	// columns lists the indexes into new of the values that changed.
+	columns []int
+}
+
+// tableDiff holds the rows of a table that differ between two dumps.
+type tableDiff struct {
+	table                  string
+	key                    []string
+	oldColumns, newColumns []extractor.Column
+	oldRows, newRows       int
+	added, removed         []extractor.Record
+	changed                []rowChange
+	unchanged              int
+	// onlyOld and onlyNew name the columns found in a single dump. The values of these columns
+	// are not compared.
+	onlyOld, onlyNew []string
+}
+
+// diffTable reads the table from the old dump into memory, keyed by the key columns, then
+// streams it from the new dump, matching every row against the old ones. Values are compared by
+// column name, so columns added or reordered between the dumps don't make every row differ.
+func diffTable(oldDump, newDump *extractor.Dump, tableName string, key []string) (*tableDiff, error) {
+	oldIt, err := oldDump.Iterate(tableName, extractor.Options{OnStatement: traceStatement})
+	if err != nil {
+		return nil, err
+	}
+	defer oldIt.Close()
+	newIt, err := newDump.Iterate(tableName, extractor.Options{OnStatement: traceStatement})
+	if err != nil {
+		return nil, err
+	}
+	defer newIt.Close()
+	diff := &tableDiff{table: tableName, key: key, oldColumns: oldIt.Columns(), newColumns: newIt.Columns()}
+
+	oldKey, err := keyIndexes(diff.oldColumns, key)
+	if err != nil {
+		return nil, fmt.Errorf("old dump: %s", err)
+	}
+	newKey, err := keyIndexes(diff.newColumns, key)
+	if err != nil {
+		return nil, fmt.Errorf("new dump: %s", err)
+	}
+	// oldIndex maps every column of the new dump to the same column of the old one, or -1.
+	oldIndex := make([]int, len(diff.newColumns))
+	for i, col := range diff.newColumns {
+		oldIndex[i] = extractor.ColumnIndex(diff.oldColumns, col.Name)
+		if oldIndex[i] < 0 {
+			diff.onlyNew = append(diff.onlyNew, col.Name)
+		}
+	}
+	for _, col := range diff.oldColumns {
+		if extractor.ColumnIndex(diff.newColumns, col.Name) < 0 {
+			diff.onlyOld = append(diff.onlyOld, col.Name)
+		}
+	}
+
+	oldRows := make(map[string]extractor.Record)
+	var order []string
+	duplicates := 0
+	for {
+		record, err := oldIt.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		diff.oldRows++
+		k := rowKey(record, oldKey)
+		if _, seen := oldRows[k]; seen {
+			duplicates++
+		} else {
+			order = append(order, k)
+		}
+		oldRows[k] = record
+	}
+
+	seen := make(map[string]bool, len(oldRows))
+	for {
+		record, err := newIt.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		diff.newRows++
+		k := rowKey(record, newKey)
+		if seen[k] {
+			duplicates++
+			continue
+		}
+		seen[k] = true
+		old, found := oldRows[k]
+		if !found {
+			diff.added = append(diff.added, record)
+			continue
+		}
+		var changed []int
+		for i, field := range record {
+			if oldIndex[i] >= 0 && (old[oldIndex[i]].Value != field.Value || old[oldIndex[i]].Null != field.Null) {
+				changed = append(changed, i)
+			}
+		}
+		if len(changed) == 0 {
+			diff.unchanged++
+		} else {
+			diff.changed = append(diff.changed, rowChange{key: k, old: old, new: record, columns: changed})
+		}
+	}
+	for _, k := range order {
+		if !seen[k] {
+			diff.removed = append(diff.removed, oldRows[k])
+		}
+	}
+
+	if duplicates > 0 {
+		slog.Warn(fmt.Sprintf("%d rows of table %s share their key with an earlier row, only one of them is compared", duplicates, tableName))
+	}
+	if err := checkMalformed(tableName, oldIt, nil); err != nil {
+		slog.Warn("old dump: " + err.Error())
+	}
+	if err := checkMalformed(tableName, newIt, nil); err != nil {
+		slog.Warn("new dump: " + err.Error())
+	}
+	return diff, nil
+}
+
+// keyIndexes returns the indexes of the key columns among columns.
+func keyIndexes(columns []extractor.Column, key []string) ([]int, error) {
+	indexes := make([]int, len(key))
+	for i, name := range key {
+		if indexes[i] = extractor.ColumnIndex(columns, name); indexes[i] < 0 {
+			return nil, fmt.Errorf("key column %s not found in table", name)
+		}
+	}
+	return indexes, nil
+}
+
+// rowKey identifies a row by the values of its key columns, shown as "id=3" or "a=1, b=2".
+func rowKey(record extractor.Record, key []int) string {
+	parts := make([]string, len(key))
+	for i, index := range key {
+		parts[i] = record[index].Name + "=" + record[index].Value
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (d *tableDiff) changedRecords() []extractor.Record {
+	records := make([]extractor.Record, len(d.changed))
+	for i, change := range d.changed {
+		records[i] = change.new
+	}
+	return records
+}
+
+// print writes the number of rows added, removed and changed, followed by the changes of the
+// first show changed rows.
+func (d *tableDiff) print(w io.Writer, show int) {
+	fmt.Fprintf(w, "Table %s, keyed by %s: %d rows before, %d rows after\n\n", d.table, strings.Join(d.key, ", "), d.oldRows, d.newRows)
+	fmt.Fprintf(w, "  Added:      %d\n", len(d.added))
+	fmt.Fprintf(w, "  Removed:    %d\n", len(d.removed))
+	fmt.Fprintf(w, "  Changed:    %d\n", len(d.changed))
+	fmt.Fprintf(w, "  Unchanged:  %d\n", d.unchanged)
+	if len(d.onlyOld) > 0 {
+		fmt.Fprintf(w, "\nColumns removed, not compared: %s\n", strings.Join(d.onlyOld, ", "))
+	}
+	if len(d.onlyNew) > 0 {
+		fmt.Fprintf(w, "\nColumns added, not compared: %s\n", strings.Join(d.onlyNew, ", "))
+	}
+	if len(d.changed) == 0 || show <= 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\nChanged rows:")
+	for i, change := range d.changed {
+		if i == show {
+			fmt.Fprintf(w, "  ... and %d more\n", len(d.changed)-show)
+			break
+		}
+		fmt.Fprintf(w, "  %s\n", change.key)
+		for _, c := range change.columns {
+			field := change.new[c]
+			old := change.old[extractor.ColumnIndex(d.oldColumns, field.Name)]
+			fmt.Fprintf(w, "    %s: %q -> %q\n", field.Name, old.Value, field.Value)
+		}
+	}
+}
+
+// recordList adapts records held in memory to output.Records.
+type recordList struct {
+	columns []extractor.Column
+	records []extractor.Record
+}
+
+func (l *recordList) Columns() []extractor.Column {
+	return l.columns
+}
+
+func (l *recordList) Next() (extractor.Record, error) {
+	if len(l.records) == 0 {
+		return nil, io.EOF
+	}
+	record := l.records[0]
+	l.records = l.records[1:]
+	return record, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// Number of most frequent domains listed in the report.
+const topDomainCount = 50
+
+// domainStats tallies the domains of the email addresses found in a dump.
+type domainStats struct {
+	total   int
+	columns []string
+	domains map[string]int
+}
+
+// tableDomains counts, per column of a table, its non-empty values and the domains of those
+// that are email addresses.
+type tableDomains struct {
+	values  []int
+	domains []map[string]int
+}
+
+// computeDomainStats tallies the domains of the addresses in the email-like columns of the given
+// tables, or of every table if tables is empty: those where at least half of the non-empty values
+// are email addresses.
+func computeDomainStats(dump *extractor.Dump, tables []string) (*domainStats, error) {
+	counts := make(map[*extractor.Iterator]*tableDomains)
+	read, err := scanTables(dump, tables, func(it *extractor.Iterator, record extractor.Record) {
+		c := counts[it]
+		if c == nil {
+			c = &tableDomains{values: make([]int, len(record)), domains: make([]map[string]int, len(record))}
+			for i := range c.domains {
+				c.domains[i] = make(map[string]int)
+			}
+			counts[it] = c
+		}
+		for i, field := range record {
+			value := strings.TrimSpace(field.Value)
+			if value == "" || field.Null {
+				continue
+			}
+			c.values[i]++
+			if emailRegex.MatchString(value) {
+				c.domains[i][strings.ToLower(value[strings.LastIndexByte(value, '@')+1:])]++
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domainStats{domains: make(map[string]int)}
+	for _, it := range read {
+		c := counts[it]
+		if c == nil {
+			continue
+		}
+		for i, column := range it.Columns() {
+			addresses := 0
+			for _, count := range c.domains[i] {
+				addresses += count
+			}
+			if addresses == 0 || addresses*2 < c.values[i] {
+				continue
+			}
+			stats.columns = append(stats.columns, it.Table()+"."+column.Name)
+			stats.total += addresses
+			for domain, count := range c.domains[i] {
+				stats.domains[domain] += count
+			}
+		}
+	}
+	return stats, nil
+}
+
+// print writes the domains as a human readable report, most frequent first.
+func (s *domainStats) print(w io.Writer) {
+	if len(s.columns) == 0 {
+		fmt.Fprintln(w, "No email columns found")
+		return
+	}
+	fmt.Fprintf(w, "Email domains in %s (%d addresses, %d domains)\n\n", strings.Join(s.columns, ", "), s.total, len(s.domains))
+	for i, entry := range sortedCounts(s.domains) {
+		if i == topDomainCount {
+			fmt.Fprintf(w, "  ... %d more domains\n", len(s.domains)-topDomainCount)
+			break
+		}
+		fmt.Fprintf(w, "  %-40s %6.2f%% (%d)\n", entry.key, float64(entry.count)*100/float64(s.total), entry.count)
+	}
+}
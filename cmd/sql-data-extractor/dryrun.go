@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+)
+
+// Number of rows encoded to estimate the output size of a dry run.
+const dryRunSampleRows = 1000
+
+// extractPlan describes what an extraction would do.
+type extractPlan struct {
+	dumpFilename   string
+	dialect        string
+	tableName      string
+	columns        []string
+	format         output.Format
+	outputFilename string
+}
+
+// dryRun validates an extraction plan against the dump and prints it along with the expected
+// number of rows and output size, without writing any output.
+func dryRun(dump *extractor.Dump, plan extractPlan) error {
+	dumpSizeLabel, err := dumpSize(plan.dumpFilename)
+	if err != nil {
+		return err
+	}
+
+	columns, err := checkPlanColumns(dump, plan)
+	if err != nil {
+		return err
+	}
+
+	it, err := dump.Iterate(plan.tableName, extractor.Options{Columns: plan.columns, OnStatement: traceStatement})
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	// Encode a sample of rows to learn the average encoded row size.
+	counter := &countingWriter{}
+	writer := plan.format.New(counter)
+	if err := writer.Begin(plan.tableName, it.Columns()); err != nil {
+		return err
+	}
+	rows := 0
+	for {
+		record, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if rows < dryRunSampleRows {
+			if err := writer.WriteRecord(record); err != nil {
+				return err
+			}
+		}
+		rows++
+	}
+	sampled := min(rows, dryRunSampleRows)
+	if err := writer.End(); err != nil {
+		return err
+	}
+
+	size := counter.n
+	sizeNote := ""
+	if rows > sampled {
+		size = counter.n * int64(rows) / int64(sampled)
+		sizeNote = fmt.Sprintf(" (estimated from the first %d rows)", sampled)
+	}
+
+	selected := "all"
+	if len(plan.columns) > 0 {
+		selected = strings.Join(plan.columns, ", ")
+	}
+
+	fmt.Printf(`Dry run, no output written.
+  Input:     %s (%s, %s)
+  Table:     %s (%d columns)
+  Columns:   %s
+  Format:    %s
+  Output:    %s
+  Rows:      %d
+  Size:      %s%s
+`, plan.dumpFilename, dumpSizeLabel, plan.dialect,
+		plan.tableName, len(columns),
+		selected,
+		plan.format.Name,
+		plan.outputFilename,
+		rows,
+		formatBytes(size), sizeNote)
+
+	if it.Malformed() > 0 {
+		fmt.Printf("  Warning:   %d rows have a different number of values than the table has columns\n", it.Malformed())
+	}
+	return nil
+}
+
+// checkPlanColumns returns the columns of the plan's table, failing if any column of the plan
+// is missing from it.
+func checkPlanColumns(dump *extractor.Dump, plan extractPlan) ([]extractor.Column, error) {
+	columns, err := dump.Columns(plan.tableName)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool)
+	for _, column := range columns {
+		known[column.Name] = true
+	}
+	var missing []string
+	for _, column := range plan.columns {
+		if !known[column] {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, withExitCode(exitUsage, fmt.Errorf("columns not found in table %s: %s", plan.tableName, strings.Join(missing, ", ")))
+	}
+	return columns, nil
+}
+
+// countingWriter discards everything written to it, counting the bytes.
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// dumpSize renders the size of the dump file, as stored, or "stdin" for the dump read from stdin,
+// whose size isn't known before it's read.
+func dumpSize(filename string) (string, error) {
+	if filename == stdinFilename {
+		return "stdin", nil
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return "", withExitCode(exitIOError, err)
+	}
+	return formatBytes(info.Size()), nil
+}
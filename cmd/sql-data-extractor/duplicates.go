@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// Number of most duplicated keys listed in the report.
+const topDuplicateCount = 25
+
+// duplicateStats counts the rows of a table sharing a key, or sharing all their values when no
+// key columns are given.
+type duplicateStats struct {
+	keyColumns []string
+	rows       int
+	// counts holds the number of rows of every key, by its extractor.Record.Hash so memory stays
+	// small when keys are long. Keys seen more than once are kept in full in duplicates.
+	counts     map[[16]byte]int
+	duplicates map[[16]byte]string
+}
+
+// computeDuplicateStats reads a table and counts the rows of every key. Null and empty keys are
+// left out when keying on a single column, where they mean the value is missing.
+func computeDuplicateStats(dump *extractor.Dump, tableName string, keyColumns []string) (*duplicateStats, error) {
+	it, err := dump.Iterate(tableName, extractor.Options{Columns: keyColumns, OnStatement: traceStatement})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	if len(keyColumns) > 0 && len(it.Columns()) != len(keyColumns) {
+		return nil, fmt.Errorf("columns %s not all found in table", strings.Join(keyColumns, ", "))
+	}
+
+	stats := &duplicateStats{
+		keyColumns: keyColumns,
+		counts:     make(map[[16]byte]int),
+		duplicates: make(map[[16]byte]string),
+	}
+	for {
+		record, err := it.Next()
+		if err == io.EOF {
+			return stats, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		stats.rows++
+		if len(keyColumns) == 1 && (record[0].Value == "" || record[0].Null) {
+			continue
+		}
+		sum := record.Hash()
+		stats.counts[sum]++
+		if stats.counts[sum] == 2 {
+			stats.duplicates[sum] = strings.Join(record.Values(), ", ")
+		}
+	}
+}
+
+// print writes the duplicates as a human readable report, most duplicated keys first.
+func (s *duplicateStats) print(w io.Writer, tableName string) {
+	what := "rows"
+	if len(s.keyColumns) > 0 {
+		what = strings.Join(s.keyColumns, ", ")
+	}
+	rowsInGroups := 0
+	groups := make([]keyCount, 0, len(s.duplicates))
+	for sum, key := range s.duplicates {
+		rowsInGroups += s.counts[sum]
+		groups = append(groups, keyCount{key, s.counts[sum]})
+	}
+	// Keys that differ may print alike, so they are sorted as they are rather than counted by
+	// their text.
+	sortKeyCounts(groups)
+
+	fmt.Fprintf(w, "Duplicate %s in %s (%d rows)\n\n", what, tableName, s.rows)
+	fmt.Fprintf(w, "  Distinct values:    %d\n", len(s.counts))
+	fmt.Fprintf(w, "  Duplicated values:  %d\n", len(groups))
+	fmt.Fprintf(w, "  Rows sharing them:  %d (%.2f%%)\n", rowsInGroups, percentOf(rowsInGroups, s.rows))
+	fmt.Fprintf(w, "  Redundant rows:     %d (%.2f%%)\n", rowsInGroups-len(groups), percentOf(rowsInGroups-len(groups), s.rows))
+	if len(groups) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\nMost duplicated:")
+	for i, entry := range groups {
+		if i == topDuplicateCount {
+			break
+		}
+		key := entry.key
+		if len(key) > maxExampleLength*2 {
+			key = key[:maxExampleLength*2] + "..."
+		}
+		fmt.Fprintf(w, "  %6d  %s\n", entry.count, key)
+	}
+}
+
+func percentOf(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) * 100 / float64(total)
+}
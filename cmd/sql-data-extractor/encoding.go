@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// encodingFinding counts the values of a column that are not proper UTF-8.
+type encodingFinding struct {
+	table, column string
+	// invalid values are not UTF-8 at all, doubleEncoded ones are UTF-8 encoded twice, showing
+	// mojibake such as "CafÃ©".
+	invalid, doubleEncoded int
+	// firstRow is the row of the first anomaly, counting from 1, and sample its value.
+	firstRow int
+	sample   string
+}
+
+// encodingReport lists the columns with encoding anomalies, and counts the values with other
+// non-ASCII text to tell a dump in another charset apart from one mixing charsets.
+type encodingReport struct {
+	findings  []*encodingFinding
+	multibyte int
+}
+
+// computeEncodingReport scans every value of the given tables, or of every table if tables is
+// empty, for invalid and double-encoded UTF-8.
+func computeEncodingReport(dump *extractor.Dump, tables []string) (*encodingReport, error) {
+	report := &encodingReport{}
+	// The findings of each table by column.
+	findings := make(map[*extractor.Iterator][]*encodingFinding)
+	read, err := scanTables(dump, tables, func(it *extractor.Iterator, record extractor.Record) {
+		tableFindings := findings[it]
+		if tableFindings == nil {
+			tableFindings = make([]*encodingFinding, len(record))
+			findings[it] = tableFindings
+		}
+		for i, field := range record {
+			if !hasNonASCII(field.Value) {
+				continue
+			}
+			var invalid, doubleEncoded bool
+			if !utf8.ValidString(field.Value) {
+				invalid = true
+			} else if repairDoubleUTF8(field.Value) != field.Value {
+				doubleEncoded = true
+			} else {
+				report.multibyte++
+				continue
+			}
+
+			if tableFindings[i] == nil {
+				// The record was counted by Rows already.
+				tableFindings[i] = &encodingFinding{table: it.Table(), column: it.Columns()[i].Name, firstRow: it.Rows(), sample: encodingSample(field.Value)}
+			}
+			if invalid {
+				tableFindings[i].invalid++
+			}
+			if doubleEncoded {
+				tableFindings[i].doubleEncoded++
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, it := range read {
+		for _, finding := range findings[it] {
+			if finding != nil {
+				report.findings = append(report.findings, finding)
+			}
+		}
+	}
+	return report, nil
+}
+
+// encodingSample shows an anomalous value: invalid bytes escaped, double-encoded text along with
+// its repair.
+func encodingSample(value string) string {
+	if !utf8.ValidString(value) {
+		if len(value) > maxExampleLength {
+			value = value[:maxExampleLength]
+		}
+		quoted := fmt.Sprintf("%+q", value)
+		return quoted[1 : len(quoted)-1]
+	}
+	if runes := []rune(value); len(runes) > maxExampleLength {
+		value = string(runes[:maxExampleLength])
+	}
+	return value + " -> " + repairDoubleUTF8(value)
+}
+
+// suggestions returns the -input-charset settings that would fix the anomalies found.
+func (r *encodingReport) suggestions() []string {
+	var invalid, doubleEncoded int
+	for _, f := range r.findings {
+		invalid += f.invalid
+		doubleEncoded += f.doubleEncoded
+	}
+	var suggestions []string
+	switch {
+	case invalid > 0 && r.multibyte == 0 && doubleEncoded == 0:
+		suggestions = append(suggestions, "-input-charset windows-1252 (the dump is not UTF-8, windows-1252 is MySQL's latin1)")
+	case invalid > 0:
+		suggestions = append(suggestions, "-input-charset utf-8,windows-1252 (the dump mixes UTF-8 with another charset)")
+	}
+	if doubleEncoded > 0 {
+		suggestions = append(suggestions, "-input-charset "+doubleUTF8+" (text was encoded to UTF-8 twice)")
+	}
+	return suggestions
+}
+
+// print writes the findings as an aligned table, followed by the suggested settings.
+func (r *encodingReport) print(w io.Writer) {
+	if len(r.findings) == 0 {
+		fmt.Fprintln(w, "No encoding anomalies found")
+		return
+	}
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TABLE\tCOLUMN\tINVALID\tDOUBLE-ENCODED\tFIRST ROW\tSAMPLE")
+	for _, f := range r.findings {
+		fmt.Fprintf(table, "%s\t%s\t%d\t%d\t%d\t%s\n", f.table, f.column, f.invalid, f.doubleEncoded, f.firstRow, f.sample)
+	}
+	table.Flush()
+
+	fmt.Fprintln(w, "\nSuggested:")
+	for _, suggestion := range r.suggestions() {
+		fmt.Fprintf(w, "  %s\n", suggestion)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+
+	_ "modernc.org/sqlite"
+)
+
+// openEngine loads the named tables of the dump into an in-memory SQLite database, so they can
+// be queried with SQL. Values are stored as text, in columns whose affinity follows the type
+// declared in the dump, which lets SQLite compare and sort numeric columns as numbers.
+func openEngine(dump *extractor.Dump, tables []string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	// Every connection to :memory: opens a database of its own.
+	db.SetMaxOpenConns(1)
+
+	for _, table := range tables {
+		if err := loadTable(db, dump, table); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+func loadTable(db *sql.DB, dump *extractor.Dump, tableName string) error {
+	it, err := dump.Iterate(tableName, extractor.Options{OnStatement: traceStatement})
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	columns := it.Columns()
+
+	definitions := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	for i, column := range columns {
+		definitions[i] = quoteSQLite(column.Name) + " " + output.SQLiteAffinity(column.Type)
+		placeholders[i] = "?"
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(fmt.Sprintf("CREATE TABLE %s (%s)", quoteSQLite(tableName), strings.Join(definitions, ", "))); err != nil {
+		return fmt.Errorf("Error creating table %s: %s", tableName, err)
+	}
+	insert, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s VALUES (%s)", quoteSQLite(tableName), strings.Join(placeholders, ", ")))
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	values := make([]interface{}, len(columns))
+	for {
+		record, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		for i, field := range record {
+			values[i] = field.Value
+			if field.Null {
+				values[i] = nil
+			}
+		}
+		if _, err := insert.Exec(values...); err != nil {
+			return fmt.Errorf("Error loading table %s: %s", tableName, err)
+		}
+	}
+	if err := checkMalformed(tableName, it, nil); err != nil {
+		slog.Warn(err.Error())
+	}
+	slog.Debug("table loaded", "table", tableName, "rows", it.Rows())
+	return tx.Commit()
+}
+
+func quoteSQLite(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+)
+
+// Number of rows read to project the size and runtime of an extraction.
+const estimateSampleRows = 10000
+
+// Size of the buffer the extractor reads dumps through, counted in the memory an extraction needs.
+const dumpBufferSize = 1 << 20
+
+// formatEstimate is the projected output of a table in one format.
+type formatEstimate struct {
+	name    string
+	size    int64
+	runtime time.Duration
+	err     error
+}
+
+// estimate reads a sample of the plan's table and projects, for every registered format, the
+// size of the output and how long writing it takes, along with the memory the extraction needs.
+// Sizes are the encoded size of the sample scaled to the table, on top of the size of an empty
+// output, so headers, footers and the fixed size of database files are only counted once.
+func estimate(dump *extractor.Dump, plan extractPlan) error {
+	dumpSizeLabel, err := dumpSize(plan.dumpFilename)
+	if err != nil {
+		return err
+	}
+	if _, err := checkPlanColumns(dump, plan); err != nil {
+		return err
+	}
+
+	// Statements are seen whole, so their sizes and row counts give the size of a row in the dump
+	// even though the last one is only partly read. A statement is split into rows as it is
+	// reached, which takes time by its size rather than by the rows read from it, so that time is
+	// projected separately: it is measured up to the statement being reported.
+	var statementBytes, statementRows, longestStatement int
+	var nextStarted time.Time
+	var statementTime time.Duration
+	// Locating the table in the dump takes as long for the extraction, and is counted once.
+	lookupStarted := time.Now()
+	it, err := dump.Iterate(plan.tableName, extractor.Options{
+		Columns: plan.columns,
+		OnStatement: func(info extractor.StatementInfo) {
+			statementTime += time.Since(nextStarted)
+			statementBytes += info.Length
+			statementRows += info.Rows
+			longestStatement = max(longestStatement, info.Length)
+			traceStatement(info)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	lookupTime := time.Since(lookupStarted)
+
+	started := time.Now()
+	var sample []extractor.Record
+	complete := false
+	for len(sample) < estimateSampleRows {
+		nextStarted = time.Now()
+		record, err := it.Next()
+		if err == io.EOF {
+			complete = true
+			break
+		}
+		if err != nil {
+			return err
+		}
+		sample = append(sample, record)
+	}
+	parseTime := time.Since(started)
+	// Reading one row more tells whether the sample holds the whole table.
+	if !complete {
+		if _, err := it.Next(); err == io.EOF {
+			complete = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	rows, rowsNote := len(sample), "all rows read"
+	scale, byteScale := 1.0, 1.0
+	if !complete {
+		tableSize, err := dump.TableSize(plan.tableName)
+		if err != nil {
+			return err
+		}
+		byteScale = float64(tableSize) / float64(statementBytes)
+		if declared, ok := dump.DeclaredRows(plan.tableName); ok && !declared.Approximate {
+			rows, rowsNote = declared.Rows, "as stated in the dump"
+		} else {
+			rows, rowsNote = tableSize*statementRows/statementBytes, "projected from the size of the table"
+		}
+		scale = float64(rows) / float64(len(sample))
+	}
+	parseTime = lookupTime + time.Duration(float64(statementTime)*byteScale+float64(parseTime-statementTime)*scale)
+
+	var estimates []formatEstimate
+	for _, name := range output.Formats() {
+		format, _ := output.Lookup(name)
+		estimates = append(estimates, estimateFormat(format, plan.tableName, it.Columns(), sample, scale, parseTime))
+	}
+
+	// The dump is read one statement at a time, each assembled from the buffer then copied into a
+	// string. Rows are streamed, so little more is needed while writing.
+	memory := int64(dumpBufferSize + 2*longestStatement)
+
+	rowsLabel := fmt.Sprintf("%d", rows)
+	if !complete {
+		rowsLabel = "~" + rowsLabel
+	}
+	fmt.Printf(`Estimate, no output written.
+  Input:     %s (%s, %s)
+  Table:     %s
+  Rows:      %s (%s, %d sampled)
+  Parsing:   %s
+  Memory:    %s peak, the longest statement is held in memory
+`, plan.dumpFilename, dumpSizeLabel, plan.dialect,
+		plan.tableName,
+		rowsLabel, rowsNote, len(sample),
+		formatDuration(parseTime),
+		formatBytes(memory))
+
+	fmt.Println()
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "FORMAT\tSIZE\tRUNTIME")
+	for _, e := range estimates {
+		if e.err != nil {
+			fmt.Fprintf(table, "%s\t-\t-\t(%s)\n", e.name, e.err)
+			continue
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", e.name, formatBytes(e.size), formatDuration(e.runtime))
+	}
+	return table.Flush()
+}
+
+// estimateFormat encodes the sample in the given format, and once without rows, to project the
+// size and runtime of writing the whole table, scale times the sample. Runtime adds the projected
+// parseTime, and counts the setup of the empty output, such as creating a database, only once.
+func estimateFormat(format output.Format, tableName string, columns []extractor.Column, sample []extractor.Record, scale float64, parseTime time.Duration) formatEstimate {
+	e := formatEstimate{name: format.Name}
+	empty, setupTime, err := encodeSample(format, tableName, columns, nil)
+	if err != nil {
+		e.err = err
+		return e
+	}
+	full, encodeTime, err := encodeSample(format, tableName, columns, sample)
+	if err != nil {
+		e.err = err
+		return e
+	}
+	e.size = empty + int64(float64(full-empty)*scale)
+	e.runtime = parseTime + setupTime + time.Duration(float64(max(encodeTime-setupTime, 0))*scale)
+	return e
+}
+
+// encodeSample writes records in the given format, returning the size of the output and the
+// time taken.
+func encodeSample(format output.Format, tableName string, columns []extractor.Column, records []extractor.Record) (int64, time.Duration, error) {
+	started := time.Now()
+	counter := &countingWriter{}
+	_, err := output.Copy(format.New(counter), tableName, &recordList{columns: columns, records: records})
+	return counter.n, time.Since(started), err
+}
+
+// formatDuration rounds a duration to a precision fitting its length, e.g. "1h12m0s" or "350ms".
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Minute:
+		return d.Round(time.Second).String()
+	case d >= time.Second:
+		return d.Round(100 * time.Millisecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// Exit codes, allowing wrapper scripts to tell outcomes apart.
+const (
+	exitOK            = 0
+	exitFailure       = 1 // any failure not covered below
+	exitUsage         = 2 // invalid command line, also used by the flag package
+	exitTableNotFound = 3
+	exitParseError    = 4 // the dump could not be parsed, or rows were malformed
+	exitNoRows        = 5 // the extraction succeeded but produced no rows
+	exitIOError       = 6 // reading the dump or writing the output failed
+	exitInterrupted   = 130
+)
+
+// Usage lines documenting the exit codes, appended to the main usage.
+const exitCodesUsage = `Exit codes:
+  0  success
+  1  other failure
+  2  invalid command line
+  3  table not found
+  4  parse errors encountered
+  5  zero rows extracted
+  6  I/O failure reading the dump or writing output
+  130 interrupted, the partial output is valid and a checkpoint was written
+`
+
+// exitError attaches an exit code to an error.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns the code the process should exit with after a command returned err.
+func exitCode(err error) int {
+	var exitErr *exitError
+	var notFound *extractor.TableNotFoundError
+	var parseErr *extractor.ParseError
+	var readErr *extractor.ReadError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &exitErr):
+		return exitErr.code
+	case errors.As(err, &notFound):
+		return exitTableNotFound
+	case errors.As(err, &parseErr):
+		return exitParseError
+	case errors.As(err, &readErr):
+		return exitIOError
+	default:
+		return exitFailure
+	}
+}
@@ -0,0 +1,455 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/destination"
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+	"github.com/elvisgraho/sql-data-extractor/pkg/pipeline"
+	"github.com/elvisgraho/sql-data-extractor/pkg/transform"
+	"github.com/elvisgraho/sql-data-extractor/pkg/where"
+)
+
+// runExtract implements the extract command.
+func runExtract(args []string) (err error) {
+	flags := newFlagSet("extract", fmt.Sprintf(`Extract the rows of a table:
+  Writes the data of one or more tables as JSON, JSON lines, CSV, in a format suitable for Hashcat, or in any
+  other registered format.
+
+Usage:
+  sql-data-extractor extract -file <path_to_sql_dump> -table <table_name>[,<table_name>...] [options]
+  sql-data-extractor extract -file <path_to_sql_dump> -all-tables [options]
+
+Options:
+%s  -table      The name of the table from which to extract data, or a comma-separated list of tables to
+              extract in one pass over the dump. (required unless -all-tables is given)
+  -all-tables Extract every table of the dump in one pass.
+  -dir        With several tables, directory to write a file per table to, named <table> with the extension
+              of the format. Defaults to writing <dump>_<table> files next to the dump.
+  -combined   With several tables, write them all to one JSON file, -o or <dump>_tables.json by default, as
+              an object holding the rows of every table under its name.
+  -column     Comma-separated list of column names to include in the output, in the order given. If omitted, all
+              columns will be included in the order of the table.
+%s%s  -format     Output format, one of: %s. Defaults to json.
+  -hashcat    Deprecated, use -format hashcat - value1:value2.
+  -separator  With -format hashcat, the separator written between the values of a row. Defaults to ':'.
+  -skip-empty Skip the rows in which any of the columns written is empty or NULL.
+%s  -o          Output file, or - for stdout. Defaults to <dump>_<table> with the extension of the format.
+  -list-tables Instead of extracting, print the name of every table of the dump, as the list command does.
+  -describe   Instead of extracting, print the columns and types of a table along with its number of rows, as
+              schema -rows does.
+  -subject    Instead of a table, export every row referencing a person, given as column=value
+              like email=alice@example.com, directly or through foreign keys, as one JSON report.
+              Written to <dump>_subject.json unless -o is given.
+%s%s%s  -dry-run    Validate the table and columns and print the expected rows and output size without writing anything.
+  -estimate   Read a sample of the table and print the projected output size and runtime of every format, and
+              the memory needed, without writing anything.
+%s%s%s`, dumpFlagsUsage, whereFlagUsage, transformFlagUsage, strings.Join(output.Formats(), ", "), dedupeFlagsUsage, destFlagUsage, workersFlagsUsage, malformedFlagsUsage, scriptFlagUsage, summaryFlagUsage, provenanceFlagUsage))
+	df := addDumpFlags(flags)
+	tableName := flags.String("table", "", "Name of the table to extract data from")
+	allTables := flags.Bool("all-tables", false, "Extract every table of the dump")
+	dir := flags.String("dir", "", "Directory to write a file per table to")
+	combined := flags.Bool("combined", false, "Write several tables to one JSON file")
+	includeColumns := flags.String("column", "", "Comma-separated list of column names to include in the output")
+	whereText := flags.String("where", "", "Only write the rows matching a condition")
+	transformText := flags.String("transform", "", "Transforms of the values of columns, as column=transform pairs")
+	formatName := flags.String("format", "json", "Output format")
+	hashcat := flags.Bool("hashcat", false, "Deprecated, use -format hashcat")
+	separator := flags.String("separator", ":", "Separator of the values of hashcat output")
+	skipEmpty := flags.Bool("skip-empty", false, "Skip the rows with an empty or NULL value")
+	dedupe := flags.Bool("dedupe", false, "Skip the rows equal to a row written before")
+	dedupeDisk := flags.Bool("dedupe-disk", false, "With -dedupe, keep the rows written in a temporary file")
+	offset := flags.Int("offset", 0, "Number of rows to skip before writing any")
+	limit := flags.Int("limit", 0, "Maximum number of rows to write per table")
+	outputFilename := flags.String("o", "", "Output file")
+	listTablesFlag := flags.Bool("list-tables", false, "Print the tables of the dump instead of extracting")
+	describeFlag := flags.String("describe", "", "Print the columns and row count of a table instead of extracting")
+	subjectFlag := flags.String("subject", "", "Export the rows referencing column=value from all tables")
+	destURL := flags.String("dest", "", "Database or service to send the rows to")
+	workers := flags.Int("workers", 1, "Number of goroutines parsing the rows of the table")
+	unordered := flags.Bool("unordered", false, "With -workers, write rows out of the order of the dump")
+	withProgress := flags.Bool("progress", false, "Print the progress of the extraction to stderr")
+	strict := flags.Bool("strict", false, "Stop at the first malformed row")
+	lenient := flags.Bool("lenient", false, "Skip malformed rows and report them, the default")
+	dryRunFlag := flags.Bool("dry-run", false, "Print the extraction plan without writing output")
+	estimateFlag := flags.Bool("estimate", false, "Print projected output sizes and runtimes without writing output")
+	scriptFilename := flags.String("script", "", "Starlark file with filter and on_row hooks")
+	summaryPath := flags.String("summary", "", "File to write a JSON summary of the run to")
+	withProvenance := flags.Bool("provenance", false, "Write a provenance record next to the output")
+	if err := parseArgs(flags, args); err != nil {
+		return err
+	}
+	summary := newRunSummary("extract", df)
+	defer func() { err = summary.finish(*summaryPath, err) }()
+
+	if *listTablesFlag || *describeFlag != "" {
+		if others := flagsOutside(flags, "list-tables", "describe", "summary"); len(others) > 0 || *listTablesFlag && *describeFlag != "" {
+			return withExitCode(exitUsage, fmt.Errorf("-list-tables and -describe can't be combined with each other or other options of extract%s", flagsLabel(others)))
+		}
+		if *listTablesFlag {
+			return listTables(df, flags)
+		}
+		return describeTables(df, flags, *describeFlag, true)
+	}
+
+	if *subjectFlag != "" {
+		if others := flagsOutside(flags, "subject", "o", "summary"); len(others) > 0 {
+			return withExitCode(exitUsage, fmt.Errorf("-subject can only be combined with -o%s", flagsLabel(others)))
+		}
+		return extractSubject(df, flags, *subjectFlag, *outputFilename)
+	}
+
+	// Check for mandatory flags and if not present, print usage
+	if df.filename == "" || *tableName == "" && !*allTables {
+		flags.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("both -file and -table flags are required"))
+	}
+	tableNames := parseIncludedColumns(*tableName)
+	severalTables := *allTables || len(tableNames) > 1
+	switch {
+	case *allTables && *tableName != "":
+		return withExitCode(exitUsage, fmt.Errorf("-table and -all-tables cannot be combined"))
+	case !severalTables && (*dir != "" || *combined):
+		return withExitCode(exitUsage, fmt.Errorf("-dir and -combined require several tables or -all-tables"))
+	case severalTables && (*includeColumns != "" || *destURL != "" || *dryRunFlag || *estimateFlag):
+		return withExitCode(exitUsage, fmt.Errorf("-column, -dest, -dry-run and -estimate require a single -table"))
+	case severalTables && (*workers != 1 || *unordered || *withProgress):
+		return withExitCode(exitUsage, fmt.Errorf("-workers, -unordered and -progress require a single -table"))
+	case *workers < 1:
+		return withExitCode(exitUsage, fmt.Errorf("-workers must be at least 1"))
+	case *unordered && *workers == 1:
+		return withExitCode(exitUsage, fmt.Errorf("-unordered requires -workers"))
+	case *strict && *lenient:
+		return withExitCode(exitUsage, fmt.Errorf("-strict and -lenient cannot be combined"))
+	case *dedupeDisk && !*dedupe:
+		return withExitCode(exitUsage, fmt.Errorf("-dedupe-disk requires -dedupe"))
+	case *offset < 0 || *limit < 0:
+		return withExitCode(exitUsage, fmt.Errorf("-offset and -limit can't be negative"))
+	case *combined && (*dir != "" || *withProvenance):
+		return withExitCode(exitUsage, fmt.Errorf("-combined cannot be combined with -dir or -provenance"))
+	case *outputFilename == stdoutFilename && (severalTables || *withProvenance):
+		return withExitCode(exitUsage, fmt.Errorf("-o - requires a single -table and can't be combined with -provenance"))
+	case severalTables && !*combined && *outputFilename != "":
+		return withExitCode(exitUsage, fmt.Errorf("-o with several tables requires -combined, -dir sets where the files of the tables go"))
+	}
+	if *hashcat {
+		if *formatName != "json" && *formatName != "hashcat" {
+			return withExitCode(exitUsage, fmt.Errorf("-hashcat cannot be combined with -format %s", *formatName))
+		}
+		slog.Warn("-hashcat is deprecated, use -format hashcat")
+		*formatName = "hashcat"
+	}
+	if *dryRunFlag && *estimateFlag {
+		return withExitCode(exitUsage, fmt.Errorf("-dry-run and -estimate cannot be combined"))
+	}
+	if *destURL != "" && (*outputFilename != "" || *withProvenance) {
+		return withExitCode(exitUsage, fmt.Errorf("-dest cannot be combined with -o or -provenance"))
+	}
+
+	format, err := output.Lookup(*formatName)
+	if err != nil {
+		return err
+	}
+	if *combined && format.Name != "json" {
+		return withExitCode(exitUsage, fmt.Errorf("-combined requires -format json"))
+	}
+	if *separator != ":" {
+		if format.Name != "hashcat" {
+			return withExitCode(exitUsage, fmt.Errorf("-separator requires -format hashcat"))
+		}
+		format = output.Hashcat(*separator)
+	}
+	var condition *where.Filter
+	if *whereText != "" {
+		if condition, err = where.Parse(*whereText); err != nil {
+			return withExitCode(exitUsage, fmt.Errorf("-where: %s", err))
+		}
+	}
+	var transforms *transform.Set
+	if *transformText != "" {
+		if transforms, err = transform.Parse(*transformText); err != nil {
+			return withExitCode(exitUsage, fmt.Errorf("-transform: %s", err))
+		}
+	}
+	var hooks *scriptHooks
+	if *scriptFilename != "" {
+		if hooks, err = loadScript(*scriptFilename); err != nil {
+			return withExitCode(exitUsage, err)
+		}
+	}
+
+	// Installed before the dump is loaded so an early interrupt still ends with a checkpoint.
+	ctx, stop := interruptContext()
+	defer stop()
+
+	dump, err := df.open(flags)
+	if err != nil {
+		return err
+	}
+
+	if severalTables {
+		plan := tablesPlan{tableNames: tableNames, format: format, dir: *dir, transforms: transforms, where: condition, hooks: hooks, skipEmpty: *skipEmpty, dedupe: *dedupe, dedupeDisk: *dedupeDisk, offset: *offset, limit: *limit, strict: *strict, provenance: *withProvenance}
+		if *combined {
+			plan.combined = *outputFilename
+			if plan.combined == "" {
+				plan.combined = defaultOutputFilename(df.filename, "tables", format)
+			}
+		}
+		return extractTables(ctx, dump, df, plan, summary)
+	}
+
+	if *outputFilename == "" {
+		*outputFilename = defaultOutputFilename(df.filename, *tableName, format)
+	}
+
+	plan := extractPlan{
+		dumpFilename:   df.filename,
+		dialect:        df.dialect,
+		tableName:      *tableName,
+		columns:        parseIncludedColumns(*includeColumns),
+		format:         format,
+		outputFilename: *outputFilename,
+	}
+	if *dryRunFlag {
+		return dryRun(dump, plan)
+	}
+	if *estimateFlag {
+		return estimate(dump, plan)
+	}
+
+	// Reports and checkpoints of output written to stdout are kept next to the dump.
+	reportsOf := *outputFilename
+	if *outputFilename == stdoutFilename {
+		reportsOf = defaultOutputFilename(df.filename, *tableName, format)
+	}
+	skipped := newSkippedRows(reportsOf)
+	// Columns are selected by the pipeline, after -transform and -where saw every column.
+	opts := extractor.Options{OnStatement: traceStatement, OnMalformed: skipped.add, Strict: *strict, Workers: *workers, Unordered: *unordered}
+	if *strict {
+		// The row stopped at is the error, and there is no report.
+		opts.OnMalformed = nil
+	}
+	var meter *progressMeter
+	if *withProgress {
+		meter = newProgressMeter(df)
+		opts.OnStatement = meter.statement
+	}
+	it, err := dump.Iterate(*tableName, opts)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	records := pipeline.New(it)
+	defer records.Close()
+	if transforms != nil {
+		if missing := transforms.AddTo(records); len(missing) > 0 {
+			return withExitCode(exitUsage, fmt.Errorf("-transform refers to columns not in table %s: %s", *tableName, strings.Join(missing, ", ")))
+		}
+	}
+	if condition != nil {
+		warnWhereColumns(condition.AddTo(records), *tableName)
+	}
+	if columns := parseIncludedColumns(*includeColumns); len(columns) > 0 {
+		records.AddProjection(columns)
+	}
+	if hooks != nil {
+		hooks.addTo(records)
+	}
+	if *skipEmpty {
+		records.AddSkipEmpty()
+	}
+	if *dedupe {
+		records.AddDedupe(*dedupeDisk)
+	}
+	if *offset > 0 {
+		records.AddOffset(*offset)
+	}
+	if *limit > 0 {
+		records.SetLimit(*limit)
+	}
+
+	// Rows are counted for -progress as the output takes them.
+	var written output.Records = records
+	if meter != nil {
+		written = meter.records(records)
+	}
+	target := *outputFilename
+	if *outputFilename == stdoutFilename {
+		target = "stdout"
+	}
+	var count int
+	if *destURL != "" {
+		var dest destination.Destination
+		var name string
+		if dest, name, err = openDestination(ctx, *destURL, format, df.dialect); err != nil {
+			return err
+		}
+		target = name
+		if meter != nil {
+			meter.start()
+		}
+		count, err = writeToDestination(ctx, dest, target, *tableName, written)
+		if closeErr := dest.Close(); err == nil && closeErr != nil {
+			err = withExitCode(exitIOError, fmt.Errorf("Error writing to %s: %s", target, closeErr))
+		}
+	} else {
+		if meter != nil {
+			meter.start()
+		}
+		count, err = writeToFile(ctx, *outputFilename, *tableName, written, format)
+		if err != nil && !isInterrupted(err) {
+			err = writeError(format, err)
+		}
+	}
+	if meter != nil {
+		meter.stop()
+	}
+	summary.addTable(*tableName, format.Name, target, records, count)
+	if closeErr := skipped.close(); err == nil {
+		err = closeErr
+	}
+	if isInterrupted(err) {
+		return interrupted(checkpoint{
+			File:    df.filename,
+			Dialect: df.dialect,
+			Table:   *tableName,
+			Columns: parseIncludedColumns(*includeColumns),
+			Format:  format.Name,
+			Output:  target,
+			Rows:    count,
+			path:    checkpointPath(reportsOf),
+		})
+	}
+	if err != nil {
+		return err
+	}
+	removeCheckpoint(reportsOf)
+	if *withProvenance {
+		if err := writeProvenance(*df, *tableName, records, format.Name, *outputFilename, count); err != nil {
+			return withExitCode(exitIOError, err)
+		}
+	}
+
+	banner("Data successfully written to %s", target)
+	if !records.Limited() {
+		checkRowCount(it)
+	}
+	if err := checkMalformed(*tableName, it, skipped); err != nil {
+		return err
+	}
+	if count == 0 {
+		return withExitCode(exitNoRows, fmt.Errorf("no rows extracted from table %s", *tableName))
+	}
+	return nil
+}
+
+// checkRowCount warns if the number of rows read from a table differs from the count the dump
+// states for it, which points at a dump the parser misreads. Approximate counts are only logged.
+// The count is the one stated in the part of the dump the iterator read.
+func checkRowCount(it *extractor.Iterator) {
+	tableName := it.Table()
+	declared, ok := it.DeclaredRows()
+	if !ok {
+		return
+	}
+	read := it.Rows() + it.Malformed()
+	switch {
+	case declared.Approximate:
+		slog.Debug("row count compared", "table", tableName, "rows", read, "declared", declared.Rows, "approximate", true)
+	case read != declared.Rows:
+		slog.Warn(fmt.Sprintf("%d rows read from table %s, but the dump states it has %d", read, tableName, declared.Rows))
+	}
+}
+
+// checkMalformed reports rows of the table that were skipped because their values did not match
+// its columns or couldn't be read. The first rows of the report skipped, if there is one, are
+// logged and the error points to it for the rest.
+func checkMalformed(tableName string, it *extractor.Iterator, skipped *skippedRows) error {
+	if it.Malformed() == 0 {
+		return nil
+	}
+	err := fmt.Errorf("%d rows of table %s were malformed and skipped", it.Malformed(), tableName)
+	if skipped != nil {
+		for _, row := range skipped.first {
+			slog.Warn(fmt.Sprintf("line %d: malformed row of table %s skipped: %s", row.Line, row.Table, row.Reason))
+		}
+		err = fmt.Errorf("%s, see %s", err, skipped.path)
+	}
+	return withExitCode(exitParseError, err)
+}
+
+// stdoutFilename is the -o value writing the output to stdout.
+const stdoutFilename = "-"
+
+// Extensions of the compressed dumps and archives df.open decompresses.
+var compressionExtensions = []string{".gz", ".tgz", ".bz2", ".zip", ".tar"}
+
+// defaultOutputFilename names the output of a table after the dump it was extracted from, without
+// the extensions of its compression, or stdin_<table> in the working directory for dumps read
+// from stdin.
+func defaultOutputFilename(dumpFilename, tableName string, format output.Format) string {
+	if dumpFilename == stdinFilename {
+		dumpFilename = "stdin"
+	}
+	for _, ext := range compressionExtensions {
+		dumpFilename = strings.TrimSuffix(dumpFilename, ext)
+	}
+	return fmt.Sprintf("%s_%s%s", strings.TrimSuffix(dumpFilename, ".sql"), tableName, format.Extension)
+}
+
+// writeError reports a failure of writeToFile. Errors of the pipeline, such as a failing script,
+// are returned as they are, anything else is a failure writing the output.
+func writeError(format output.Format, err error) error {
+	if isRecordsError(err) {
+		return err
+	}
+	return withExitCode(exitIOError, fmt.Errorf("Error writing %s file: %s", format.Name, err))
+}
+
+// isRecordsError reports whether an error writing an output was returned by the records written
+// rather than by the output: a failing stage of the pipeline, the malformed row a strict iterator
+// stopped at or a failure reading the dump, which is reported as it is.
+func isRecordsError(err error) bool {
+	var stageErr *pipeline.StageError
+	var parseErr *extractor.ParseError
+	var readErr *extractor.ReadError
+	return errors.As(err, &stageErr) || errors.As(err, &parseErr) || errors.As(err, &readErr)
+}
+
+// writeToFile streams the records of the iterator into outputFilename using the given format,
+// returning the number of records written. If ctx is cancelled or the records fail, as a strict
+// iterator does at a malformed row, the records written so far are flushed, leaving a
+// well-formed file, and the error of ctx or of the records is returned.
+func writeToFile(ctx context.Context, outputFilename string, tableName string, it output.Records, format output.Format) (int, error) {
+	file := os.Stdout
+	if outputFilename != stdoutFilename {
+		var err error
+		if file, err = os.Create(outputFilename); err != nil {
+			return 0, err
+		}
+		defer file.Close()
+	}
+
+	buffered := bufio.NewWriter(file)
+	count, copyErr := output.CopyContext(ctx, format.New(buffered), tableName, it)
+	if copyErr != nil && !isInterrupted(copyErr) && !isRecordsError(copyErr) {
+		return count, copyErr
+	}
+	slog.Debug("table written", "table", tableName, "rows", count, "output", outputFilename, "format", format.Name)
+	if err := buffered.Flush(); err != nil {
+		return count, err
+	}
+	if outputFilename != stdoutFilename {
+		if err := file.Close(); err != nil {
+			return count, err
+		}
+	}
+	return count, copyErr
+}
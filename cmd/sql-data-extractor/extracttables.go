@@ -0,0 +1,389 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/destination"
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+	"github.com/elvisgraho/sql-data-extractor/pkg/pipeline"
+	"github.com/elvisgraho/sql-data-extractor/pkg/transform"
+	"github.com/elvisgraho/sql-data-extractor/pkg/where"
+)
+
+// tablesPlan describes an extraction of several tables, with extract -all-tables, a list given
+// to -table, or convert.
+type tablesPlan struct {
+	// tableNames lists the tables to extract, nil for all of them.
+	tableNames []string
+	format     output.Format
+	// dir is the directory the file of every table is written to, empty to write them next to
+	// the dump.
+	dir string
+	// combined is the file all tables are written to, empty for one file per table.
+	combined string
+	// dest is the destination the tables are sent to instead, one after the other, and target
+	// names it in messages. Reports and checkpoints are then kept next to the dump.
+	dest       destination.Destination
+	target     string
+	transforms *transform.Set
+	where      *where.Filter
+	hooks      *scriptHooks
+	skipEmpty  bool
+	dedupe     bool
+	dedupeDisk bool
+	offset     int
+	limit      int
+	strict     bool
+	provenance bool
+	// allowEmpty doesn't fail extractions writing no row at all, which convert doesn't.
+	allowEmpty bool
+}
+
+// tableOutput is the file one table of an extraction of several tables is written to, or its
+// part of the destination. It is created once the first row of the table is written, or once the
+// dump is read for tables without rows, and closed once the table's section of the dump ended.
+type tableOutput struct {
+	records  *pipeline.Pipeline
+	filename string
+	file     *os.File
+	buffered *bufio.Writer
+	writer   output.Writer
+	count    int
+	// closed is set once the output is ended, after which it takes no more rows.
+	closed bool
+}
+
+func (o *tableOutput) open(format output.Format, dest destination.Destination) error {
+	if dest != nil {
+		o.writer = dest
+		return o.writer.Begin(o.records.Table(), o.records.Columns())
+	}
+	file, err := os.Create(o.filename)
+	if err != nil {
+		return err
+	}
+	o.file = file
+	o.buffered = bufio.NewWriter(file)
+	o.writer = format.New(o.buffered)
+	return o.writer.Begin(o.records.Table(), o.records.Columns())
+}
+
+// close ends the output, creating it first if no row was written. Closing it again does nothing.
+func (o *tableOutput) close(format output.Format, dest destination.Destination) error {
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+	if o.writer == nil {
+		if err := o.open(format, dest); err != nil {
+			return err
+		}
+	}
+	if o.file == nil {
+		return o.writer.End()
+	}
+	defer o.file.Close()
+	if err := o.writer.End(); err != nil {
+		return err
+	}
+	if err := o.buffered.Flush(); err != nil {
+		return err
+	}
+	return o.file.Close()
+}
+
+// extractTables writes several tables, reading the dump once. Every table gets its own file,
+// or a part of the combined file, which is assembled from a file per table once the dump is
+// read so that it doesn't depend on the order of the rows in the dump. The file of a table is
+// closed as soon as rows of another table follow the end of its section, so that few are open at
+// once. A destination takes one table at a time, a table being ended once rows of another one
+// follow. On interrupt, the outputs
+// are completed with the rows written so far and a checkpoint names the table being written. So
+// are they when the reading fails, as at the malformed row a strict iterator stops at, or when a
+// requested table turns out missing at the end of the dump.
+func extractTables(ctx context.Context, dump *extractor.Dump, df *dumpFlags, plan tablesPlan, summary *runSummary) error {
+	filename := func(tableName string) string {
+		if plan.dir != "" {
+			return filepath.Join(plan.dir, tableName+plan.format.Extension)
+		}
+		return defaultOutputFilename(df.filename, tableName, plan.format)
+	}
+	if plan.combined != "" {
+		dir, err := os.MkdirTemp("", "sql-data-extractor-*")
+		if err != nil {
+			return withExitCode(exitIOError, err)
+		}
+		defer os.RemoveAll(dir)
+		// Numbered, as table names may hold characters file names can't.
+		parts := make(map[string]string)
+		filename = func(tableName string) string {
+			if parts[tableName] == "" {
+				parts[tableName] = filepath.Join(dir, fmt.Sprintf("%d%s", len(parts), plan.format.Extension))
+			}
+			return parts[tableName]
+		}
+	} else if plan.dir != "" {
+		if err := os.MkdirAll(plan.dir, 0755); err != nil {
+			return withExitCode(exitIOError, err)
+		}
+	}
+
+	// Reports of malformed rows sit next to the output of their table, or of the combined file.
+	skipped := make(map[string]*skippedRows)
+	skippedOf := func(tableName string) *skippedRows {
+		if skipped[tableName] == nil {
+			reportOf := filename(tableName)
+			if plan.combined != "" {
+				reportOf = strings.TrimSuffix(plan.combined, plan.format.Extension) + "_" + tableName + plan.format.Extension
+			}
+			skipped[tableName] = newSkippedRows(reportOf)
+		}
+		return skipped[tableName]
+	}
+	opts := extractor.Options{OnStatement: traceStatement, Strict: plan.strict}
+	if !plan.strict {
+		opts.OnMalformed = func(row extractor.MalformedRow) { skippedOf(row.Table).add(row) }
+	}
+	tables, err := dump.IterateTables(plan.tableNames, opts)
+	if err != nil {
+		return err
+	}
+	defer tables.Close()
+
+	// Errors writing to a destination name it, the others the format written.
+	writeErr := func(err error) error {
+		if plan.dest != nil {
+			return withExitCode(exitIOError, fmt.Errorf("Error writing to %s: %s", plan.target, err))
+		}
+		return writeError(plan.format, err)
+	}
+
+	outputs := make(map[string]*tableOutput)
+	defer func() {
+		// Outputs left open by an error.
+		for _, out := range outputs {
+			if out.file != nil {
+				out.file.Close()
+			}
+			out.records.Close()
+		}
+	}()
+	outputOf := func(it *extractor.Iterator) *tableOutput {
+		if outputs[it.Table()] == nil {
+			records := pipeline.New(it)
+			if plan.transforms != nil {
+				warnTransformColumns(plan.transforms.AddTo(records), it.Table())
+			}
+			if plan.where != nil {
+				warnWhereColumns(plan.where.AddTo(records), it.Table())
+			}
+			if plan.hooks != nil {
+				plan.hooks.addTo(records)
+			}
+			if plan.skipEmpty {
+				records.AddSkipEmpty()
+			}
+			if plan.dedupe {
+				records.AddDedupe(plan.dedupeDisk)
+			}
+			if plan.offset > 0 {
+				records.AddOffset(plan.offset)
+			}
+			if plan.limit > 0 {
+				records.SetLimit(plan.limit)
+			}
+			outputs[it.Table()] = &tableOutput{records: records, filename: filename(it.Table())}
+		}
+		return outputs[it.Table()]
+	}
+
+	var last *tableOutput
+	// writing holds the files being written, of which those of ended sections are closed.
+	var writing []*tableOutput
+	var recordsErr error
+	for ctx.Err() == nil {
+		it, record, err := tables.Next()
+		if err == io.EOF {
+			break
+		}
+		var notFound *extractor.TableNotFoundError
+		if isRecordsError(err) || errors.As(err, &notFound) {
+			recordsErr = err
+			break
+		}
+		if err != nil {
+			return err
+		}
+		out := outputOf(it)
+		record, keep, err := out.records.Process(record)
+		if err != nil {
+			return err
+		}
+		if !keep {
+			continue
+		}
+		if plan.dest != nil && last != nil && last != out {
+			if err := last.close(plan.format, plan.dest); err != nil {
+				return writeErr(err)
+			}
+		}
+		if plan.dest == nil && plan.combined == "" && last != out {
+			open := writing[:0]
+			for _, w := range writing {
+				if w == out || tables.Reading(w.records.Table()) {
+					open = append(open, w)
+				} else if err := w.close(plan.format, nil); err != nil {
+					return writeErr(err)
+				}
+			}
+			writing = open
+		}
+		if out.closed && plan.dest != nil {
+			return fmt.Errorf("rows of table %s follow those of table %s, which %s takes one table after the other", it.Table(), last.records.Table(), plan.target)
+		}
+		if out.closed {
+			return fmt.Errorf("table %s is created again after its file was written, which -combined allows", it.Table())
+		}
+		if out.writer == nil {
+			if err := out.open(plan.format, plan.dest); err != nil {
+				return writeErr(err)
+			}
+			writing = append(writing, out)
+		}
+		if err := out.writer.WriteRecord(record); err != nil {
+			return writeErr(err)
+		}
+		out.count++
+		last = out
+	}
+
+	for _, it := range tables.Tables() {
+		if err := outputOf(it).close(plan.format, plan.dest); err != nil {
+			return writeErr(err)
+		}
+	}
+	if plan.combined != "" {
+		if err := writeCombined(plan.combined, tables.Tables(), outputs); err != nil {
+			return withExitCode(exitIOError, fmt.Errorf("Error writing %s file: %s", plan.format.Name, err))
+		}
+	}
+
+	total := 0
+	var malformedErr error
+	for _, it := range tables.Tables() {
+		out := outputs[it.Table()]
+		total += out.count
+		target := out.filename
+		if plan.combined != "" {
+			target = plan.combined
+		} else if plan.dest != nil {
+			target = plan.target
+		}
+		summary.addTable(it.Table(), plan.format.Name, target, out.records, out.count)
+		if plan.combined != "" {
+			// The size of the table's part of the combined file, so that totals add up.
+			if info, err := os.Stat(out.filename); err == nil {
+				summary.Tables[len(summary.Tables)-1].OutputBytes = info.Size()
+			}
+		}
+		if err := skippedOf(it.Table()).close(); err != nil {
+			return err
+		}
+		if ctx.Err() != nil || recordsErr != nil {
+			continue
+		}
+		if plan.combined == "" {
+			removeCheckpoint(out.filename)
+		}
+		if plan.combined == "" && plan.dest == nil {
+			if plan.provenance {
+				if err := writeProvenance(*df, it.Table(), out.records, plan.format.Name, out.filename, out.count); err != nil {
+					return withExitCode(exitIOError, err)
+				}
+			}
+			banner("Data successfully written to %s", out.filename)
+		}
+		checkRowCount(it)
+		if err := checkMalformed(it.Table(), it, skippedOf(it.Table())); err != nil {
+			slog.Warn(err.Error())
+			malformedErr = withExitCode(exitParseError, fmt.Errorf("malformed rows encountered"))
+		}
+	}
+	if recordsErr != nil {
+		return recordsErr
+	}
+	if plan.combined != "" && ctx.Err() == nil {
+		removeCheckpoint(plan.combined)
+		banner("Data of %d tables successfully written to %s", len(tables.Tables()), plan.combined)
+	}
+	if plan.dest != nil && ctx.Err() == nil {
+		banner("%d tables successfully written to %s", len(tables.Tables()), plan.target)
+	}
+
+	if ctx.Err() != nil {
+		c := checkpoint{File: df.filename, Dialect: df.dialect, Format: plan.format.Name}
+		if last != nil {
+			c.Table, c.Output, c.Rows = last.records.Table(), last.filename, last.count
+		}
+		if plan.combined != "" {
+			c.Output = plan.combined
+		}
+		if plan.dest != nil && last != nil {
+			c.Output, c.path = plan.target, checkpointPath(last.filename)
+		}
+		return interrupted(c)
+	}
+	if malformedErr != nil {
+		return malformedErr
+	}
+	if total == 0 && !plan.allowEmpty {
+		return withExitCode(exitNoRows, fmt.Errorf("no rows extracted from %d tables", len(tables.Tables())))
+	}
+	return nil
+}
+
+// writeCombined writes the JSON object holding the array of rows of every table under its name,
+// from the files the tables were written to.
+func writeCombined(filename string, tables []*extractor.Iterator, outputs map[string]*tableOutput) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	buffered := bufio.NewWriter(file)
+	buffered.WriteString("{")
+	for i, it := range tables {
+		if i > 0 {
+			buffered.WriteString(",")
+		}
+		name, _ := json.Marshal(it.Table())
+		buffered.WriteString("\n  " + string(name) + ": ")
+		part, err := os.Open(outputs[it.Table()].filename)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(buffered, part)
+		part.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if len(tables) > 0 {
+		buffered.WriteString("\n")
+	}
+	buffered.WriteString("}")
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const malformedDump = "CREATE TABLE `users` (\n  `id` int,\n  `name` varchar(255)\n);\n" +
+	"INSERT INTO `users` VALUES (1,'a'),(2,'b');\n" +
+	"INSERT INTO `users` VALUES (3,'c'),(4);\n" +
+	"INSERT INTO `users` VALUES (5,'e');\n" +
+	"CREATE TABLE `orders` (\n  `id` int\n);\n" +
+	"INSERT INTO `orders` VALUES (1);\n"
+
+func TestStrict(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		files map[string]string
+	}{
+		{
+			"lenient", []string{"-table", "users", "-o", "users.jsonl"},
+			map[string]string{"users.jsonl": "{\"id\":\"1\",\"name\":\"a\"}\n{\"id\":\"2\",\"name\":\"b\"}\n{\"id\":\"3\",\"name\":\"c\"}\n{\"id\":\"5\",\"name\":\"e\"}\n"},
+		},
+		{
+			// The rows before the malformed one are written.
+			"strict", []string{"-table", "users", "-o", "users.jsonl", "-strict"},
+			map[string]string{"users.jsonl": "{\"id\":\"1\",\"name\":\"a\"}\n{\"id\":\"2\",\"name\":\"b\"}\n{\"id\":\"3\",\"name\":\"c\"}\n"},
+		},
+		{
+			"lenient tables", []string{"-table", "users,orders", "-dir", "out"},
+			map[string]string{
+				"out/users.jsonl":  "{\"id\":\"1\",\"name\":\"a\"}\n{\"id\":\"2\",\"name\":\"b\"}\n{\"id\":\"3\",\"name\":\"c\"}\n{\"id\":\"5\",\"name\":\"e\"}\n",
+				"out/orders.jsonl": "{\"id\":\"1\"}\n",
+			},
+		},
+		{
+			// The tables after the malformed row aren't written.
+			"strict tables", []string{"-table", "users,orders", "-dir", "out", "-strict"},
+			map[string]string{
+				"out/users.jsonl":  "{\"id\":\"1\",\"name\":\"a\"}\n{\"id\":\"2\",\"name\":\"b\"}\n{\"id\":\"3\",\"name\":\"c\"}\n",
+				"out/orders.jsonl": "",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "dump.sql"), []byte(malformedDump), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			args := []string{"-file", filepath.Join(dir, "dump.sql"), "-format", "jsonl"}
+			for i, arg := range test.args {
+				if i > 0 && (test.args[i-1] == "-o" || test.args[i-1] == "-dir") {
+					arg = filepath.Join(dir, arg)
+				}
+				args = append(args, arg)
+			}
+			err := runExtract(args)
+			// Malformed rows fail the extraction either way, once skipped or at the first.
+			if code := exitCode(err); code != exitParseError {
+				t.Errorf("got exit code %d (%v), want %d", code, err, exitParseError)
+			}
+			for name, want := range test.files {
+				got, err := os.ReadFile(filepath.Join(dir, name))
+				if want == "" {
+					if err == nil {
+						t.Errorf("%s was written: %q", name, got)
+					}
+					continue
+				}
+				if err != nil {
+					t.Errorf("reading %s: %v", name, err)
+				} else if string(got) != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
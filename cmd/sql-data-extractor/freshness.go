@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// Layouts dates are parsed with, in the order they are tried.
+var dateLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// Unix timestamps are only recognised in integer columns named like dates, and between 2000 and
+// 2100, so ids and counters aren't taken for them.
+var (
+	epochHints = []string{"time", "date", "at", "created", "updated", "modified", "login", "last", "seen", "expires"}
+	minEpoch   = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	maxEpoch   = time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+)
+
+// dateColumn holds the dates found in a column, in Unix seconds.
+type dateColumn struct {
+	table, column string
+	dates         []int64
+	// invalid counts values that didn't parse, such as MySQL's zero dates.
+	invalid int
+}
+
+// tableDates holds how the dates of each column of a table are parsed, nil for columns not
+// holding dates, and the dates found in them.
+type tableDates struct {
+	parsers []func(string) (int64, bool)
+	columns []*dateColumn
+}
+
+// computeFreshness collects the dates of every date column of the given tables, or of every
+// table if tables is empty: columns declared as date, datetime or timestamp, and integer columns
+// named like dates holding Unix timestamps.
+func computeFreshness(dump *extractor.Dump, tables []string) ([]*dateColumn, error) {
+	dates := make(map[*extractor.Iterator]*tableDates)
+	read, err := scanTables(dump, tables, func(it *extractor.Iterator, record extractor.Record) {
+		d := dates[it]
+		if d == nil {
+			d = &tableDates{parsers: make([]func(string) (int64, bool), len(it.Columns())), columns: make([]*dateColumn, len(it.Columns()))}
+			for i, col := range it.Columns() {
+				if d.parsers[i] = dateParser(col); d.parsers[i] != nil {
+					d.columns[i] = &dateColumn{table: it.Table(), column: col.Name}
+				}
+			}
+			dates[it] = d
+		}
+		for i, field := range record {
+			if d.parsers[i] == nil || field.Null || field.Value == "" {
+				continue
+			}
+			if date, ok := d.parsers[i](field.Value); ok {
+				d.columns[i].dates = append(d.columns[i].dates, date)
+			} else {
+				d.columns[i].invalid++
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []*dateColumn
+	for _, it := range read {
+		d := dates[it]
+		if d == nil {
+			continue
+		}
+		for _, column := range d.columns {
+			if column != nil && len(column.dates) > 0 {
+				sort.Slice(column.dates, func(a, b int) bool { return column.dates[a] < column.dates[b] })
+				columns = append(columns, column)
+			}
+		}
+	}
+	return columns, nil
+}
+
+// dateParser returns how dates of a column are parsed, or nil if it doesn't hold dates.
+func dateParser(col extractor.Column) func(string) (int64, bool) {
+	declared := strings.ToLower(col.Type)
+	switch {
+	case strings.HasPrefix(declared, "date"), strings.HasPrefix(declared, "timestamp"):
+		return parseDate
+	case strings.Contains(declared, "int") && columnHinted(col.Name, epochHints):
+		return parseEpoch
+	}
+	return nil
+}
+
+func parseDate(value string) (int64, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Unix(), true
+		}
+	}
+	return 0, false
+}
+
+// parseEpoch reads Unix timestamps in seconds or milliseconds.
+func parseEpoch(value string) (int64, bool) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	if n >= minEpoch*1000 && n < maxEpoch*1000 {
+		n /= 1000
+	}
+	return n, n >= minEpoch && n < maxEpoch
+}
+
+// percentile returns the earliest date on or before which the given share of the column's dates fall.
+func (c *dateColumn) percentile(share float64) int64 {
+	rank := int(math.Ceil(share*float64(len(c.dates)))) - 1
+	return c.dates[max(rank, 0)]
+}
+
+// printFreshness writes the range and spread of every date column, the latest date of every
+// table, and how the dates of each column are distributed over the years.
+func printFreshness(w io.Writer, columns []*dateColumn) {
+	if len(columns) == 0 {
+		fmt.Fprintln(w, "No date columns found")
+		return
+	}
+	day := func(seconds int64) string { return time.Unix(seconds, 0).UTC().Format("2006-01-02") }
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TABLE\tCOLUMN\tDATES\tINVALID\tOLDEST\t10%\t50%\t90%\tNEWEST")
+	latest := make(map[string]int64)
+	var tables []string
+	for _, c := range columns {
+		fmt.Fprintf(table, "%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n", c.table, c.column, len(c.dates), c.invalid,
+			day(c.dates[0]), day(c.percentile(0.1)), day(c.percentile(0.5)), day(c.percentile(0.9)), day(c.dates[len(c.dates)-1]))
+		if _, seen := latest[c.table]; !seen {
+			tables = append(tables, c.table)
+		}
+		latest[c.table] = max(latest[c.table], c.dates[len(c.dates)-1])
+	}
+	table.Flush()
+
+	// The latest date of a table hints at when the dump was taken.
+	fmt.Fprintln(w, "\nLatest date:")
+	for _, t := range tables {
+		fmt.Fprintf(w, "  %-30s %s\n", t, time.Unix(latest[t], 0).UTC().Format("2006-01-02 15:04:05"))
+	}
+
+	for _, c := range columns {
+		fmt.Fprintf(w, "\nYears of %s.%s (90%% on or before %s):\n", c.table, c.column, day(c.percentile(0.9)))
+		years := make(map[int]int)
+		for _, date := range c.dates {
+			years[time.Unix(date, 0).UTC().Year()]++
+		}
+		sorted := make([]int, 0, len(years))
+		for year := range years {
+			sorted = append(sorted, year)
+		}
+		sort.Ints(sorted)
+		for _, year := range sorted {
+			fmt.Fprintf(w, "  %d: %6.2f%% (%d)\n", year, percentOf(years[year], len(c.dates)), years[year])
+		}
+	}
+}
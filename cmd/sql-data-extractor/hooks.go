@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/pipeline"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// Usage line of the -script flag.
+const scriptFlagUsage = `  -script     Starlark file defining filter(record) and/or on_row(record) hooks called for every row.
+`
+
+// scriptHooks are the functions of a -script file. Each is called with the record as a dict
+// mapping column names to values. filter returns whether to keep the record. on_row may modify
+// the dict in place or return a new one; the keys of the result become the columns of the record.
+type scriptHooks struct {
+	filename string
+	thread   *starlark.Thread
+	filter   starlark.Callable
+	onRow    starlark.Callable
+}
+
+// loadScript runs a script file and collects its hooks.
+func loadScript(filename string) (*scriptHooks, error) {
+	thread := &starlark.Thread{
+		Name: filename,
+		Print: func(thread *starlark.Thread, msg string) {
+			slog.Info(msg, "script", filename)
+		},
+	}
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, filename, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading script: %s", err)
+	}
+
+	hooks := &scriptHooks{filename: filename, thread: thread}
+	for name, hook := range map[string]*starlark.Callable{"filter": &hooks.filter, "on_row": &hooks.onRow} {
+		value, ok := globals[name]
+		if !ok {
+			continue
+		}
+		if *hook, ok = value.(starlark.Callable); !ok {
+			return nil, fmt.Errorf("Error loading script: %s in %s is not a function", name, filename)
+		}
+	}
+	if hooks.filter == nil && hooks.onRow == nil {
+		return nil, fmt.Errorf("Error loading script: %s defines neither filter nor on_row", filename)
+	}
+	return hooks, nil
+}
+
+// addTo appends the hooks to the stages of p: filter first, so on_row only sees kept records.
+func (h *scriptHooks) addTo(p *pipeline.Pipeline) {
+	if h.filter != nil {
+		p.Add("script "+h.filename+": filter", pipeline.Stage{Reason: "script filter", Apply: func(record extractor.Record) (extractor.Record, bool, error) {
+			result, err := starlark.Call(h.thread, h.filter, starlark.Tuple{recordDict(record)}, nil)
+			if err != nil {
+				return nil, false, fmt.Errorf("Error in script filter: %s", err)
+			}
+			return record, bool(result.Truth()), nil
+		}})
+	}
+	if h.onRow != nil {
+		p.Add("script "+h.filename+": on_row", pipeline.Stage{Reason: "script on_row", Apply: func(record extractor.Record) (extractor.Record, bool, error) {
+			dict := recordDict(record)
+			result, err := starlark.Call(h.thread, h.onRow, starlark.Tuple{dict}, nil)
+			if err != nil {
+				return nil, false, fmt.Errorf("Error in script on_row: %s", err)
+			}
+			if result != starlark.None {
+				var ok bool
+				if dict, ok = result.(*starlark.Dict); !ok {
+					return nil, false, fmt.Errorf("Error in script on_row: returned %s, expected a dict or None", result.Type())
+				}
+			}
+			record, err = dictRecord(dict)
+			return record, true, err
+		}})
+	}
+}
+
+func recordDict(record extractor.Record) *starlark.Dict {
+	dict := starlark.NewDict(len(record))
+	for _, field := range record {
+		if field.Null {
+			dict.SetKey(starlark.String(field.Name), starlark.None)
+			continue
+		}
+		dict.SetKey(starlark.String(field.Name), starlark.String(field.Value))
+	}
+	return dict
+}
+
+// dictRecord converts a dict back to a record, in the order of its keys. Values that are not
+// strings are converted as str() would.
+func dictRecord(dict *starlark.Dict) (extractor.Record, error) {
+	record := make(extractor.Record, 0, dict.Len())
+	for _, item := range dict.Items() {
+		name, ok := starlark.AsString(item[0])
+		if !ok {
+			return nil, fmt.Errorf("Error in script on_row: record key %s is not a string", item[0])
+		}
+		if item[1] == starlark.None {
+			record = append(record, extractor.Field{Name: name, Value: "NULL", Null: true})
+			continue
+		}
+		value, ok := starlark.AsString(item[1])
+		if !ok {
+			value = item[1].String()
+		}
+		record = append(record, extractor.Field{Name: name, Value: value})
+	}
+	return record, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// runIndex implements the index command.
+func runIndex(args []string) error {
+	flags := newFlagSet("index", `Index a dump:
+  Parses every table of the dump and writes their locations, columns and row counts to <dump>.idx.
+  Commands such as list use the index instead of parsing the dump while it is up to date.
+
+Usage:
+  sql-data-extractor index -file <path_to_sql_dump> [options]
+
+Options:
+`+dumpFlagsUsage)
+	df := addDumpFlags(flags)
+	if err := parseArgs(flags, args); err != nil {
+		return err
+	}
+	if df.filename == stdinFilename {
+		return withExitCode(exitUsage, fmt.Errorf("the dump read from stdin cannot be indexed, the index is written next to the dump"))
+	}
+
+	dump, err := df.open(flags)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(df.filename)
+	if err != nil {
+		return err
+	}
+
+	idx, err := dump.BuildIndex()
+	if err != nil {
+		return err
+	}
+	idx.Size = info.Size()
+	idx.ModTime = info.ModTime()
+
+	indexFilename := extractor.IndexPath(df.filename)
+	if err := idx.Write(indexFilename); err != nil {
+		return withExitCode(exitIOError, fmt.Errorf("Error writing index file: %s", err))
+	}
+	banner("Indexed %d tables into %s", len(idx.Tables), indexFilename)
+	return nil
+}
+
+// loadFreshIndex returns the index of the dump at filename, or nil if there is none or the dump
+// changed since it was written.
+func loadFreshIndex(filename string) *extractor.Index {
+	if filename == "" {
+		return nil
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil
+	}
+	idx, err := extractor.ReadIndex(extractor.IndexPath(filename))
+	if err != nil || idx.Size != info.Size() || !idx.ModTime.Equal(info.ModTime()) {
+		return nil
+	}
+	return idx
+}
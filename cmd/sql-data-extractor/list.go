@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// runList implements the list command, printing one table name per line.
+func runList(args []string) error {
+	flags := newFlagSet("list", `List the tables of a dump:
+  Prints the name of every table created in the dump, one per line. Uses the dump's index if it is up to date.
+
+Usage:
+  sql-data-extractor list -file <path_to_sql_dump> [options]
+
+Options:
+`+dumpFlagsUsage)
+	df := addDumpFlags(flags)
+	if err := parseArgs(flags, args); err != nil {
+		return err
+	}
+	return listTables(df, flags)
+}
+
+// listTables prints the tables of the dump, for list and extract -list-tables.
+func listTables(df *dumpFlags, flags *flag.FlagSet) error {
+	if idx := loadFreshIndex(df.filename); idx != nil {
+		for _, table := range idx.Tables {
+			fmt.Println(table.Name)
+		}
+		return nil
+	}
+
+	dump, err := df.open(flags)
+	if err != nil {
+		return err
+	}
+	tables, err := dump.Tables()
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		fmt.Println(table)
+	}
+	return nil
+}
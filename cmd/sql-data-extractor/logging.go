@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// Usage lines of the logging flags, appended to the help of every command.
+const loggingFlagsUsage = `  -log-format Format of diagnostics written to stderr: text or json. Defaults to text.
+  -log-level  Minimum level of diagnostics: debug, info, warn or error. Defaults to info.
+  -v          Verbose, shorthand for -log-level debug.
+  -vv         Very verbose, additionally logs every data statement as it is parsed.
+  -quiet      Do not print success messages, only requested output and diagnostics.
+`
+
+// Level of the per-statement diagnostics enabled by -vv.
+const levelTrace = slog.LevelDebug - 4
+
+// quiet is set by -quiet and suppresses banner messages.
+var quiet bool
+
+func addLoggingFlags(flags *flag.FlagSet) {
+	flags.String("log-format", "text", "Format of diagnostics: text or json")
+	flags.String("log-level", "info", "Minimum level of diagnostics")
+	flags.Bool("v", false, "Verbose diagnostics")
+	flags.Bool("vv", false, "Very verbose diagnostics")
+	flags.Bool("quiet", false, "Do not print success messages")
+}
+
+// setupLogging installs the default logger described by the -log-format and -log-level flags.
+func setupLogging(flags *flag.FlagSet) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(flags.Lookup("log-level").Value.String())); err != nil {
+		return fmt.Errorf("invalid -log-level: %s", err)
+	}
+	switch {
+	case flags.Lookup("vv").Value.String() == "true":
+		level = levelTrace
+	case flags.Lookup("v").Value.String() == "true":
+		level = slog.LevelDebug
+	}
+	quiet = flags.Lookup("quiet").Value.String() == "true"
+
+	handlerOptions := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.LevelKey && attr.Value.Any() == levelTrace {
+				attr.Value = slog.StringValue("TRACE")
+			}
+			return attr
+		},
+	}
+	var handler slog.Handler
+	switch format := flags.Lookup("log-format").Value.String(); format {
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, handlerOptions)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, handlerOptions)
+	default:
+		return fmt.Errorf("invalid -log-format %s, expected text or json", format)
+	}
+	slog.SetDefault(slog.New(handler).With("command", flags.Name()))
+	return nil
+}
+
+// banner prints a success message to stderr unless -quiet was given, keeping stdout for the
+// output of commands.
+func banner(format string, args ...interface{}) {
+	if !quiet {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
+
+// traceStatement is used as extractor.Options.OnStatement to log statements at the -vv level.
+func traceStatement(info extractor.StatementInfo) {
+	slog.Log(context.Background(), levelTrace, "parsed statement", "table", info.Table, "offset", info.Offset, "length", info.Length, "rows", info.Rows)
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"golang.org/x/text/transform"
+)
+
+// command is a subcommand of the CLI.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"extract", "Extract the rows of a table into a file", runExtract},
+		{"list", "List the tables of a dump", runList},
+		{"schema", "Print the columns of one or all tables", runSchema},
+		{"stats", "Print row counts and password mask statistics", runStats},
+		{"profile", "Profile the values of every column of a table", runProfile},
+		{"diff-data", "Compare a table between two dumps", runDiffData},
+		{"convert", "Convert every table of a dump into files of one format", runConvert},
+		{"index", "Write an index of the dump's tables next to it", runIndex},
+		{"batch", "Run the extractions listed in a manifest", runBatch},
+		{"watch", "Process the dumps dropped into a directory", runWatch},
+		{"query", "Run a SQL query on the tables of a dump", runQuery},
+		{"repl", "Query the tables of a dump interactively with SQL", runRepl},
+		{"serve", "Serve the extractor over HTTP and gRPC", runServe},
+		{"completion", "Print a shell completion script", runCompletion},
+		{"help", "Show help for a command", runHelp},
+		// Called by the completion scripts, hidden from the usage by its missing summary.
+		{"__complete", "", runComplete},
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `SQL Dump Data Extractor Usage:
+  This application processes SQL dump files to extract data from specified tables and outputs the data as JSON, JSON lines, CSV or in a format suitable for Hashcat.
+
+Usage:
+  sql-data-extractor <command> [options]
+
+Commands:
+`)
+	for _, cmd := range commands {
+		if cmd.summary != "" {
+			fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+		}
+	}
+	fmt.Fprintf(os.Stderr, `
+Run "sql-data-extractor help <command>" for the options of a command.
+Invoking the tool with options but no command, e.g. "sql-data-extractor -file dump.sql -table users", runs extract.
+
+`+exitCodesUsage)
+}
+
+func main() {
+	args := os.Args[1:]
+	if len(args) == 0 {
+		usage()
+		os.Exit(exitUsage)
+	}
+
+	name := args[0]
+	switch {
+	case name == "-h" || name == "-help" || name == "--help":
+		name = "help"
+		args = args[1:]
+	case strings.HasPrefix(name, "-"):
+		// Invocations from before subcommands existed pass the extract flags directly.
+		name = "extract"
+	default:
+		args = args[1:]
+	}
+
+	cmd, ok := lookupCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %s\n\n", name)
+		usage()
+		os.Exit(exitUsage)
+	}
+
+	err := cmd.run(args)
+	stdinDump.remove()
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(exitCode(err))
+	}
+}
+
+func lookupCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func runHelp(args []string) error {
+	if len(args) == 0 {
+		usage()
+		return nil
+	}
+	cmd, ok := lookupCommand(args[0])
+	if !ok || cmd.name == "help" {
+		usage()
+		return nil
+	}
+	return cmd.run([]string{"-h"})
+}
+
+// newFlagSet creates the flag set of a command, printing usageText as its help. Every command
+// accepts -config, see parseArgs, and the logging flags.
+func newFlagSet(name, usageText string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usageText+loggingFlagsUsage+configFlagUsage)
+	}
+	flags.String("config", "", "YAML file providing values for the options")
+	addLoggingFlags(flags)
+	return flags
+}
+
+// dumpFlags are the flags shared by every command reading a dump.
+type dumpFlags struct {
+	filename string
+	dialect  string
+	charset  string
+}
+
+// Usage lines of the dump flags, for inclusion in the help of commands.
+const dumpFlagsUsage = `  -file       The path to the SQL dump file to be processed, or - to read it from stdin. Dumps compressed with
+              gzip or bzip2 and the first .sql file of zip and tar archives are decompressed as they are read.
+              (required)
+  -dialect    SQL dialect of the dump: mysql, postgres, or auto to detect it. Defaults to auto.
+  -input-charset Character set of the dump, converted to UTF-8 as it is read. Defaults to utf-8.
+`
+
+func addDumpFlags(flags *flag.FlagSet) *dumpFlags {
+	df := &dumpFlags{}
+	flags.StringVar(&df.filename, "file", "", "Path to the SQL dump file")
+	flags.StringVar(&df.dialect, "dialect", extractor.AutoDetect, "SQL dialect of the dump")
+	flags.StringVar(&df.charset, "input-charset", "utf-8", "Character set of the dump")
+	return df
+}
+
+// commonFlags are the flags of newFlagSet and addDumpFlags, which every command reading a dump
+// takes whatever else it is asked.
+var commonFlags = []string{"config", "log-format", "log-level", "v", "vv", "quiet", "file", "dialect", "input-charset"}
+
+// flagsOutside returns the flags set on the command line, by the environment or by a config
+// file, other than the allowed ones and commonFlags, for commands whose modes take few options.
+func flagsOutside(flags *flag.FlagSet, allowed ...string) []string {
+	var others []string
+	flags.Visit(func(f *flag.Flag) {
+		if !containsString(allowed, f.Name) && !containsString(commonFlags, f.Name) {
+			others = append(others, "-"+f.Name)
+		}
+	})
+	return others
+}
+
+// flagsLabel lists the flags flagsOutside returned for an error message.
+func flagsLabel(others []string) string {
+	if len(others) == 0 {
+		return ""
+	}
+	return ", got " + strings.Join(others, " ")
+}
+
+// open loads the dump named by -file with the dialect named by -dialect. A detected dialect is
+// recorded in df.dialect.
+func (df *dumpFlags) open(flags *flag.FlagSet) (*extractor.Dump, error) {
+	if df.filename == "" {
+		flags.Usage()
+		return nil, withExitCode(exitUsage, fmt.Errorf("the -file flag is required"))
+	}
+
+	if df.dialect != extractor.AutoDetect {
+		if _, err := extractor.LookupDialect(df.dialect); err != nil {
+			return nil, err
+		}
+	}
+	decoder, err := charsetDecoder(df.charset)
+	if err != nil {
+		return nil, withExitCode(exitUsage, err)
+	}
+	// The dump is read anew on every pass over it, from disk or from the copy of stdin kept so
+	// far, decompressed and its charset converted as it's read.
+	open := func() (io.ReadCloser, error) { return os.Open(df.filename) }
+	if df.filename == stdinFilename {
+		open = stdinDump.open
+	}
+	source := extractor.Decompress(df.filename, open)
+	// Opened once first, so that a missing file or one that isn't in the format its name says is
+	// reported here.
+	r, err := source()
+	if err != nil {
+		return nil, withExitCode(exitIOError, fmt.Errorf("Error reading file: %s", err))
+	}
+	r.Close()
+	raw := extractor.NewSource(source)
+	dump := raw
+	if decoder != nil {
+		dump = extractor.NewSource(func() (io.ReadCloser, error) {
+			r, err := source()
+			if err != nil {
+				return nil, err
+			}
+			return decodedFile{transform.NewReader(r, decoder), r}, nil
+		})
+	}
+	if err := dump.SetDialect(df.dialect); err != nil {
+		return nil, withExitCode(exitIOError, err)
+	}
+	raw.Dialect = dump.Dialect
+	df.dialect = dump.Dialect.Name()
+	slog.Debug("dump opened", "file", df.filename, "dialect", df.dialect)
+	// The end of the dump is checked on the raw file, which can seek to it.
+	if raw.Truncated() {
+		slog.Warn("dump does not end like a complete dump, it may be truncated", "file", df.filename)
+	}
+	return dump, nil
+}
+
+func parseIncludedColumns(includeColumnsStr string) []string {
+	if includeColumnsStr == "" {
+		return nil
+	}
+	return strings.Split(includeColumnsStr, ",")
+}
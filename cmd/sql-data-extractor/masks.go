@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// Number of most frequent masks listed in the report.
+const topMaskCount = 25
+
+// maskStats holds hashcat-style mask statistics computed over a plaintext password column.
+type maskStats struct {
+	total    int
+	lengths  map[int]int
+	charsets map[string]int
+	masks    map[string]int
+}
+
+// computeMaskStats collects every non-empty value of the given column and tallies lengths, charsets and masks.
+func computeMaskStats(dump *extractor.Dump, tableName, column string) (*maskStats, error) {
+	it, err := dump.Iterate(tableName, extractor.Options{Columns: []string{column}, OnStatement: traceStatement})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	if len(it.Columns()) == 0 {
+		return nil, fmt.Errorf("column %s not found in table", column)
+	}
+
+	stats := &maskStats{
+		lengths:  make(map[int]int),
+		charsets: make(map[string]int),
+		masks:    make(map[string]int),
+	}
+	for {
+		record, err := it.Next()
+		if err == io.EOF {
+			return stats, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range record {
+			if field.Value == "" || field.Null {
+				continue
+			}
+			stats.add(field.Value)
+		}
+	}
+}
+
+func (s *maskStats) add(password string) {
+	s.total++
+	s.lengths[len(password)]++
+	s.charsets[passwordCharset(password)]++
+	s.masks[passwordMask(password)]++
+}
+
+// passwordMask converts a password into its hashcat mask, byte by byte: ?l, ?u, ?d, ?s, or ?b for non-ASCII bytes.
+func passwordMask(password string) string {
+	var mask strings.Builder
+	for i := 0; i < len(password); i++ {
+		c := password[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			mask.WriteString("?l")
+		case c >= 'A' && c <= 'Z':
+			mask.WriteString("?u")
+		case c >= '0' && c <= '9':
+			mask.WriteString("?d")
+		case c >= 0x20 && c <= 0x7e:
+			mask.WriteString("?s")
+		default:
+			mask.WriteString("?b")
+		}
+	}
+	return mask.String()
+}
+
+// passwordCharset names the character classes used by a password, following the statsgen naming
+// (loweralpha, mixedalphanum, specialnum, ...). Non-ASCII bytes count as special characters.
+func passwordCharset(password string) string {
+	var lower, upper, digit, special bool
+	for i := 0; i < len(password); i++ {
+		c := password[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= '0' && c <= '9':
+			digit = true
+		default:
+			special = true
+		}
+	}
+
+	name := ""
+	switch {
+	case lower && upper:
+		name = "mixedalpha"
+	case lower:
+		name = "loweralpha"
+	case upper:
+		name = "upperalpha"
+	}
+	if special {
+		name += "special"
+	}
+	if digit {
+		if name == "" {
+			return "numeric"
+		}
+		name += "num"
+	}
+	return name
+}
+
+// print writes the statistics as a human readable report.
+func (s *maskStats) print(w io.Writer, tableName, column string) {
+	fmt.Fprintf(w, "Mask statistics for %s.%s (%d passwords)\n", tableName, column, s.total)
+	if s.total == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\nLength distribution:")
+	lengths := make([]int, 0, len(s.lengths))
+	for length := range s.lengths {
+		lengths = append(lengths, length)
+	}
+	sort.Ints(lengths)
+	for _, length := range lengths {
+		fmt.Fprintf(w, "  %4d: %6.2f%% (%d)\n", length, s.percent(s.lengths[length]), s.lengths[length])
+	}
+
+	fmt.Fprintln(w, "\nCharset distribution:")
+	for _, entry := range sortedCounts(s.charsets) {
+		fmt.Fprintf(w, "  %-22s %6.2f%% (%d)\n", entry.key+":", s.percent(entry.count), entry.count)
+	}
+
+	fmt.Fprintln(w, "\nTop masks:")
+	for i, entry := range sortedCounts(s.masks) {
+		if i == topMaskCount {
+			break
+		}
+		fmt.Fprintf(w, "  %-32s %6.2f%% (%d)\n", entry.key, s.percent(entry.count), entry.count)
+	}
+}
+
+func (s *maskStats) percent(count int) float64 {
+	return float64(count) * 100 / float64(s.total)
+}
+
+type keyCount struct {
+	key   string
+	count int
+}
+
+// sortedCounts orders a tally by descending count, breaking ties alphabetically.
+func sortedCounts(counts map[string]int) []keyCount {
+	entries := make([]keyCount, 0, len(counts))
+	for key, count := range counts {
+		entries = append(entries, keyCount{key, count})
+	}
+	sortKeyCounts(entries)
+	return entries
+}
+
+// sortKeyCounts sorts entries by decreasing count, then by key.
+func sortKeyCounts(entries []keyCount) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].count != entries[j].count {
+			return entries[i].count > entries[j].count
+		}
+		return entries[i].key < entries[j].key
+	})
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// Number of most used passwords listed in the report.
+const topPasswordCount = 25
+
+// passwordPolicy is a common password policy the report measures compliance with.
+type passwordPolicy struct {
+	name      string
+	minLength int
+	// minClasses is the number of lowercase, uppercase, digit and special character classes required.
+	minClasses int
+}
+
+var passwordPolicies = []passwordPolicy{
+	{"at least 8 characters", 8, 0},
+	{"at least 12 characters", 12, 0},
+	{"3 character classes", 0, 3},
+	{"at least 8 characters, 3 character classes", 8, 3},
+	{"at least 12 characters, 4 character classes", 12, 4},
+}
+
+// passwordReport describes the passwords of a column, for a findings report.
+type passwordReport struct {
+	Table    string `json:"table"`
+	Column   string `json:"column"`
+	Accounts int    `json:"accounts"`
+	// Passwords counts the accounts with a known password, the non-empty values of the column, or
+	// with a potfile the cracked ones.
+	Passwords int `json:"passwords"`
+	// Cracked is set when the column holds hashes looked up in a potfile.
+	Cracked      *int            `json:"cracked,omitempty"`
+	Unique       int             `json:"unique"`
+	Reused       int             `json:"reused"`
+	ReuseRate    float64         `json:"reuse_rate"`
+	TopPasswords []passwordCount `json:"top_passwords"`
+	Lengths      []passwordCount `json:"lengths"`
+	Charsets     []passwordCount `json:"charsets"`
+	Policies     []policyResult  `json:"policies"`
+}
+
+type passwordCount struct {
+	Value string  `json:"value"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+type policyResult struct {
+	Policy    string  `json:"policy"`
+	Compliant int     `json:"compliant"`
+	Share     float64 `json:"share"`
+}
+
+// computePasswordReport analyses the passwords of a column. If cracked is not nil, the column holds
+// hashes and the passwords are the ones cracked, looked up by hash.
+func computePasswordReport(dump *extractor.Dump, tableName, column string, cracked map[string]string) (*passwordReport, error) {
+	it, err := dump.Iterate(tableName, extractor.Options{Columns: []string{column}, OnStatement: traceStatement})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	if len(it.Columns()) == 0 {
+		return nil, fmt.Errorf("column %s not found in table", column)
+	}
+
+	report := &passwordReport{Table: tableName, Column: column, TopPasswords: []passwordCount{}, Lengths: []passwordCount{}, Charsets: []passwordCount{}}
+	if cracked != nil {
+		report.Cracked = new(int)
+	}
+	uses := make(map[string]int)
+	for {
+		record, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Accounts++
+		password := record[0].Value
+		if password == "" || record[0].Null {
+			continue
+		}
+		if cracked != nil {
+			plain, ok := cracked[password]
+			if !ok {
+				continue
+			}
+			*report.Cracked++
+			password = plain
+		}
+		uses[password]++
+	}
+
+	lengths := make(map[int]int)
+	charsets := make(map[string]int)
+	compliant := make([]int, len(passwordPolicies))
+	for password, count := range uses {
+		report.Passwords += count
+		if count > 1 {
+			report.Reused += count
+		}
+		lengths[len(password)] += count
+		charsets[passwordCharset(password)] += count
+		for i, policy := range passwordPolicies {
+			if len(password) >= policy.minLength && characterClasses(password) >= policy.minClasses {
+				compliant[i] += count
+			}
+		}
+	}
+	report.Unique = len(uses)
+	report.ReuseRate = report.share(report.Reused)
+
+	for i, entry := range sortedCounts(uses) {
+		if i == topPasswordCount || entry.count < 2 {
+			break
+		}
+		report.TopPasswords = append(report.TopPasswords, report.count(entry))
+	}
+	// Lengths read best in order rather than by frequency.
+	sortedLengths := make([]int, 0, len(lengths))
+	for length := range lengths {
+		sortedLengths = append(sortedLengths, length)
+	}
+	sort.Ints(sortedLengths)
+	for _, length := range sortedLengths {
+		report.Lengths = append(report.Lengths, report.count(keyCount{fmt.Sprint(length), lengths[length]}))
+	}
+	for _, entry := range sortedCounts(charsets) {
+		report.Charsets = append(report.Charsets, report.count(entry))
+	}
+	for i, policy := range passwordPolicies {
+		report.Policies = append(report.Policies, policyResult{Policy: policy.name, Compliant: compliant[i], Share: report.share(compliant[i])})
+	}
+	return report, nil
+}
+
+func (r *passwordReport) share(count int) float64 {
+	if r.Passwords == 0 {
+		return 0
+	}
+	return float64(count) / float64(r.Passwords)
+}
+
+func (r *passwordReport) count(entry keyCount) passwordCount {
+	return passwordCount{Value: entry.key, Count: entry.count, Share: r.share(entry.count)}
+}
+
+// characterClasses counts the lowercase, uppercase, digit and special character classes used by a password.
+func characterClasses(password string) int {
+	var lower, upper, digit, special int
+	for i := 0; i < len(password); i++ {
+		c := password[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			lower = 1
+		case c >= 'A' && c <= 'Z':
+			upper = 1
+		case c >= '0' && c <= '9':
+			digit = 1
+		default:
+			special = 1
+		}
+	}
+	return lower + upper + digit + special
+}
+
+// loadPotfile reads the cracked passwords of a hashcat potfile, by hash. Salted hashes contain
+// colons themselves, so a line is indexed under every prefix ending before a colon, the column
+// values then only match the right one.
+func loadPotfile(filename string) (map[string]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, withExitCode(exitIOError, fmt.Errorf("Error reading potfile: %s", err))
+	}
+	defer file.Close()
+
+	cracked := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		for i := 0; i < len(line); i++ {
+			if line[i] == ':' {
+				cracked[line[:i]] = decodeHashcatHex(line[i+1:])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, withExitCode(exitIOError, fmt.Errorf("Error reading potfile: %s", err))
+	}
+	return cracked, nil
+}
+
+// decodeHashcatHex decodes the $HEX[...] notation hashcat uses for passwords with special bytes.
+func decodeHashcatHex(plain string) string {
+	if !strings.HasPrefix(plain, "$HEX[") || !strings.HasSuffix(plain, "]") {
+		return plain
+	}
+	decoded, err := hex.DecodeString(plain[5 : len(plain)-1])
+	if err != nil {
+		return plain
+	}
+	return string(decoded)
+}
+
+// write renders the report as JSON or HTML.
+func (r *passwordReport) write(w io.Writer, format string) error {
+	if format == "html" {
+		return passwordReportTemplate.Execute(w, r)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+var passwordReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(share float64) string { return fmt.Sprintf("%.2f%%", share*100) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Password analysis of {{.Table}}.{{.Column}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+td.n { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Password analysis of {{.Table}}.{{.Column}}</h1>
+<table>
+<tr><th>Accounts</th><td class="n">{{.Accounts}}</td></tr>
+{{- if .Cracked}}
+<tr><th>Cracked</th><td class="n">{{.Cracked}}</td></tr>
+{{- end}}
+<tr><th>Passwords</th><td class="n">{{.Passwords}}</td></tr>
+<tr><th>Unique passwords</th><td class="n">{{.Unique}}</td></tr>
+<tr><th>Accounts sharing their password</th><td class="n">{{.Reused}} ({{percent .ReuseRate}})</td></tr>
+</table>
+<h2>Policy compliance</h2>
+<table>
+<tr><th>Policy</th><th>Compliant</th><th>Share</th></tr>
+{{- range .Policies}}
+<tr><td>{{.Policy}}</td><td class="n">{{.Compliant}}</td><td class="n">{{percent .Share}}</td></tr>
+{{- end}}
+</table>
+<h2>Most reused passwords</h2>
+<table>
+<tr><th>Password</th><th>Accounts</th><th>Share</th></tr>
+{{- range .TopPasswords}}
+<tr><td>{{.Value}}</td><td class="n">{{.Count}}</td><td class="n">{{percent .Share}}</td></tr>
+{{- end}}
+</table>
+<h2>Lengths</h2>
+<table>
+<tr><th>Length</th><th>Passwords</th><th>Share</th></tr>
+{{- range .Lengths}}
+<tr><td>{{.Value}}</td><td class="n">{{.Count}}</td><td class="n">{{percent .Share}}</td></tr>
+{{- end}}
+</table>
+<h2>Character sets</h2>
+<table>
+<tr><th>Charset</th><th>Passwords</th><th>Share</th></tr>
+{{- range .Charsets}}
+<tr><td>{{.Value}}</td><td class="n">{{.Count}}</td><td class="n">{{percent .Share}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
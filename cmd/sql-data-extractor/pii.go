@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// piiCategory recognises one kind of personal data in a whole value.
+type piiCategory struct {
+	name string
+	// hints are prefixes of the words of a column name that make a match in the column more trustworthy.
+	hints []string
+	match func(value string) bool
+}
+
+var (
+	emailRegex = regexp.MustCompile(`^[A-Za-z0-9._%+'-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}$`)
+	// Phone numbers need a leading + or separators, so plain integer columns aren't taken for them.
+	phoneRegex      = regexp.MustCompile(`^(\+\d[\d ().-]{6,18}\d|\(?\d{2,4}\)?[ .-]\d{2,4}[ .-]\d{2,5}([ .-]\d{2,5})?)$`)
+	ssnRegex        = regexp.MustCompile(`^(\d{3})-(\d{2})-(\d{4})$`)
+	ninoRegex       = regexp.MustCompile(`^(?i:[A-CEGHJ-PR-TW-Z][A-CEGHJ-NPR-TW-Z] ?\d{2} ?\d{2} ?\d{2} ?[A-D])$`)
+	cardNumberRegex = regexp.MustCompile(`^\d{4}([ -]?\d{3,4}){2,3}[ -]?\d{0,4}$`)
+)
+
+var piiCategories = []piiCategory{
+	{"email", []string{"email", "mail"}, emailRegex.MatchString},
+	{"phone", []string{"phone", "tel", "mobile", "fax"}, isPhoneNumber},
+	{"national_id", []string{"ssn", "nino", "national", "social", "tax"}, isNationalID},
+	{"credit_card", []string{"card", "cc", "pan"}, isCardNumber},
+	{"ip_address", []string{"ip", "host", "remote"}, isIPAddress},
+}
+
+func isPhoneNumber(value string) bool {
+	if !phoneRegex.MatchString(value) || isoDateRegex.MatchString(value) || ssnRegex.MatchString(value) {
+		return false
+	}
+	digits := countDigits(value)
+	return digits >= 7 && digits <= 15
+}
+
+var isoDateRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+
+// isNationalID recognises US social security numbers and UK national insurance numbers.
+func isNationalID(value string) bool {
+	if match := ssnRegex.FindStringSubmatch(value); match != nil {
+		// Area 000, 666 and 900-999, group 00 and serial 0000 are never issued.
+		return match[1] != "000" && match[1] != "666" && match[1][0] != '9' && match[2] != "00" && match[3] != "0000"
+	}
+	return ninoRegex.MatchString(value)
+}
+
+// isCardNumber recognises payment card numbers of the major networks that pass the Luhn check.
+func isCardNumber(value string) bool {
+	if !cardNumberRegex.MatchString(value) {
+		return false
+	}
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+	if len(digits) < 13 || len(digits) > 19 || !strings.ContainsAny(digits[:1], "23456") {
+		return false
+	}
+	return luhnValid(digits)
+}
+
+// luhnValid reports whether a string of digits has a valid Luhn check digit.
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func isIPAddress(value string) bool {
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return false
+	}
+	// Short hex strings such as "::" or "1::" parse as IPv6 without looking like an address.
+	return addr.Is4() || strings.Count(value, ":") >= 2 && len(value) >= 7
+}
+
+func countDigits(value string) int {
+	n := 0
+	for _, c := range value {
+		if c >= '0' && c <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+// piiFinding is one category of personal data found in a column.
+type piiFinding struct {
+	table, column, category string
+	matches, values         int
+	confidence              string
+}
+
+func (f piiFinding) share() float64 {
+	return float64(f.matches) / float64(f.values)
+}
+
+// piiCounts counts, per column of a table, its non-empty values and those matching each category.
+type piiCounts struct {
+	values  []int
+	matches [][]int
+}
+
+// detectPII scans every value of the given tables, or of every table if tables is empty, for
+// personal data and returns, per column, the categories found in it.
+func detectPII(dump *extractor.Dump, tables []string) ([]piiFinding, error) {
+	counts := make(map[*extractor.Iterator]*piiCounts)
+	read, err := scanTables(dump, tables, func(it *extractor.Iterator, record extractor.Record) {
+		c := counts[it]
+		if c == nil {
+			c = &piiCounts{values: make([]int, len(record)), matches: make([][]int, len(record))}
+			for i := range c.matches {
+				c.matches[i] = make([]int, len(piiCategories))
+			}
+			counts[it] = c
+		}
+		for i, field := range record {
+			value := strings.TrimSpace(field.Value)
+			if value == "" || field.Null {
+				continue
+			}
+			c.values[i]++
+			for k, category := range piiCategories {
+				if category.match(value) {
+					c.matches[i][k]++
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []piiFinding
+	for _, it := range read {
+		c := counts[it]
+		if c == nil {
+			continue
+		}
+		for i, column := range it.Columns() {
+			for k, category := range piiCategories {
+				if c.matches[i][k] == 0 {
+					continue
+				}
+				finding := piiFinding{
+					table:    it.Table(),
+					column:   column.Name,
+					category: category.name,
+					matches:  c.matches[i][k],
+					values:   c.values[i],
+				}
+				finding.confidence = piiConfidence(finding.share(), columnHinted(column.Name, category.hints))
+				findings = append(findings, finding)
+			}
+		}
+	}
+	return findings, nil
+}
+
+// piiConfidence rates a finding by the share of the column's values that matched, a column named
+// after the category needing fewer matches.
+func piiConfidence(share float64, hinted bool) string {
+	switch {
+	case share >= 0.8 || hinted && share >= 0.3:
+		return "high"
+	case share >= 0.2 || hinted:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// columnHinted reports whether a word of the column name, such as "cc" in "cc_number", starts with
+// one of the hints.
+func columnHinted(column string, hints []string) bool {
+	words := strings.FieldsFunc(strings.ToLower(column), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+	for _, word := range words {
+		for _, hint := range hints {
+			if strings.HasPrefix(word, hint) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// printPIIFindings writes the findings as an aligned table.
+func printPIIFindings(w io.Writer, findings []piiFinding) {
+	if len(findings) == 0 {
+		fmt.Fprintln(w, "No personal data found")
+		return
+	}
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TABLE\tCOLUMN\tCATEGORY\tMATCHES\tSHARE\tCONFIDENCE")
+	for _, f := range findings {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%.1f%%\t%s\n", f.table, f.column, f.category, f.matches, f.share()*100, f.confidence)
+	}
+	table.Flush()
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/bits"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// Value classes recognised by the profile command, most specific first.
+var valueClasses = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"integer", regexp.MustCompile(`^[-+]?\d+$`)},
+	{"decimal", regexp.MustCompile(`^[-+]?(\d+\.\d*|\.\d+)([eE][-+]?\d+)?$`)},
+	{"boolean", regexp.MustCompile(`^(?i:true|false)$`)},
+	{"datetime", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[-+]\d{2}:?\d{2})?$`)},
+	{"date", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)},
+	{"time", regexp.MustCompile(`^\d{2}:\d{2}:\d{2}(\.\d+)?$`)},
+	{"uuid", regexp.MustCompile(`^(?i:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})$`)},
+	{"email", regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)},
+	{"ipv4", regexp.MustCompile(`^(\d{1,3}\.){3}\d{1,3}$`)},
+	{"url", regexp.MustCompile(`^(?i:https?|ftp)://\S+$`)},
+	{"hex", regexp.MustCompile(`^(?i:[0-9a-f]{16,})$`)},
+}
+
+// classifyValue returns the class of a non-empty value, "text" if none matches.
+func classifyValue(value string) string {
+	for _, class := range valueClasses {
+		if class.pattern.MatchString(value) {
+			return class.name
+		}
+	}
+	return "text"
+}
+
+// columnProfile describes the values found in one column.
+type columnProfile struct {
+	Name         string   `json:"name"`
+	DeclaredType string   `json:"declared_type"`
+	InferredType string   `json:"inferred_type"`
+	TypeShare    float64  `json:"type_share"`
+	Rows         int      `json:"rows"`
+	Nulls        int      `json:"nulls"`
+	Empty        int      `json:"empty"`
+	NullRate     float64  `json:"null_rate"`
+	EmptyRate    float64  `json:"empty_rate"`
+	Distinct     int      `json:"distinct"`
+	MinLength    int      `json:"min_length"`
+	MaxLength    int      `json:"max_length"`
+	AvgLength    float64  `json:"avg_length"`
+	Examples     []string `json:"examples"`
+
+	classes     map[string]int
+	distinct    *distinctCounter
+	values      int
+	totalLength int
+}
+
+// Longest example value printed, longer ones are cut.
+const maxExampleLength = 40
+
+// runProfile implements the profile command.
+func runProfile(args []string) error {
+	flags := newFlagSet("profile", `Profile the values of every column of a table:
+  Reads every row of a table and reports, per column, the type its values look like, the share of
+  NULL and empty values, an estimate of the number of distinct values, the minimum, maximum and
+  average length, and a few example values.
+
+Usage:
+  sql-data-extractor profile -file <path_to_sql_dump> -table <table_name> [options]
+
+Options:
+`+dumpFlagsUsage+`  -table      The table to profile. (required)
+  -column     Comma-separated list of columns to profile. If omitted, all columns are profiled.
+  -examples   Number of distinct example values listed per column. Defaults to 3.
+  -json       Print the profile as JSON.
+`)
+	df := addDumpFlags(flags)
+	tableName := flags.String("table", "", "Table to profile")
+	includeColumns := flags.String("column", "", "Comma-separated list of columns to profile")
+	examples := flags.Int("examples", 3, "Number of example values per column")
+	asJSON := flags.Bool("json", false, "Print the profile as JSON")
+	if err := parseArgs(flags, args); err != nil {
+		return err
+	}
+	if df.filename == "" || *tableName == "" {
+		flags.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("both -file and -table flags are required"))
+	}
+
+	dump, err := df.open(flags)
+	if err != nil {
+		return err
+	}
+	profiles, err := profileTable(dump, *tableName, parseIncludedColumns(*includeColumns), *examples)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{"table": *tableName, "columns": profiles})
+	}
+	printProfile(os.Stdout, *tableName, profiles)
+	return nil
+}
+
+// profileTable reads the rows of a table and profiles the selected columns.
+func profileTable(dump *extractor.Dump, tableName string, columns []string, examples int) ([]*columnProfile, error) {
+	it, err := dump.Iterate(tableName, extractor.Options{Columns: columns, OnStatement: traceStatement})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	profiles := make([]*columnProfile, len(it.Columns()))
+	for i, col := range it.Columns() {
+		profiles[i] = &columnProfile{
+			Name:         col.Name,
+			DeclaredType: col.Type,
+			Examples:     []string{},
+			classes:      make(map[string]int),
+			distinct:     newDistinctCounter(),
+		}
+	}
+
+	rows := 0
+	for {
+		record, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows++
+		for i, field := range record {
+			profiles[i].add(field, examples)
+		}
+	}
+	for _, p := range profiles {
+		p.finish(rows)
+	}
+	return profiles, nil
+}
+
+func (p *columnProfile) add(field extractor.Field, examples int) {
+	value := field.Value
+	switch {
+	case field.Null:
+		p.Nulls++
+		return
+	case value == "":
+		p.Empty++
+		return
+	}
+
+	length := utf8.RuneCountInString(value)
+	if p.values == 0 || length < p.MinLength {
+		p.MinLength = length
+	}
+	if length > p.MaxLength {
+		p.MaxLength = length
+	}
+	p.values++
+	p.totalLength += length
+	p.classes[classifyValue(value)]++
+
+	if p.distinct.add(value) && len(p.Examples) < examples {
+		if length > maxExampleLength {
+			value = string([]rune(value)[:maxExampleLength]) + "..."
+		}
+		p.Examples = append(p.Examples, value)
+	}
+}
+
+// finish computes the rates and the inferred type once all rows have been added.
+func (p *columnProfile) finish(rows int) {
+	p.Rows = rows
+	p.Distinct = p.distinct.count()
+	if rows > 0 {
+		p.NullRate = float64(p.Nulls) / float64(rows)
+		p.EmptyRate = float64(p.Empty) / float64(rows)
+	}
+	if p.values == 0 {
+		p.InferredType = "empty"
+		return
+	}
+	p.AvgLength = float64(p.totalLength) / float64(p.values)
+
+	// Widen classes that commonly mix, so a price column with a few whole numbers is decimal.
+	widen := map[string]string{"integer": "decimal", "date": "datetime"}
+	for narrow, wide := range widen {
+		if p.classes[narrow] > 0 && p.classes[wide] > 0 {
+			p.classes[wide] += p.classes[narrow]
+			delete(p.classes, narrow)
+		}
+	}
+	dominant := sortedCounts(p.classes)[0]
+	p.InferredType = dominant.key
+	p.TypeShare = float64(dominant.count) / float64(p.values)
+}
+
+// printProfile writes the profiles as an aligned table.
+func printProfile(w io.Writer, tableName string, profiles []*columnProfile) {
+	rows := 0
+	if len(profiles) > 0 {
+		rows = profiles[0].Rows
+	}
+	fmt.Fprintf(w, "Profile of %s (%d rows)\n\n", tableName, rows)
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "COLUMN\tDECLARED\tINFERRED\tNULL\tEMPTY\tDISTINCT\tLENGTH\tEXAMPLES")
+	for _, p := range profiles {
+		inferred := p.InferredType
+		if p.TypeShare > 0 && p.TypeShare < 1 {
+			inferred = fmt.Sprintf("%s (%.1f%%)", inferred, p.TypeShare*100)
+		}
+		length := "-"
+		if p.MaxLength > 0 {
+			length = fmt.Sprintf("%d-%d avg %.1f", p.MinLength, p.MaxLength, p.AvgLength)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%.1f%%\t%.1f%%\t%s\t%s\t%s\n", p.Name, p.DeclaredType, inferred,
+			p.NullRate*100, p.EmptyRate*100, p.distinctLabel(), length, strings.Join(p.Examples, ", "))
+	}
+	table.Flush()
+}
+
+// distinctLabel marks estimated distinct counts with a tilde.
+func (p *columnProfile) distinctLabel() string {
+	if p.distinct.exact != nil {
+		return fmt.Sprint(p.Distinct)
+	}
+	return fmt.Sprintf("~%d", p.Distinct)
+}
+
+// Number of distinct values counted exactly before switching to an estimate.
+const exactDistinctLimit = 10000
+
+// HyperLogLog precision, 2^14 registers give a standard error of about 0.8%.
+const hllPrecision = 14
+
+// distinctCounter counts distinct values exactly while there are few, then estimates their
+// number with HyperLogLog so memory stays bounded on large tables.
+type distinctCounter struct {
+	exact     map[string]bool
+	registers []uint8
+}
+
+func newDistinctCounter() *distinctCounter {
+	return &distinctCounter{exact: make(map[string]bool)}
+}
+
+// add counts a value, reporting whether it is known to be new. Once estimating, values are never
+// reported as new.
+func (d *distinctCounter) add(value string) bool {
+	if d.exact != nil {
+		if d.exact[value] {
+			return false
+		}
+		d.exact[value] = true
+		if len(d.exact) > exactDistinctLimit {
+			d.registers = make([]uint8, 1<<hllPrecision)
+			for known := range d.exact {
+				d.observe(known)
+			}
+			d.exact = nil
+		}
+		return true
+	}
+	d.observe(value)
+	return false
+}
+
+func (d *distinctCounter) observe(value string) {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	// FNV spreads short, similar values poorly over the high bits, the finalizer of MurmurHash3 mixes them.
+	hash := h.Sum64()
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	hash *= 0xc4ceb9fe1a85ec53
+	hash ^= hash >> 33
+	index := hash >> (64 - hllPrecision)
+	rank := uint8(bits.LeadingZeros64(hash<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	if rank > d.registers[index] {
+		d.registers[index] = rank
+	}
+}
+
+func (d *distinctCounter) count() int {
+	if d.exact != nil {
+		return len(d.exact)
+	}
+	m := float64(len(d.registers))
+	sum, zeros := 0.0, 0
+	for _, r := range d.registers {
+		sum += math.Pow(2, -float64(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// Linear counting is more accurate for small cardinalities.
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int(estimate + 0.5)
+}
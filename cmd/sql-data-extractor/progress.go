@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+	"golang.org/x/term"
+)
+
+// Usage lines of the flags parsing a table on several goroutines and reporting the progress of
+// an extraction.
+const workersFlagsUsage = `  -workers    Number of goroutines parsing the rows of the table while the dump is read, for large dumps
+              whose extraction is bound by the CPU. Rows are still written in the order of the dump. Defaults to 1.
+  -unordered  With -workers, write the rows of every statement as soon as it is parsed, out of the order of the
+              dump, for the highest throughput.
+  -progress   Print the amount of the dump read and the rows written to stderr while extracting.
+`
+
+// Intervals the progress line is refreshed at on a terminal, and printed at otherwise.
+const (
+	progressInterval     = 200 * time.Millisecond
+	progressLineInterval = 10 * time.Second
+)
+
+// progressMeter reports how far an extraction got while it runs. The position in the dump is that of
+// the last data statement read, which the iterator reports, and rows are counted as the output
+// takes them; both are printed from a goroutine of their own.
+type progressMeter struct {
+	read    atomic.Int64
+	written atomic.Int64
+	// size is the size of the dump, 0 if it is read from stdin or compressed and its size
+	// doesn't tell how much of it is left.
+	size     int64
+	terminal bool
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// newProgressMeter returns a meter for extracting the dump of df, printing nothing until started.
+func newProgressMeter(df *dumpFlags) *progressMeter {
+	p := &progressMeter{
+		terminal: term.IsTerminal(int(os.Stderr.Fd())),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	if !containsString(compressionExtensions, strings.ToLower(path.Ext(df.filename))) && df.filename != stdinFilename {
+		if info, err := os.Stat(df.filename); err == nil {
+			p.size = info.Size()
+		}
+	}
+	return p
+}
+
+// start prints the progress until stop is called.
+func (p *progressMeter) start() {
+	interval := progressLineInterval
+	if p.terminal {
+		interval = progressInterval
+	}
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.print()
+			case <-p.done:
+				return
+			}
+		}
+	}()
+}
+
+// statement is used as extractor.Options.OnStatement, tracing the statement as traceStatement
+// does.
+func (p *progressMeter) statement(info extractor.StatementInfo) {
+	traceStatement(info)
+	p.read.Store(int64(info.Offset + info.Length))
+}
+
+// records returns the records counted as they are written.
+func (p *progressMeter) records(records output.Records) output.Records {
+	return progressRecords{Records: records, meter: p}
+}
+
+type progressRecords struct {
+	output.Records
+	meter *progressMeter
+}
+
+func (r progressRecords) Next() (extractor.Record, error) {
+	record, err := r.Records.Next()
+	if err == nil {
+		r.meter.written.Add(1)
+	}
+	return record, err
+}
+
+// stop prints the progress reached and ends the reporting.
+func (p *progressMeter) stop() {
+	close(p.done)
+	<-p.stopped
+	p.print()
+	if p.terminal {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+func (p *progressMeter) print() {
+	read := formatBytes(p.read.Load())
+	if p.size > 0 {
+		read = fmt.Sprintf("%s of %s (%d%%)", read, formatBytes(p.size), min(100, p.read.Load()*100/p.size))
+	}
+	line := fmt.Sprintf("Read %s, %d rows written", read, p.written.Load())
+	if p.terminal {
+		// Cleared to the end of the line, which may be shorter than the previous one.
+		fmt.Fprintf(os.Stderr, "\r%s\x1b[K", line)
+	} else {
+		fmt.Fprintln(os.Stderr, line)
+	}
+}
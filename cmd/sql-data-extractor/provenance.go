@@ -0,0 +1,116 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/pipeline"
+)
+
+// Usage line of the -provenance flag.
+const provenanceFlagUsage = `  -provenance Write <output>.provenance.json next to every output, recording the tool version, SHA-256 of the dump, table, columns, filters and time.
+`
+
+// version is set at build time with -ldflags "-X main.version=v1.2.3". Without it, the version
+// of the module recorded by go install is used.
+var version = ""
+
+func toolVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// provenance describes where an output file came from, so it remains traceable once it has been
+// copied away from the dump.
+type provenance struct {
+	Tool         string    `json:"tool"`
+	Version      string    `json:"version"`
+	Source       string    `json:"source"`
+	SourceSHA256 string    `json:"source_sha256"`
+	Dialect      string    `json:"dialect"`
+	Table        string    `json:"table"`
+	Columns      []string  `json:"columns"`
+	Filters      []string  `json:"filters"`
+	Format       string    `json:"format"`
+	Output       string    `json:"output"`
+	Rows         int       `json:"rows"`
+	Created      time.Time `json:"created"`
+}
+
+// Hashes of the dumps already hashed by this run, by file name.
+var sourceHashes = make(map[string]string)
+
+func provenancePath(outputFilename string) string {
+	return outputFilename + ".provenance.json"
+}
+
+// writeProvenance records the provenance of an output written from records.
+func writeProvenance(df dumpFlags, tableName string, records *pipeline.Pipeline, format, outputFilename string, rows int) error {
+	hash, ok := sourceHashes[df.filename]
+	if !ok {
+		var err error
+		if hash, err = hashFile(df.filename); err != nil {
+			return fmt.Errorf("Error hashing file: %s", err)
+		}
+		sourceHashes[df.filename] = hash
+	}
+
+	record := provenance{
+		Tool:         "sql-data-extractor",
+		Version:      toolVersion(),
+		Source:       df.filename,
+		SourceSHA256: hash,
+		Dialect:      df.dialect,
+		Table:        tableName,
+		Columns:      []string{},
+		Filters:      append([]string{}, records.Filters()...),
+		Format:       format,
+		Output:       outputFilename,
+		Rows:         rows,
+		Created:      time.Now().UTC(),
+	}
+	for _, column := range records.Columns() {
+		record.Columns = append(record.Columns, column.Name)
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(provenancePath(outputFilename), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("Error writing provenance file: %s", err)
+	}
+	return nil
+}
+
+// hashFile hashes the dump at filename as it is stored, compressed or not. The dump read from
+// stdin is hashed once all of it is read.
+func hashFile(filename string) (string, error) {
+	var file io.ReadCloser
+	var err error
+	if filename == stdinFilename {
+		file, err = stdinDump.open()
+	} else {
+		file, err = os.Open(filename)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
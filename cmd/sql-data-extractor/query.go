@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+)
+
+// runQuery implements the query command.
+func runQuery(args []string) error {
+	flags := newFlagSet("query", fmt.Sprintf(`Run a SQL query on a dump:
+  Loads the tables named in the query into an in-memory SQLite database, runs the query and writes
+  its rows in any output format, e.g.
+  sql-data-extractor query -file dump.sql "SELECT u.email, o.total FROM users u JOIN orders o ON o.user_id = u.id WHERE o.total > 100 LIMIT 10"
+
+Usage:
+  sql-data-extractor query -file <path_to_sql_dump> [options] <select_statement>
+
+Options:
+%s  -format     Output format, one of: %s. Defaults to json.
+  -o          Output file. Defaults to stdout.
+`, dumpFlagsUsage, strings.Join(output.Formats(), ", ")))
+	df := addDumpFlags(flags)
+	formatName := flags.String("format", "json", "Output format")
+	outputFilename := flags.String("o", "", "Output file")
+	if err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	query := strings.TrimSpace(strings.Join(flags.Args(), " "))
+	if query == "" {
+		flags.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("a query is required"))
+	}
+	if keyword := strings.ToUpper(strings.Fields(query)[0]); keyword != "SELECT" && keyword != "WITH" {
+		return withExitCode(exitUsage, fmt.Errorf("only SELECT queries are supported"))
+	}
+	format, err := output.Lookup(*formatName)
+	if err != nil {
+		return err
+	}
+
+	dump, err := df.open(flags)
+	if err != nil {
+		return err
+	}
+	dumpTables, err := dump.Tables()
+	if err != nil {
+		return err
+	}
+	tables := queryTables(query, dumpTables)
+	if len(tables) == 0 {
+		return withExitCode(exitTableNotFound, fmt.Errorf("the query names no table of the dump"))
+	}
+	db, err := openEngine(dump, tables)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return withExitCode(exitUsage, fmt.Errorf("Error in query: %s", err))
+	}
+	defer rows.Close()
+	records, err := newSQLRecords(rows)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *outputFilename != "" {
+		file, err := os.Create(*outputFilename)
+		if err != nil {
+			return withExitCode(exitIOError, fmt.Errorf("Error writing %s file: %s", format.Name, err))
+		}
+		defer file.Close()
+		w = file
+	}
+	buffered := bufio.NewWriter(w)
+	count, err := output.Copy(format.New(buffered), "query", records)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err != nil {
+		return withExitCode(exitIOError, fmt.Errorf("Error writing %s file: %s", format.Name, err))
+	}
+	if *outputFilename != "" {
+		if err := w.(*os.File).Close(); err != nil {
+			return withExitCode(exitIOError, fmt.Errorf("Error writing %s file: %s", format.Name, err))
+		}
+		banner("%d rows successfully written to %s", count, *outputFilename)
+	} else {
+		// The formats end without a newline, which would leave the shell prompt on the last line.
+		fmt.Println()
+	}
+	if count == 0 {
+		return withExitCode(exitNoRows, fmt.Errorf("the query returned no rows"))
+	}
+	return nil
+}
+
+// queryTables returns the tables whose name appears as a word of the query, so only those are loaded.
+func queryTables(query string, tables []string) []string {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(query, func(r rune) bool { return r > 127 || !isIdentifierByte(byte(r)) }) {
+		words[strings.ToLower(word)] = true
+	}
+	var named []string
+	for _, table := range tables {
+		if words[strings.ToLower(table)] {
+			named = append(named, table)
+		}
+	}
+	return named
+}
+
+// sqlRecords adapts the rows of a query to output.Records. NULL values are rendered as NULL, as
+// they appear in dumps.
+type sqlRecords struct {
+	rows    *sql.Rows
+	columns []extractor.Column
+}
+
+func newSQLRecords(rows *sql.Rows) (*sqlRecords, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	records := &sqlRecords{rows: rows}
+	for _, t := range types {
+		records.columns = append(records.columns, extractor.Column{Name: t.Name(), Type: t.DatabaseTypeName()})
+	}
+	return records, nil
+}
+
+func (r *sqlRecords) Columns() []extractor.Column {
+	return r.columns
+}
+
+func (r *sqlRecords) Next() (extractor.Record, error) {
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	values := make([]sql.NullString, len(r.columns))
+	pointers := make([]interface{}, len(values))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := r.rows.Scan(pointers...); err != nil {
+		return nil, err
+	}
+	record := make(extractor.Record, len(values))
+	for i, value := range values {
+		record[i] = extractor.Field{Name: r.columns[i].Name, Value: "NULL", Null: true}
+		if value.Valid {
+			record[i].Value, record[i].Null = value.String, false
+		}
+	}
+	return record, nil
+}
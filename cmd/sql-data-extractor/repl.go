@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+)
+
+// SQL keywords offered by tab completion besides table and column names.
+var replKeywords = []string{
+	"SELECT", "DISTINCT", "FROM", "WHERE", "AND", "OR", "NOT", "NULL", "IS", "IN", "LIKE", "BETWEEN",
+	"JOIN", "LEFT", "INNER", "ON", "AS", "GROUP", "BY", "HAVING", "ORDER", "ASC", "DESC", "LIMIT",
+	"OFFSET", "COUNT", "SUM", "AVG", "MIN", "MAX", "LENGTH", "LOWER", "UPPER", "SUBSTR",
+}
+
+const replHelp = `Enter a SQL query on one line to run it. Commands:
+  .tables           List the loaded tables
+  .schema [table]   Print the columns of one or all loaded tables
+  .help             Show this help
+  .quit             Leave, also Ctrl-D
+`
+
+// runRepl implements the repl command.
+func runRepl(args []string) error {
+	flags := newFlagSet("repl", `Query a dump interactively:
+  Loads tables of the dump into an in-memory SQLite database and runs the SQL queries typed at the
+  prompt, e.g. SELECT email FROM users WHERE id < 10. Table names, column names and keywords
+  are completed with tab. When standard input is not a terminal, one query is read per line.
+
+Usage:
+  sql-data-extractor repl -file <path_to_sql_dump> [-table <table_names>] [options]
+
+Options:
+`+dumpFlagsUsage+`  -table      Comma-separated list of tables to load. If omitted, all tables are loaded.
+`)
+	df := addDumpFlags(flags)
+	tableNames := flags.String("table", "", "Comma-separated list of tables to load")
+	if err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	if df.filename == stdinFilename {
+		return withExitCode(exitUsage, fmt.Errorf("-file - cannot be used with repl, which reads its queries from stdin"))
+	}
+	dump, err := df.open(flags)
+	if err != nil {
+		return err
+	}
+	tables := parseIncludedColumns(*tableNames)
+	if tables == nil {
+		if tables, err = dump.Tables(); err != nil {
+			return err
+		}
+	}
+	db, err := openEngine(dump, tables)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	r := &repl{db: db, tables: tables}
+	for _, table := range tables {
+		columns, err := dump.Columns(table)
+		if err != nil {
+			return err
+		}
+		for _, column := range columns {
+			r.columns = append(r.columns, column.Name)
+		}
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return r.runScript(os.Stdin, os.Stdout)
+	}
+	banner("Loaded %d tables. Type .help for help.", len(tables))
+	return r.runTerminal()
+}
+
+type repl struct {
+	db      *sql.DB
+	tables  []string
+	columns []string
+}
+
+// runTerminal reads queries from the terminal with line editing, history and tab completion.
+func (r *repl) runTerminal() error {
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(os.Stdin.Fd()), state)
+
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "sql> ")
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return r.complete(t, line, pos)
+	}
+	for {
+		line, err := t.ReadLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !r.execute(t, line) {
+			return nil
+		}
+	}
+}
+
+// runScript runs the queries read from in, one per line.
+func (r *repl) runScript(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if !r.execute(out, scanner.Text()) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// execute runs one line of input, writing its result to out. It returns false once the user asked to leave.
+func (r *repl) execute(out io.Writer, line string) bool {
+	line = strings.TrimSpace(line)
+	fields := strings.Fields(line)
+	switch {
+	case line == "":
+	case line == ".quit" || line == ".exit":
+		return false
+	case line == ".help":
+		fmt.Fprint(out, replHelp)
+	case line == ".tables":
+		for _, table := range r.tables {
+			fmt.Fprintln(out, table)
+		}
+	case fields[0] == ".schema":
+		query := "SELECT m.name AS \"table\", p.name AS \"column\", p.type AS type FROM sqlite_master m JOIN pragma_table_info(m.name) p WHERE m.type = 'table'"
+		if len(fields) > 1 {
+			query += " AND m.name = " + strings.ReplaceAll(quoteSQLite(fields[1]), `"`, "'")
+		}
+		r.query(out, query+" ORDER BY m.rowid, p.cid")
+	case strings.HasPrefix(line, "."):
+		fmt.Fprintf(out, "Unknown command %s, type .help for help.\n", fields[0])
+	default:
+		r.query(out, line)
+	}
+	return true
+}
+
+// query runs a SQL statement and prints the rows it returns as an aligned table.
+func (r *repl) query(out io.Writer, query string) {
+	rows, err := r.db.Query(query)
+	if err != nil {
+		fmt.Fprintf(out, "Error: %s\n", err)
+		return
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		fmt.Fprintf(out, "Error: %s\n", err)
+		return
+	}
+	if len(columns) == 0 {
+		return
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	values := make([]sql.NullString, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	count := 0
+	cells := make([]string, len(columns))
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			fmt.Fprintf(out, "Error: %s\n", err)
+			return
+		}
+		for i, value := range values {
+			cells[i] = "NULL"
+			if value.Valid {
+				cells[i] = value.String
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		count++
+	}
+	tw.Flush()
+	if err := rows.Err(); err != nil {
+		fmt.Fprintf(out, "Error: %s\n", err)
+		return
+	}
+	fmt.Fprintf(out, "(%d rows)\n", count)
+}
+
+// complete completes the word before the cursor with a table name, column name or keyword. When
+// several candidates remain, they are listed above the prompt.
+func (r *repl) complete(out io.Writer, line string, pos int) (string, int, bool) {
+	start := pos
+	for start > 0 && isIdentifierByte(line[start-1]) {
+		start--
+	}
+	prefix := line[start:pos]
+	if prefix == "" {
+		return "", 0, false
+	}
+
+	seen := make(map[string]bool)
+	var matches []string
+	for _, candidates := range [][]string{r.tables, r.columns, replKeywords} {
+		for _, candidate := range candidates {
+			if !seen[candidate] && strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(prefix)) {
+				seen[candidate] = true
+				matches = append(matches, candidate)
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+	sort.Strings(matches)
+
+	common := matches[0]
+	for _, match := range matches[1:] {
+		for !strings.HasPrefix(strings.ToLower(match), strings.ToLower(common)) {
+			common = common[:len(common)-1]
+		}
+	}
+	if len(common) <= len(prefix) {
+		if len(matches) > 1 {
+			fmt.Fprintln(out, strings.Join(matches, "  "))
+		}
+		// Keep what was typed, the candidates may differ from it in case only.
+		common = prefix
+	}
+	return line[:start] + common + line[pos:], start + len(common), true
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
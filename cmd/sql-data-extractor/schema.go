@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// runSchema implements the schema command.
+func runSchema(args []string) error {
+	flags := newFlagSet("schema", `Print table columns:
+  Prints the columns and their declared types of one table, or of every table in the dump.
+
+Usage:
+  sql-data-extractor schema -file <path_to_sql_dump> [-table <table_name>] [options]
+
+Options:
+`+dumpFlagsUsage+`  -table      The table to describe. If omitted, all tables are described.
+  -rows       Also print the number of rows of every table, counted in its INSERT or COPY statements. Reads
+              the whole dump, unless its index is up to date.
+`)
+	df := addDumpFlags(flags)
+	tableName := flags.String("table", "", "Table to describe")
+	withRows := flags.Bool("rows", false, "Also print the number of rows of every table")
+	if err := parseArgs(flags, args); err != nil {
+		return err
+	}
+	return describeTables(df, flags, *tableName, *withRows)
+}
+
+// describeTables prints the columns of a table, or of every table if tableName is empty, for
+// schema and extract -describe.
+func describeTables(df *dumpFlags, flags *flag.FlagSet, tableName string, withRows bool) error {
+	dump, err := df.open(flags)
+	if err != nil {
+		return err
+	}
+
+	var schemas []*extractor.TableSchema
+	if tableName == "" {
+		if schemas, err = dump.Schemas(); err != nil {
+			return err
+		}
+	} else {
+		schema, err := dump.Schema(tableName)
+		if err != nil {
+			return err
+		}
+		schemas = append(schemas, schema)
+	}
+
+	var rows map[string]int
+	if withRows {
+		idx := loadFreshIndex(df.filename)
+		if idx == nil {
+			if idx, err = dump.BuildIndex(); err != nil {
+				return err
+			}
+		}
+		rows = make(map[string]int)
+		for _, table := range idx.Tables {
+			rows[table.Name] = table.Rows
+		}
+	}
+
+	for i, schema := range schemas {
+		if i > 0 {
+			fmt.Println()
+		}
+		if rows != nil {
+			fmt.Printf("%s (%d rows)\n", schema.Name, rows[schema.Name])
+		} else {
+			fmt.Println(schema.Name)
+		}
+		for _, column := range schema.Columns {
+			fmt.Printf("  %-30s %s\n", column.Name, column.Type)
+		}
+	}
+	return nil
+}
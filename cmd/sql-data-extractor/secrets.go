@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// Well-known credential formats, reported by name rather than by their entropy.
+var secretPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{"private_key", regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{60,})\b`)},
+	{"slack_token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{"stripe_key", regexp.MustCompile(`\b[rs]k_(?:live|test)_[A-Za-z0-9]{20,}\b`)},
+	{"google_api_key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+}
+
+var (
+	// Candidate tokens for the entropy check, values are split around anything else.
+	secretTokenRegex = regexp.MustCompile(`[A-Za-z0-9+/=_.~-]{20,}`)
+	hexTokenRegex    = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+	// Password hashes in crypt format, such as bcrypt's $2y$, are expected in a dump and not secrets.
+	cryptHashRegex = regexp.MustCompile(`^\$[0-9a-z]+\$`)
+)
+
+// Minimum entropy in bits per character of a token to be reported. Hex tokens cannot exceed 4 bits,
+// so they get a lower threshold than base64-like ones.
+const (
+	hexEntropyThreshold    = 3.0
+	base64EntropyThreshold = 4.2
+)
+
+// Number of masked samples listed per finding.
+const secretSampleCount = 3
+
+// secretFinding is one kind of secret found in a column.
+type secretFinding struct {
+	table, column, kind string
+	matches             int
+	samples             []string
+}
+
+// tableSecrets holds the findings of a table by column, then kind, and in the order they were
+// first seen.
+type tableSecrets struct {
+	byColumn []map[string]*secretFinding
+	findings []*secretFinding
+}
+
+// detectSecrets scans every value of the given tables, or of every table if tables is empty, for
+// credentials and high-entropy tokens.
+func detectSecrets(dump *extractor.Dump, tables []string) ([]*secretFinding, error) {
+	secrets := make(map[*extractor.Iterator]*tableSecrets)
+	read, err := scanTables(dump, tables, func(it *extractor.Iterator, record extractor.Record) {
+		s := secrets[it]
+		if s == nil {
+			s = &tableSecrets{byColumn: make([]map[string]*secretFinding, len(record))}
+			secrets[it] = s
+		}
+		columns := it.Columns()
+		for i, field := range record {
+			if field.Null || len(field.Value) < 16 {
+				continue
+			}
+			for _, match := range findSecrets(field.Value, isPasswordColumn(columns[i].Name)) {
+				if s.byColumn[i] == nil {
+					s.byColumn[i] = make(map[string]*secretFinding)
+				}
+				finding := s.byColumn[i][match.kind]
+				if finding == nil {
+					finding = &secretFinding{table: it.Table(), column: columns[i].Name, kind: match.kind}
+					s.byColumn[i][match.kind] = finding
+					s.findings = append(s.findings, finding)
+				}
+				finding.matches++
+				if len(finding.samples) < secretSampleCount {
+					finding.samples = append(finding.samples, maskSecret(match.value))
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []*secretFinding
+	for _, it := range read {
+		if s := secrets[it]; s != nil {
+			findings = append(findings, s.findings...)
+		}
+	}
+	return findings, nil
+}
+
+type secretMatch struct {
+	kind, value string
+}
+
+// findSecrets returns the secrets in value: matches of the known formats, or if there are none,
+// tokens whose entropy is high enough to be random. Hex tokens are skipped in password columns,
+// where they are hashes.
+func findSecrets(value string, passwordColumn bool) []secretMatch {
+	var matches []secretMatch
+	for _, p := range secretPatterns {
+		for _, match := range p.pattern.FindAllString(value, -1) {
+			matches = append(matches, secretMatch{p.kind, match})
+		}
+	}
+	if len(matches) > 0 || cryptHashRegex.MatchString(value) {
+		return matches
+	}
+
+	for _, token := range secretTokenRegex.FindAllString(value, -1) {
+		token = strings.Trim(token, "=.-_~")
+		if len(token) < 20 {
+			continue
+		}
+		threshold := base64EntropyThreshold
+		if hexTokenRegex.MatchString(token) {
+			if passwordColumn {
+				continue
+			}
+			threshold = hexEntropyThreshold
+		} else if !strings.ContainsAny(token, "0123456789") {
+			// Long words, identifiers and paths without digits are rarely generated.
+			continue
+		}
+		if shannonEntropy(token) >= threshold {
+			matches = append(matches, secretMatch{"high_entropy", token})
+		}
+	}
+	return matches
+}
+
+// shannonEntropy returns the entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / float64(len(s))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func isPasswordColumn(column string) bool {
+	return columnHinted(column, []string{"pass", "pwd", "hash", "digest", "salt"})
+}
+
+// maskSecret keeps the start and end of a secret, enough to recognise it, and hides the rest.
+func maskSecret(secret string) string {
+	if strings.HasPrefix(secret, "-----BEGIN") {
+		return secret
+	}
+	hidden := len(secret) - 6
+	if hidden < 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", min(hidden, 12)) + secret[len(secret)-2:]
+}
+
+// printSecretFindings writes the findings as an aligned table.
+func printSecretFindings(w io.Writer, findings []*secretFinding) {
+	if len(findings) == 0 {
+		fmt.Fprintln(w, "No secrets found")
+		return
+	}
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TABLE\tCOLUMN\tKIND\tMATCHES\tSAMPLES")
+	for _, f := range findings {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%s\n", f.table, f.column, f.kind, f.matches, strings.Join(f.samples, ", "))
+	}
+	table.Flush()
+}
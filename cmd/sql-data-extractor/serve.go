@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/rpc"
+	"github.com/elvisgraho/sql-data-extractor/pkg/server"
+)
+
+// Default limit of -max-upload, in bytes.
+const defaultMaxUpload = 1 << 30
+
+// runServe implements the serve command, running the HTTP API (and the gRPC service if requested)
+// until a listener fails.
+func runServe(args []string) error {
+	flags := newFlagSet("serve", `SQL Dump Data Extractor HTTP API:
+  Serves dump uploads, table listings and streamed extractions over HTTP, and optionally gRPC.
+
+Usage:
+  sql-data-extractor serve [options]
+
+Options:
+  -listen      Address to listen on. Defaults to 127.0.0.1:8080, reachable from this machine only.
+  -grpc-listen Address to serve the gRPC Extractor service on. Requires -data-dir. If omitted, gRPC is disabled.
+  -public      Allow -listen and -grpc-listen to be reachable from other machines, e.g. :8080. The API
+               has no authentication: anyone reaching it can upload dumps and read the data directory.
+  -data-dir    Directory dumps may be referenced from by path. If omitted, dumps can only be uploaded.
+  -max-upload  Maximum size of an uploaded dump in bytes. Defaults to 1073741824 (1 GiB), 0 means unlimited.
+`)
+	listen := flags.String("listen", "127.0.0.1:8080", "Address to listen on")
+	grpcListen := flags.String("grpc-listen", "", "Address to serve the gRPC service on")
+	public := flags.Bool("public", false, "Allow listening on addresses reachable from other machines")
+	dataDir := flags.String("data-dir", "", "Directory dumps may be referenced from by path")
+	maxUpload := flags.Int64("max-upload", defaultMaxUpload, "Maximum size of an uploaded dump in bytes")
+	if err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	for _, address := range []struct{ flag, value string }{{"-listen", *listen}, {"-grpc-listen", *grpcListen}} {
+		if address.value != "" && !*public && !isLoopback(address.value) {
+			return withExitCode(exitUsage, fmt.Errorf("%s %s is reachable from other machines and the API has no authentication, pass -public to listen on it anyway", address.flag, address.value))
+		}
+	}
+
+	errs := make(chan error, 2)
+
+	if *grpcListen != "" {
+		if *dataDir == "" {
+			return withExitCode(exitUsage, fmt.Errorf("-grpc-listen requires -data-dir"))
+		}
+		listener, err := net.Listen("tcp", *grpcListen)
+		if err != nil {
+			return err
+		}
+		slog.Info("serving gRPC", "address", *grpcListen, "dataDir", *dataDir)
+		go func() {
+			errs <- rpc.NewServer(*dataDir).Serve(listener)
+		}()
+	}
+
+	handler := server.New(server.Config{DataDir: *dataDir, MaxUploadSize: *maxUpload})
+	defer handler.Close()
+	slog.Info("serving HTTP", "address", *listen)
+	go func() {
+		errs <- http.ListenAndServe(*listen, handler)
+	}()
+
+	return <-errs
+}
+
+// isLoopback reports whether a listen address only accepts connections from this machine. An
+// address without a host listens on every interface.
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
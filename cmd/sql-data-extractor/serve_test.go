@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		address string
+		want    bool
+	}{
+		{"127.0.0.1:8080", true},
+		{"127.1.2.3:8080", true},
+		{"localhost:8080", true},
+		{"[::1]:8080", true},
+		{":8080", false},
+		{"0.0.0.0:8080", false},
+		{"[::]:8080", false},
+		{"192.168.1.10:8080", false},
+		{"example.com:8080", false},
+		{"8080", false},
+	}
+	for _, test := range tests {
+		if got := isLoopback(test.address); got != test.want {
+			t.Errorf("isLoopback(%q) = %v, want %v", test.address, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// Usage lines of the flags choosing what becomes of malformed rows.
+const malformedFlagsUsage = `  -lenient    Skip malformed rows and the parts of data statements that can't be read, listing them in
+              <output>.skipped.jsonl and the first of them on stderr, then exit with code 4. The default.
+  -strict     Stop at the first malformed row, exiting with code 4 and the line it is on.
+`
+
+// Number of skipped rows of a table listed on stderr, the report holding them all.
+const skippedRowsLogged = 5
+
+// skippedRowsPath names the report of the malformed rows skipped while writing outputFilename.
+func skippedRowsPath(outputFilename string) string {
+	return outputFilename + ".skipped.jsonl"
+}
+
+// skippedRows writes the malformed rows of a table to a JSON lines report, one object per row.
+// The file is only created once a row is skipped.
+type skippedRows struct {
+	path string
+	file *os.File
+	buf  *bufio.Writer
+	err  error
+	// first holds the first rows skipped, listed once the table is written.
+	first []extractor.MalformedRow
+}
+
+func newSkippedRows(outputFilename string) *skippedRows {
+	return &skippedRows{path: skippedRowsPath(outputFilename)}
+}
+
+// add is passed as extractor.Options.OnMalformed. A failure writing the report is kept for close.
+func (s *skippedRows) add(row extractor.MalformedRow) {
+	slog.Debug("malformed row skipped", "table", row.Table, "line", row.Line, "offset", row.Offset, "reason", row.Reason)
+	if len(s.first) < skippedRowsLogged {
+		s.first = append(s.first, row)
+	}
+	if s.err != nil {
+		return
+	}
+	if s.file == nil {
+		if s.file, s.err = os.Create(s.path); s.err != nil {
+			return
+		}
+		s.buf = bufio.NewWriter(s.file)
+	}
+	data, _ := json.Marshal(row)
+	s.buf.Write(data)
+	s.err = s.buf.WriteByte('\n')
+}
+
+// close completes the report. If no row was skipped, a report left by an earlier run is removed.
+func (s *skippedRows) close() error {
+	if s.file == nil {
+		if s.err == nil {
+			os.Remove(s.path)
+		}
+		return s.reportError(s.err)
+	}
+	if s.err == nil {
+		s.err = s.buf.Flush()
+	}
+	if err := s.file.Close(); s.err == nil {
+		s.err = err
+	}
+	return s.reportError(s.err)
+}
+
+func (s *skippedRows) reportError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return withExitCode(exitIOError, fmt.Errorf("Error writing skipped rows report %s: %s", s.path, err))
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// runStats implements the stats command.
+func runStats(args []string) error {
+	flags := newFlagSet("stats", `Print table statistics:
+  Prints the row and column count of one table, or of every table in the dump. The reports chosen
+  with -mask-stats, -detect-pii, -detect-secrets, -email-domains, -password-report, -duplicates,
+  -encoding, -freshness or -topn are printed instead, one at a time.
+
+Usage:
+  sql-data-extractor stats -file <path_to_sql_dump> [-table <table_name>] [options]
+
+Options:
+`+dumpFlagsUsage+`  -table      The table to report on. If omitted, all tables are reported.
+  -mask-stats Name of a plaintext password column of -table. Prints its length distribution, charset composition and most common masks.
+  -detect-pii Scan the values of -table, or of every table, for emails, phone numbers, national IDs, credit card numbers and IP addresses, and report the columns holding them.
+  -detect-secrets Scan the values of -table, or of every table, for private keys, API keys, tokens and other high-entropy strings, and report the columns holding them with masked samples.
+  -email-domains Tally the domains of the email addresses in the email columns of -table, or of every table, most frequent first.
+  -password-report Name of a password column of -table. Writes a report of its reuse rate, most used passwords, length and charset distributions and compliance with common policies.
+  -potfile    Hashcat potfile with the cracked passwords of a -password-report column holding hashes.
+  -report-format Format of the -password-report: json or html. Defaults to json.
+  -o          File to write the -password-report to. Defaults to stdout.
+  -duplicates Count the rows of -table occurring more than once, listing the most duplicated.
+  -key        Comma-separated list of columns -duplicates compares, such as email. If omitted, whole rows are compared.
+  -encoding   Scan the values of -table, or of every table, for invalid and double-encoded UTF-8, and suggest the -input-charset fixing them.
+  -freshness  Report the oldest, newest and percentile dates of the date columns of -table, or of every table, and their distribution over the years.
+  -topn       Number of most frequent values of every -column of -table to list, with their counts.
+  -column     Comma-separated list of the columns -topn reports on. If omitted, all columns are reported.
+`)
+	df := addDumpFlags(flags)
+	tableName := flags.String("table", "", "Table to report on")
+	maskColumn := flags.String("mask-stats", "", "Plaintext password column to compute mask statistics for")
+	detectPIIFlag := flags.Bool("detect-pii", false, "Report the columns holding personal data")
+	detectSecretsFlag := flags.Bool("detect-secrets", false, "Report the columns holding secrets")
+	emailDomainsFlag := flags.Bool("email-domains", false, "Tally the domains of email addresses")
+	passwordColumn := flags.String("password-report", "", "Password column to analyse")
+	potfile := flags.String("potfile", "", "Hashcat potfile with cracked passwords")
+	reportFormat := flags.String("report-format", "json", "Format of the password report")
+	outputFilename := flags.String("o", "", "File to write the password report to")
+	duplicatesFlag := flags.Bool("duplicates", false, "Count duplicate rows")
+	keyColumns := flags.String("key", "", "Comma-separated list of columns compared for duplicates")
+	encodingFlag := flags.Bool("encoding", false, "Report values that are not proper UTF-8")
+	freshnessFlag := flags.Bool("freshness", false, "Report how recent the dates of the dump are")
+	topN := flags.Int("topn", 0, "Number of most frequent values to list per column")
+	includeColumns := flags.String("column", "", "Comma-separated list of columns to list the most frequent values of")
+	if err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	if *maskColumn != "" && *tableName == "" {
+		flags.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("-mask-stats requires -table"))
+	}
+	if *passwordColumn != "" && *tableName == "" {
+		flags.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("-password-report requires -table"))
+	}
+	if *topN < 0 {
+		return withExitCode(exitUsage, fmt.Errorf("-topn must be a positive number"))
+	}
+	if *topN > 0 && *tableName == "" {
+		flags.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("-topn requires -table"))
+	}
+	if *duplicatesFlag && *tableName == "" {
+		flags.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("-duplicates requires -table"))
+	}
+	if *reportFormat != "json" && *reportFormat != "html" {
+		return withExitCode(exitUsage, fmt.Errorf("unknown report format %s, expected json or html", *reportFormat))
+	}
+	modes := 0
+	for _, set := range []bool{*maskColumn != "", *detectPIIFlag, *detectSecretsFlag, *emailDomainsFlag, *passwordColumn != "", *duplicatesFlag, *encodingFlag, *freshnessFlag, *topN > 0} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		flags.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("only one of -mask-stats, -detect-pii, -detect-secrets, -email-domains, -password-report, -duplicates, -encoding, -freshness and -topn can be given"))
+	}
+
+	dump, err := df.open(flags)
+	if err != nil {
+		return err
+	}
+
+	if *maskColumn != "" {
+		stats, err := computeMaskStats(dump, *tableName, *maskColumn)
+		if err != nil {
+			return err
+		}
+		stats.print(os.Stdout, *tableName, *maskColumn)
+		return nil
+	}
+	if *topN > 0 {
+		freq, err := computeValueFrequencies(dump, *tableName, parseIncludedColumns(*includeColumns))
+		if err != nil {
+			return err
+		}
+		freq.print(os.Stdout, *tableName, *topN)
+		return nil
+	}
+	if *duplicatesFlag {
+		stats, err := computeDuplicateStats(dump, *tableName, parseIncludedColumns(*keyColumns))
+		if err != nil {
+			return err
+		}
+		stats.print(os.Stdout, *tableName)
+		return nil
+	}
+	if *passwordColumn != "" {
+		return writePasswordReport(dump, *tableName, *passwordColumn, *potfile, *reportFormat, *outputFilename)
+	}
+
+	// Every table of the dump is read if none is named.
+	var tables []string
+	if *tableName != "" {
+		tables = []string{*tableName}
+	}
+	if *detectPIIFlag {
+		findings, err := detectPII(dump, tables)
+		if err != nil {
+			return err
+		}
+		printPIIFindings(os.Stdout, findings)
+		return nil
+	}
+	if *detectSecretsFlag {
+		findings, err := detectSecrets(dump, tables)
+		if err != nil {
+			return err
+		}
+		printSecretFindings(os.Stdout, findings)
+		return nil
+	}
+	if *freshnessFlag {
+		columns, err := computeFreshness(dump, tables)
+		if err != nil {
+			return err
+		}
+		printFreshness(os.Stdout, columns)
+		return nil
+	}
+	if *encodingFlag {
+		report, err := computeEncodingReport(dump, tables)
+		if err != nil {
+			return err
+		}
+		report.print(os.Stdout)
+		return nil
+	}
+	if *emailDomainsFlag {
+		stats, err := computeDomainStats(dump, tables)
+		if err != nil {
+			return err
+		}
+		stats.print(os.Stdout)
+		return nil
+	}
+	read, err := scanTables(dump, tables, func(*extractor.Iterator, extractor.Record) {})
+	if err != nil {
+		return err
+	}
+	for _, it := range read {
+		fmt.Printf("%-30s %10d rows %4d columns\n", it.Table(), it.Rows(), len(it.Columns()))
+	}
+	return nil
+}
+
+// scanTables calls fn with every row of the given tables, or of every table if tableNames is
+// empty, reading the dump once for all of them. It returns the iterators of the tables read, in
+// the order of the dump, to report on them once everything is read.
+func scanTables(dump *extractor.Dump, tableNames []string, fn func(*extractor.Iterator, extractor.Record)) ([]*extractor.Iterator, error) {
+	tables, err := dump.IterateTables(tableNames, extractor.Options{OnStatement: traceStatement})
+	if err != nil {
+		return nil, err
+	}
+	for {
+		it, record, err := tables.Next()
+		if err == io.EOF {
+			return tables.Tables(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		fn(it, record)
+	}
+}
+
+// writePasswordReport analyses a password column and writes the report to outputFilename, or to
+// stdout if it is empty.
+func writePasswordReport(dump *extractor.Dump, tableName, column, potfile, format, outputFilename string) error {
+	var cracked map[string]string
+	if potfile != "" {
+		var err error
+		if cracked, err = loadPotfile(potfile); err != nil {
+			return err
+		}
+	}
+	report, err := computePasswordReport(dump, tableName, column, cracked)
+	if err != nil {
+		return err
+	}
+	if outputFilename == "" {
+		return report.write(os.Stdout, format)
+	}
+
+	file, err := os.Create(outputFilename)
+	if err != nil {
+		return withExitCode(exitIOError, fmt.Errorf("Error writing report: %s", err))
+	}
+	if err := report.write(file, format); err != nil {
+		file.Close()
+		return withExitCode(exitIOError, fmt.Errorf("Error writing report: %s", err))
+	}
+	if err := file.Close(); err != nil {
+		return withExitCode(exitIOError, fmt.Errorf("Error writing report: %s", err))
+	}
+	banner("Report successfully written to %s", outputFilename)
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// stdinFilename is the -file value reading the dump from stdin.
+const stdinFilename = "-"
+
+// stdinDump is the dump read from stdin. Stdin can only be read once, so every command reading a
+// dump from it shares it.
+var stdinDump = &stdinSpool{in: os.Stdin}
+
+// stdinSpool keeps a copy of what is read from stdin in a temporary file, so that the dump can be
+// read on every pass over it like a file: a pass reads the part of the dump read so far from the
+// file, and stdin again past it. The dump is kept as it arrives, so compressed dumps take their
+// compressed size on disk.
+type stdinSpool struct {
+	mu   sync.Mutex
+	in   io.Reader
+	file *os.File
+	size int64
+	buf  []byte
+	// err is the error reading stdin ended with, io.EOF at its end.
+	err error
+}
+
+func (s *stdinSpool) open() (io.ReadCloser, error) {
+	return &spoolReader{spool: s}, nil
+}
+
+// fill copies the next chunk of stdin to the file.
+func (s *stdinSpool) fill() {
+	if s.file == nil {
+		if s.file, s.err = os.CreateTemp("", "sql-data-extractor-stdin-*"); s.err != nil {
+			return
+		}
+		s.buf = make([]byte, 1<<20)
+	}
+	n, err := s.in.Read(s.buf)
+	if n > 0 {
+		if _, err := s.file.WriteAt(s.buf[:n], s.size); err != nil {
+			s.err = err
+			return
+		}
+		s.size += int64(n)
+	}
+	if err != nil {
+		s.err = err
+	}
+}
+
+// readAt reads from offset off of the dump, reading stdin up to it.
+func (s *stdinSpool) readAt(p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for off >= s.size && s.err == nil {
+		s.fill()
+	}
+	if off < s.size {
+		return s.file.ReadAt(p[:min(int64(len(p)), s.size-off)], off)
+	}
+	return 0, s.err
+}
+
+// drain reads the rest of stdin.
+func (s *stdinSpool) drain() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.err == nil {
+		s.fill()
+	}
+	if s.err != io.EOF {
+		return s.err
+	}
+	return nil
+}
+
+// remove deletes the file, once the command is done with the dump.
+func (s *stdinSpool) remove() {
+	if s.file != nil {
+		s.file.Close()
+		os.Remove(s.file.Name())
+	}
+}
+
+// spoolReader is a pass over the dump read from stdin.
+type spoolReader struct {
+	spool  *stdinSpool
+	offset int64
+}
+
+func (r *spoolReader) Read(p []byte) (int, error) {
+	n, err := r.spool.readAt(p, r.offset)
+	r.offset += int64(n)
+	return n, err
+}
+
+// ReadAt and Stat let zip archives be read from stdin, once all of it is read.
+func (r *spoolReader) ReadAt(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		m, err := r.spool.readAt(p[n:], off+int64(n))
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (r *spoolReader) Stat() (fs.FileInfo, error) {
+	if err := r.spool.drain(); err != nil {
+		return nil, err
+	}
+	return r.spool.file.Stat()
+}
+
+func (r *spoolReader) Close() error {
+	return nil
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// subject identifies the person a subject access report is about, as a column and its value.
+type subject struct {
+	Column string `json:"column"`
+	Value  string `json:"value"`
+}
+
+func parseSubject(s string) (subject, error) {
+	column, value, ok := strings.Cut(s, "=")
+	if !ok || column == "" || value == "" {
+		return subject{}, fmt.Errorf("invalid -subject %q, expected column=value", s)
+	}
+	return subject{Column: column, Value: value}, nil
+}
+
+// extractSubject implements extract -subject, writing the rows referencing the subject to a
+// report at outputFilename.
+func extractSubject(df *dumpFlags, flags *flag.FlagSet, subjectFlag, outputFilename string) error {
+	s, err := parseSubject(subjectFlag)
+	if err != nil {
+		return withExitCode(exitUsage, err)
+	}
+	dump, err := df.open(flags)
+	if err != nil {
+		return err
+	}
+	if outputFilename == "" {
+		outputFilename = strings.TrimSuffix(df.filename, ".sql") + "_subject.json"
+	}
+
+	report, err := findSubject(dump, df.filename, s)
+	if err != nil {
+		return err
+	}
+	if err := writeSubjectReport(report, outputFilename); err != nil {
+		return err
+	}
+	banner("%d rows from %d tables referencing %s=%s written to %s", report.Rows, len(report.Tables), s.Column, s.Value, outputFilename)
+	if report.Rows == 0 {
+		return withExitCode(exitNoRows, fmt.Errorf("no rows reference %s=%s", s.Column, s.Value))
+	}
+	return nil
+}
+
+// subjectReport lists every row of a dump referencing a subject, by table.
+type subjectReport struct {
+	Subject     subject         `json:"subject"`
+	Dump        string          `json:"dump"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Rows        int             `json:"rows"`
+	Tables      []*subjectTable `json:"tables"`
+}
+
+type subjectTable struct {
+	Table string `json:"table"`
+	// MatchedBy lists how the rows reference the subject: the column holding it, or the foreign
+	// keys leading to rows that do, such as "user_id -> users.id".
+	MatchedBy []string            `json:"matched_by"`
+	Rows      []map[string]string `json:"rows"`
+
+	// found holds the rows found so far by their number in the table, counting from 0.
+	found map[int]map[string]string
+}
+
+// subjectLink is a foreign key of a table, through which its rows reference the subject when
+// the referenced rows do.
+type subjectLink struct {
+	key   extractor.ForeignKey
+	label string
+}
+
+// findSubject searches every table of the dump for rows whose subject column holds the subject's
+// value, compared case-insensitively, then for rows referencing those rows through foreign keys,
+// and the rows referencing these in turn. Every pass reads all the tables and keeps only the
+// matching rows, so the dump is read once more for every level of foreign keys followed.
+func findSubject(dump *extractor.Dump, dumpFilename string, s subject) (*subjectReport, error) {
+	tables, err := dump.Tables()
+	if err != nil {
+		return nil, err
+	}
+	links := make(map[string][]subjectLink)
+	found := make(map[string]*subjectTable)
+	hasColumn := false
+	for _, tableName := range tables {
+		columns, err := dump.Columns(tableName)
+		if err != nil {
+			return nil, err
+		}
+		if extractor.ColumnIndex(columns, s.Column) >= 0 {
+			hasColumn = true
+		}
+		keys, err := dump.ForeignKeys(tableName)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			label := fmt.Sprintf("%s -> %s.%s", strings.Join(key.Columns, ", "), key.Table, strings.Join(key.References, ", "))
+			links[tableName] = append(links[tableName], subjectLink{key: key, label: label})
+		}
+		found[tableName] = &subjectTable{Table: tableName, MatchedBy: []string{}, found: make(map[int]map[string]string)}
+	}
+	if !hasColumn {
+		return nil, fmt.Errorf("no table has a column %s", s.Column)
+	}
+
+	// referenced holds, by table and columns, the values of the key columns of the rows found,
+	// which rows of other tables reference them by.
+	referenced := make(map[string]map[string]bool)
+	referencedKey := func(table string, columns []string) string {
+		return table + "\x00" + strings.Join(columns, "\x00")
+	}
+	for _, tableLinks := range links {
+		for _, link := range tableLinks {
+			referenced[referencedKey(link.key.Table, link.key.References)] = make(map[string]bool)
+		}
+	}
+
+	for {
+		added := 0
+		for _, tableName := range tables {
+			n, err := findSubjectRows(dump, found[tableName], s, links[tableName], referenced, referencedKey)
+			if err != nil {
+				return nil, err
+			}
+			added += n
+		}
+		if added == 0 {
+			break
+		}
+	}
+
+	report := &subjectReport{Subject: s, Dump: dumpFilename, GeneratedAt: time.Now().UTC(), Tables: []*subjectTable{}}
+	for _, tableName := range tables {
+		if t := found[tableName]; len(t.found) > 0 {
+			// Rows found in later passes are listed in the order of the table too.
+			numbers := make([]int, 0, len(t.found))
+			for row := range t.found {
+				numbers = append(numbers, row)
+			}
+			sort.Ints(numbers)
+			for _, row := range numbers {
+				t.Rows = append(t.Rows, t.found[row])
+			}
+			report.Tables = append(report.Tables, t)
+			report.Rows += len(t.Rows)
+		}
+	}
+	return report, nil
+}
+
+// findSubjectRows reads a table and adds the rows referencing the subject that weren't found yet,
+// recording the values other tables may reference them by. It returns the number of rows added.
+func findSubjectRows(dump *extractor.Dump, t *subjectTable, s subject, links []subjectLink, referenced map[string]map[string]bool, referencedKey func(string, []string) string) (int, error) {
+	it, err := dump.Iterate(t.Table, extractor.Options{OnStatement: traceStatement})
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+	columns := it.Columns()
+	subjectColumn := extractor.ColumnIndex(columns, s.Column)
+	linkColumns := make([][]int, len(links))
+	for i, link := range links {
+		if linkColumns[i], err = keyIndexes(columns, link.key.Columns); err != nil {
+			return 0, fmt.Errorf("foreign key of table %s: %s", t.Table, err)
+		}
+	}
+	// The values of this table's columns that other tables reference it by.
+	var referencedBy [][]string
+	for key := range referenced {
+		if table, cols, _ := strings.Cut(key, "\x00"); table == t.Table {
+			referencedBy = append(referencedBy, strings.Split(cols, "\x00"))
+		}
+	}
+
+	added := 0
+	for row := 0; ; row++ {
+		record, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if t.found[row] != nil {
+			continue
+		}
+
+		matchedBy := ""
+		if subjectColumn >= 0 && strings.EqualFold(record[subjectColumn].Value, s.Value) {
+			matchedBy = s.Column
+		}
+		for i, link := range links {
+			if matchedBy != "" {
+				break
+			}
+			if referenced[referencedKey(link.key.Table, link.key.References)][keyValues(record, linkColumns[i])] {
+				matchedBy = link.label
+			}
+		}
+		if matchedBy == "" {
+			continue
+		}
+
+		t.found[row] = recordMap(record)
+		if !containsString(t.MatchedBy, matchedBy) {
+			t.MatchedBy = append(t.MatchedBy, matchedBy)
+		}
+		for _, cols := range referencedBy {
+			indexes, err := keyIndexes(columns, cols)
+			if err != nil {
+				continue
+			}
+			referenced[referencedKey(t.Table, cols)][keyValues(record, indexes)] = true
+		}
+		added++
+	}
+	return added, nil
+}
+
+// keyValues joins the values of the given columns of a record.
+func keyValues(record extractor.Record, indexes []int) string {
+	values := make([]string, len(indexes))
+	for i, index := range indexes {
+		values[i] = record[index].Value
+	}
+	return strings.Join(values, "\x00")
+}
+
+func recordMap(record extractor.Record) map[string]string {
+	values := make(map[string]string, len(record))
+	for _, field := range record {
+		values[field.Name] = field.Value
+	}
+	return values
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// writeSubjectReport writes the report as indented JSON to filename.
+func writeSubjectReport(report *subjectReport, filename string) error {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetIndent("", "  ")
+	// Keeps the arrows of matched_by readable.
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, data.Bytes(), 0644); err != nil {
+		return withExitCode(exitIOError, fmt.Errorf("Error writing subject report: %s", err))
+	}
+	return nil
+}
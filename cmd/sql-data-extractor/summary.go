@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/pipeline"
+)
+
+// Usage line of the -summary flag.
+const summaryFlagUsage = `  -summary    Write a JSON summary of the run to this file, or to stderr with -summary -.
+`
+
+// runSummary is the machine-readable account of a run written with -summary.
+type runSummary struct {
+	Command         string         `json:"command"`
+	Input           string         `json:"input"`
+	Dialect         string         `json:"dialect"`
+	Started         time.Time      `json:"started"`
+	DurationSeconds float64        `json:"duration_seconds"`
+	Tables          []tableSummary `json:"tables"`
+	ExitCode        int            `json:"exit_code"`
+	Error           string         `json:"error,omitempty"`
+}
+
+// tableSummary accounts for the rows of one table. Rows read from the dump are either written
+// or skipped, the reasons of skipped rows being counted in RowsSkipped. Malformed rows are
+// skipped before that and only counted in RowsMalformed.
+type tableSummary struct {
+	// Input is only set by batch, whose jobs read different dumps.
+	Input         string         `json:"input,omitempty"`
+	Table         string         `json:"table"`
+	Format        string         `json:"format"`
+	Output        string         `json:"output"`
+	RowsParsed    int            `json:"rows_parsed"`
+	RowsWritten   int            `json:"rows_written"`
+	RowsSkipped   map[string]int `json:"rows_skipped"`
+	RowsMalformed int            `json:"rows_malformed"`
+	// ValuesUntransformed counts the values a -transform didn't apply to, left as they were.
+	ValuesUntransformed map[string]int `json:"values_untransformed,omitempty"`
+	// OutputBytes is the size of the output file, left out for destinations.
+	OutputBytes int64 `json:"output_bytes,omitempty"`
+}
+
+func newRunSummary(command string, df *dumpFlags) *runSummary {
+	return &runSummary{
+		Command: command,
+		Input:   df.filename,
+		Dialect: df.dialect,
+		Started: time.Now().UTC(),
+		Tables:  []tableSummary{},
+	}
+}
+
+// addTable records the outcome of writing records to outputFilename, which is either a file or
+// the name of a destination.
+func (s *runSummary) addTable(tableName, format, outputFilename string, records *pipeline.Pipeline, written int) {
+	skipped := make(map[string]int)
+	for reason, count := range records.Skipped() {
+		skipped[reason] = count
+	}
+	var untransformed map[string]int
+	for transform, count := range records.Untransformed() {
+		if untransformed == nil {
+			untransformed = make(map[string]int)
+		}
+		untransformed[transform] = count
+	}
+	table := tableSummary{
+		Table:         tableName,
+		Format:        format,
+		Output:        outputFilename,
+		RowsParsed:    records.Rows() + records.Malformed(),
+		RowsWritten:   written,
+		RowsSkipped:   skipped,
+		RowsMalformed: records.Malformed(),
+
+		ValuesUntransformed: untransformed,
+	}
+	if info, err := os.Stat(outputFilename); err == nil && info.Mode().IsRegular() {
+		table.OutputBytes = info.Size()
+	}
+	s.Tables = append(s.Tables, table)
+}
+
+// print writes the summary as a table of the rows of every table, followed by totals if there
+// are several.
+func (s *runSummary) print(w io.Writer) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TABLE\tPARSED\tWRITTEN\tSKIPPED\tMALFORMED\tSIZE\tOUTPUT")
+	var total tableSummary
+	for _, t := range s.Tables {
+		fmt.Fprintf(table, "%s\t%d\t%d\t%s\t%d\t%s\t%s\n", t.Table, t.RowsParsed, t.RowsWritten,
+			skippedLabel(t.RowsSkipped), t.RowsMalformed, sizeLabel(t.OutputBytes), t.Output)
+		total.RowsParsed += t.RowsParsed
+		total.RowsWritten += t.RowsWritten
+		total.RowsMalformed += t.RowsMalformed
+		total.OutputBytes += t.OutputBytes
+		for reason, count := range t.RowsSkipped {
+			if total.RowsSkipped == nil {
+				total.RowsSkipped = make(map[string]int)
+			}
+			total.RowsSkipped[reason] += count
+		}
+	}
+	if len(s.Tables) > 1 {
+		fmt.Fprintf(table, "total\t%d\t%d\t%s\t%d\t%s\n", total.RowsParsed, total.RowsWritten,
+			skippedLabel(total.RowsSkipped), total.RowsMalformed, sizeLabel(total.OutputBytes))
+	}
+	table.Flush()
+}
+
+// skippedLabel sums the skipped rows, naming the reasons if there are any, e.g. "3 (script filter 3)".
+func skippedLabel(skipped map[string]int) string {
+	total := 0
+	var reasons []string
+	for _, entry := range sortedCounts(skipped) {
+		total += entry.count
+		reasons = append(reasons, fmt.Sprintf("%s %d", entry.key, entry.count))
+	}
+	if total == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%d (%s)", total, strings.Join(reasons, ", "))
+}
+
+func sizeLabel(bytes int64) string {
+	if bytes == 0 {
+		return "-"
+	}
+	return formatBytes(bytes)
+}
+
+// finish completes the summary with the result of the run, prints it unless -quiet was given,
+// and writes it to path, "-" meaning stderr. Nothing is written when path is empty. It returns
+// runErr, or the error writing the summary if the run itself succeeded.
+func (s *runSummary) finish(path string, runErr error) error {
+	if !quiet && len(s.Tables) > 0 {
+		s.print(os.Stderr)
+	}
+	if path == "" {
+		return runErr
+	}
+	s.DurationSeconds = time.Since(s.Started).Seconds()
+	s.ExitCode = exitCode(runErr)
+	if runErr != nil {
+		s.Error = runErr.Error()
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stderr.Write(data)
+	} else {
+		err = os.WriteFile(path, data, 0644)
+	}
+	if err != nil && runErr == nil {
+		return withExitCode(exitIOError, fmt.Errorf("Error writing summary file: %s", err))
+	}
+	return runErr
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// valueFrequencies tallies the values of columns of a table.
+type valueFrequencies struct {
+	columns []string
+	// order lists the indexes of columns in the order they are printed.
+	order  []int
+	rows   int
+	counts []map[string]int
+}
+
+// computeValueFrequencies counts how often every value of the given columns, or of every column
+// if none are given, occurs in a table.
+func computeValueFrequencies(dump *extractor.Dump, tableName string, columns []string) (*valueFrequencies, error) {
+	it, err := dump.Iterate(tableName, extractor.Options{Columns: columns, OnStatement: traceStatement})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	if len(it.Columns()) == 0 || len(columns) > 0 && len(it.Columns()) != len(columns) {
+		return nil, fmt.Errorf("columns %s not all found in table", strings.Join(columns, ", "))
+	}
+
+	freq := &valueFrequencies{}
+	for _, col := range it.Columns() {
+		freq.columns = append(freq.columns, col.Name)
+		freq.counts = append(freq.counts, make(map[string]int))
+	}
+	// Reported in the order asked for, rather than the table's.
+	order := make([]int, len(freq.columns))
+	for i := range order {
+		order[i] = i
+	}
+	if len(columns) > 0 {
+		for i, name := range columns {
+			for j, column := range freq.columns {
+				if column == name {
+					order[i] = j
+				}
+			}
+		}
+	}
+	freq.order = order
+	for {
+		record, err := it.Next()
+		if err == io.EOF {
+			return freq, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		freq.rows++
+		for i, field := range record {
+			freq.counts[i][field.Value]++
+		}
+	}
+}
+
+// print writes the n most frequent values of every column.
+func (f *valueFrequencies) print(w io.Writer, tableName string, n int) {
+	for i, c := range f.order {
+		column := f.columns[c]
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Top %d values of %s.%s (%d rows, %d distinct)\n", n, tableName, column, f.rows, len(f.counts[c]))
+		for i, entry := range sortedCounts(f.counts[c]) {
+			if i == n {
+				break
+			}
+			value := entry.key
+			switch {
+			case value == "":
+				value = `""`
+			case len([]rune(value)) > maxExampleLength:
+				value = string([]rune(value)[:maxExampleLength]) + "..."
+			}
+			fmt.Fprintf(w, "  %-42s %6.2f%% (%d)\n", value, percentOf(entry.count, f.rows), entry.count)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Usage line of the -transform flag.
+const transformFlagUsage = `  -transform  Transform the values of columns as they are extracted, e.g. "pass=hexdecode,email=lower". Transforms
+              are hexdecode, base64decode, unescape, lower and trim, applied in the order given.
+`
+
+// warnTransformColumns warns that the columns a table lacks are left out of a transform naming
+// them, when it applies to several tables.
+func warnTransformColumns(missing []string, tableName string) {
+	if len(missing) > 0 {
+		slog.Warn(fmt.Sprintf("table %s has no column %s named in -transform", tableName, strings.Join(missing, ", ")))
+	}
+}
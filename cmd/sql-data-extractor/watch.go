@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/metrics"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+	"gopkg.in/yaml.v3"
+)
+
+// watchRule selects what to extract from the dumps arriving in the intake directory.
+type watchRule struct {
+	Name string `yaml:"name"`
+	// Match is a file name pattern, as in filepath.Match, selecting the dumps the rule applies to.
+	Match string `yaml:"match"`
+	// Table is a pattern selecting the tables to extract.
+	Table   string      `yaml:"table"`
+	Column  interface{} `yaml:"column"`
+	Format  string      `yaml:"format"`
+	Dialect string      `yaml:"dialect"`
+
+	columns []string
+	format  output.Format
+}
+
+// watcher moves the dumps of an intake directory through the rules.
+type watcher struct {
+	flags     *flag.FlagSet
+	dir       string
+	outDir    string
+	doneDir   string
+	failedDir string
+	rules     []watchRule
+	// provenance is set by -provenance.
+	provenance bool
+	// Size and modification time of the files seen by the previous scan, a file is only processed
+	// once they stopped changing, so dumps still being copied are left alone.
+	seen map[string]os.FileInfo
+}
+
+// runWatch implements the watch command.
+func runWatch(args []string) error {
+	flags := newFlagSet("watch", `Process dumps dropped into a directory:
+  Watches an intake directory and processes every dump appearing in it with the rules of a YAML
+  file, then moves it to the done directory, or to the failed directory along with a .error file
+  explaining why. Files are picked up once their size stopped changing between two scans; names
+  starting with a dot or ending in .part or .tmp are ignored.
+
+  rules:
+    - name: credentials
+      match: "*.sql"
+      table: "*user*"
+      column: [email, password]
+      format: hashcat
+
+  Table is a pattern; tables lacking one of the columns are skipped. Each table a rule extracts is
+  written to <out>/<dump>_<rule>_<table> with the extension of the format.
+
+Usage:
+  sql-data-extractor watch -dir <intake_dir> -rules <rules.yaml> [options]
+
+Options:
+  -dir        The intake directory to watch. (required)
+  -rules      YAML file with the extraction rules. (required)
+  -out        Directory to write the outputs to. Defaults to <dir>/output.
+  -done       Directory to move processed dumps to. Defaults to <dir>/done.
+  -failed     Directory to move dumps that could not be processed to. Defaults to <dir>/failed.
+  -interval   Time between two scans of the intake directory. Defaults to 5s.
+  -once       Process the dumps present and exit instead of watching.
+  -metrics-listen Address to serve Prometheus metrics on at /metrics, e.g. :9100. If omitted, metrics are not served.
+`+provenanceFlagUsage)
+	dir := flags.String("dir", "", "Intake directory to watch")
+	rulesFilename := flags.String("rules", "", "YAML file with the extraction rules")
+	outDir := flags.String("out", "", "Directory to write the outputs to")
+	doneDir := flags.String("done", "", "Directory to move processed dumps to")
+	failedDir := flags.String("failed", "", "Directory to move failed dumps to")
+	interval := flags.Duration("interval", 5*time.Second, "Time between two scans")
+	once := flags.Bool("once", false, "Process the present dumps and exit")
+	metricsListen := flags.String("metrics-listen", "", "Address to serve Prometheus metrics on")
+	withProvenance := flags.Bool("provenance", false, "Write a provenance record next to every output")
+	if err := parseArgs(flags, args); err != nil {
+		return err
+	}
+	if *dir == "" || *rulesFilename == "" {
+		flags.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("both -dir and -rules flags are required"))
+	}
+
+	rules, err := loadWatchRules(*rulesFilename)
+	if err != nil {
+		return withExitCode(exitUsage, fmt.Errorf("Error reading rules: %s", err))
+	}
+
+	w := &watcher{
+		flags:      flags,
+		dir:        *dir,
+		outDir:     defaultString(*outDir, filepath.Join(*dir, "output")),
+		doneDir:    defaultString(*doneDir, filepath.Join(*dir, "done")),
+		failedDir:  defaultString(*failedDir, filepath.Join(*dir, "failed")),
+		rules:      rules,
+		provenance: *withProvenance,
+		seen:       make(map[string]os.FileInfo),
+	}
+	for _, d := range []string{w.outDir, w.doneDir, w.failedDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return withExitCode(exitIOError, err)
+		}
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	if *metricsListen != "" {
+		listener, err := net.Listen("tcp", *metricsListen)
+		if err != nil {
+			return withExitCode(exitIOError, err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", metrics.Handler())
+		slog.Info("serving metrics", "address", *metricsListen)
+		go http.Serve(listener, mux)
+	}
+
+	slog.Info("watching", "dir", w.dir, "rules", len(rules), "interval", interval.String())
+	for {
+		if err := w.scan(ctx, *once); err != nil {
+			return withExitCode(exitIOError, err)
+		}
+		if *once {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			slog.Info("stopped watching", "dir", w.dir)
+			return nil
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// scan processes the files of the intake directory that are ready. With immediate, files are
+// processed without waiting for a second scan to confirm they are complete.
+func (w *watcher) scan(ctx context.Context, immediate bool) error {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return err
+	}
+	current := make(map[string]os.FileInfo)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".part") || strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		previous, known := w.seen[name]
+		if !immediate && (!known || previous.Size() != info.Size() || !previous.ModTime().Equal(info.ModTime())) {
+			current[name] = info
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		w.process(ctx, name)
+	}
+	w.seen = current
+	return nil
+}
+
+// process applies the rules to one dump and moves it out of the intake directory.
+func (w *watcher) process(ctx context.Context, name string) {
+	path := filepath.Join(w.dir, name)
+	slog.Info("processing dump", "file", path)
+	start := time.Now()
+
+	outputs, err := w.apply(ctx, path)
+	if isInterrupted(err) {
+		// Left in the intake directory, it is processed again on the next start.
+		slog.Warn("interrupted, dump left in the intake directory", "file", path)
+		return
+	}
+	metrics.FileDuration.ObserveSince(start)
+	if err != nil {
+		metrics.FilesProcessed.Inc("failed")
+		slog.Error("processing failed", "file", path, "error", err)
+		w.move(path, w.failedDir)
+		errorFilename := filepath.Join(w.failedDir, name+".error")
+		if werr := os.WriteFile(errorFilename, []byte(err.Error()+"\n"), 0644); werr != nil {
+			slog.Error("Error writing error file", "file", errorFilename, "error", werr)
+		}
+		return
+	}
+	metrics.FilesProcessed.Inc("ok")
+	slog.Info("dump processed", "file", path, "outputs", outputs, "duration", time.Since(start).String())
+	w.move(path, w.doneDir)
+}
+
+// apply runs every rule matching the dump, returning the number of files written.
+func (w *watcher) apply(ctx context.Context, path string) (int, error) {
+	dumps := make(map[string]*extractor.Dump)
+	dumpTables := make(map[string][]string)
+	base := strings.TrimSuffix(filepath.Base(path), ".sql")
+	outputs := 0
+	for _, rule := range w.rules {
+		if matched, _ := filepath.Match(rule.Match, filepath.Base(path)); !matched {
+			continue
+		}
+		dump, ok := dumps[rule.Dialect]
+		if !ok {
+			df := dumpFlags{filename: path, dialect: rule.Dialect}
+			var err error
+			if dump, err = df.open(w.flags); err != nil {
+				return outputs, err
+			}
+			tables, err := dump.Tables()
+			if err != nil {
+				return outputs, err
+			}
+			if len(tables) == 0 {
+				metrics.ParseErrors.Inc("dump")
+				return outputs, withExitCode(exitParseError, fmt.Errorf("no tables found in the dump"))
+			}
+			dumps[rule.Dialect], dumpTables[rule.Dialect] = dump, tables
+		}
+
+		for _, table := range dumpTables[rule.Dialect] {
+			if matched, _ := filepath.Match(rule.Table, table); !matched {
+				continue
+			}
+			ok, err := hasColumns(dump, table, rule.columns)
+			if err != nil {
+				return outputs, err
+			}
+			if !ok {
+				slog.Debug("table skipped, columns missing", "file", path, "rule", rule.Name, "table", table)
+				continue
+			}
+			job := batchJob{
+				dumpFlags:  dumpFlags{filename: path, dialect: rule.Dialect},
+				table:      table,
+				columns:    rule.columns,
+				format:     rule.format,
+				output:     filepath.Join(w.outDir, fmt.Sprintf("%s_%s_%s%s", base, rule.Name, table, rule.format.Extension)),
+				provenance: w.provenance,
+			}
+			count, err := job.run(ctx, dump, nil)
+			if err != nil && exitCode(err) != exitNoRows {
+				return outputs, err
+			}
+			slog.Info("table extracted", "file", path, "rule", rule.Name, "table", table, "rows", count, "output", job.output)
+			outputs++
+		}
+	}
+	return outputs, nil
+}
+
+// move renames the file into dir, adding a timestamp to its name if dir already holds one by that name.
+func (w *watcher) move(path, dir string) {
+	target := filepath.Join(dir, filepath.Base(path))
+	if _, err := os.Stat(target); err == nil {
+		target = fmt.Sprintf("%s.%s", target, time.Now().UTC().Format("20060102T150405"))
+	}
+	if err := os.Rename(path, target); err != nil {
+		slog.Error("Error moving dump", "file", path, "target", target, "error", err)
+	}
+}
+
+// hasColumns reports whether the table has all the named columns.
+func hasColumns(dump *extractor.Dump, tableName string, names []string) (bool, error) {
+	columns, err := dump.Columns(tableName)
+	if err != nil {
+		return false, err
+	}
+	present := make(map[string]bool)
+	for _, column := range columns {
+		present[column.Name] = true
+	}
+	for _, name := range names {
+		if !present[name] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// loadWatchRules reads and checks the rules file of the watch command.
+func loadWatchRules(filename string) ([]watchRule, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Rules []watchRule `yaml:"rules"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, err
+	}
+	if len(file.Rules) == 0 {
+		return nil, fmt.Errorf("no rules listed")
+	}
+
+	names := make(map[string]bool)
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+		rule.Name = defaultString(rule.Name, fmt.Sprintf("rule%d", i+1))
+		rule.Match = defaultString(rule.Match, "*")
+		rule.Table = defaultString(rule.Table, "*")
+		rule.Dialect = defaultString(rule.Dialect, extractor.AutoDetect)
+		if names[rule.Name] {
+			return nil, fmt.Errorf("rule name %s used twice", rule.Name)
+		}
+		names[rule.Name] = true
+
+		for _, pattern := range []string{rule.Match, rule.Table} {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("rule %s: invalid pattern %s", rule.Name, pattern)
+			}
+		}
+		column, err := configString("column", rule.Column)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %s", rule.Name, err)
+		}
+		rule.columns = parseIncludedColumns(column)
+		if _, err := extractor.LookupDialect(rule.Dialect); err != nil && rule.Dialect != extractor.AutoDetect {
+			return nil, fmt.Errorf("rule %s: %s", rule.Name, err)
+		}
+		if rule.format, err = output.Lookup(defaultString(rule.Format, "json")); err != nil {
+			return nil, fmt.Errorf("rule %s: %s", rule.Name, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Usage line of the -where flag.
+const whereFlagUsage = `  -where      Only write the rows matching a condition, such as "active=1" or "email LIKE '%@corp.com'".
+              Compares columns with =, !=, <, <=, >, >=, LIKE, CONTAINS and IS [NOT] NULL, combined with AND,
+              OR, NOT and parentheses.
+`
+
+// warnWhereColumns warns that the columns a table lacks are read as NULL by a condition naming
+// them.
+func warnWhereColumns(missing []string, tableName string) {
+	if len(missing) > 0 {
+		slog.Warn(fmt.Sprintf("table %s has no column %s named in -where, read as NULL", tableName, strings.Join(missing, ", ")))
+	}
+}
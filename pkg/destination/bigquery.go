@@ -0,0 +1,328 @@
+package destination
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"golang.org/x/oauth2/google"
+)
+
+func init() {
+	Register(Scheme{Name: "bigquery", Open: openBigQuery})
+}
+
+// Google APIs used to stage and load the rows.
+var (
+	storageUploadAPI = "https://storage.googleapis.com/upload/storage/v1"
+	storageAPI       = "https://storage.googleapis.com/storage/v1"
+	bigqueryAPI      = "https://bigquery.googleapis.com/bigquery/v2"
+)
+
+// bigqueryDestination loads the tables it receives into BigQuery. The rows of each table are
+// streamed as newline delimited JSON to a staging object in Cloud Storage, which a load job then
+// appends to the BigQuery table, creating it with a schema derived from the CREATE TABLE
+// statement if needed.
+//
+//	bigquery://project/dataset.table?staging=gs://bucket/prefix/&location=EU&keep=true
+//
+// The table defaults to the table name. Staging objects are deleted once loaded unless keep is
+// true. Credentials are Google's Application Default Credentials.
+type bigqueryDestination struct {
+	client   *http.Client
+	project  string
+	dataset  string
+	table    string
+	bucket   string
+	prefix   string
+	location string
+	keep     bool
+
+	columns  []bigqueryField
+	tableID  string
+	object   string
+	pipe     *io.PipeWriter
+	buf      *bufio.Writer
+	uploaded chan error
+}
+
+// bigqueryField is a column of a load job schema.
+type bigqueryField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+func openBigQuery(u *url.URL, opts Options) (Destination, error) {
+	query := u.Query()
+	dataset, table, _ := strings.Cut(strings.Trim(u.Path, "/"), ".")
+	if u.Host == "" || dataset == "" {
+		return nil, configErrorf("expected bigquery://project/dataset or bigquery://project/dataset.table, got %s", u.Redacted())
+	}
+	staging, err := url.Parse(query.Get("staging"))
+	if err != nil || staging.Scheme != "gs" || staging.Host == "" {
+		return nil, configErrorf("the staging parameter must name a Cloud Storage location such as gs://bucket/prefix/")
+	}
+	prefix := strings.TrimPrefix(staging.Path, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	client, err := google.DefaultClient(context.Background(), "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return nil, err
+	}
+	return &bigqueryDestination{
+		client:   client,
+		project:  u.Host,
+		dataset:  dataset,
+		table:    table,
+		bucket:   staging.Host,
+		prefix:   prefix,
+		location: query.Get("location"),
+		keep:     query.Get("keep") == "true",
+	}, nil
+}
+
+func (b *bigqueryDestination) Begin(tableName string, columns []extractor.Column) error {
+	b.tableID = b.table
+	if b.tableID == "" {
+		b.tableID = tableName
+	}
+	b.columns = make([]bigqueryField, len(columns))
+	for i, col := range columns {
+		b.columns[i] = bigqueryField{Name: col.Name, Type: bigqueryType(col.Type)}
+	}
+	b.object = fmt.Sprintf("%s%s-%d.json", b.prefix, b.tableID, time.Now().UnixNano())
+
+	// The object is uploaded as the rows are written, in a single streamed request.
+	reader, pipe := io.Pipe()
+	b.pipe = pipe
+	b.buf = bufio.NewWriterSize(pipe, 1<<20)
+	b.uploaded = make(chan error, 1)
+	go func() {
+		endpoint := fmt.Sprintf("%s/b/%s/o?uploadType=media&name=%s", storageUploadAPI, url.PathEscape(b.bucket), url.QueryEscape(b.object))
+		err := b.call(http.MethodPost, endpoint, "application/x-ndjson", reader, nil)
+		reader.CloseWithError(fmt.Errorf("upload ended"))
+		b.uploaded <- err
+	}()
+	return nil
+}
+
+func (b *bigqueryDestination) WriteRecord(record extractor.Record) error {
+	b.buf.WriteByte('{')
+	for i, field := range record {
+		if i > 0 {
+			b.buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(field.Name)
+		b.buf.Write(name)
+		b.buf.WriteByte(':')
+		b.buf.Write(bigqueryValue(field, b.columns[i].Type))
+	}
+	if _, err := b.buf.WriteString("}\n"); err != nil {
+		return b.abort(err)
+	}
+	return nil
+}
+
+// abort ends a failed upload, returning its error rather than the one of the broken pipe.
+func (b *bigqueryDestination) abort(err error) error {
+	b.pipe.CloseWithError(err)
+	if uploadErr := <-b.uploaded; uploadErr != nil {
+		err = uploadErr
+	}
+	b.pipe = nil
+	return fmt.Errorf("Error uploading gs://%s/%s: %s", b.bucket, b.object, err)
+}
+
+func (b *bigqueryDestination) End() error {
+	if err := b.buf.Flush(); err != nil {
+		return b.abort(err)
+	}
+	b.pipe.Close()
+	b.pipe = nil
+	if err := <-b.uploaded; err != nil {
+		return fmt.Errorf("Error uploading gs://%s/%s: %s", b.bucket, b.object, err)
+	}
+
+	if err := b.load(); err != nil {
+		return fmt.Errorf("Error loading gs://%s/%s into %s.%s.%s: %s", b.bucket, b.object, b.project, b.dataset, b.tableID, err)
+	}
+	if !b.keep {
+		endpoint := fmt.Sprintf("%s/b/%s/o/%s", storageAPI, url.PathEscape(b.bucket), url.PathEscape(b.object))
+		if err := b.call(http.MethodDelete, endpoint, "", nil, nil); err != nil {
+			slog.Warn("staging object not deleted", "object", "gs://"+b.bucket+"/"+b.object, "error", err)
+		}
+	}
+	return nil
+}
+
+// load runs a load job appending the staging object to the table, and waits for it to finish.
+func (b *bigqueryDestination) load() error {
+	var job struct {
+		JobReference struct {
+			JobID    string `json:"jobId"`
+			Location string `json:"location"`
+		} `json:"jobReference"`
+		Status struct {
+			State       string `json:"state"`
+			ErrorResult *struct {
+				Message string `json:"message"`
+			} `json:"errorResult"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"status"`
+	}
+	request := map[string]interface{}{
+		"configuration": map[string]interface{}{
+			"load": map[string]interface{}{
+				"sourceUris":        []string{"gs://" + b.bucket + "/" + b.object},
+				"sourceFormat":      "NEWLINE_DELIMITED_JSON",
+				"destinationTable":  map[string]string{"projectId": b.project, "datasetId": b.dataset, "tableId": b.tableID},
+				"schema":            map[string]interface{}{"fields": b.columns},
+				"createDisposition": "CREATE_IF_NEEDED",
+				"writeDisposition":  "WRITE_APPEND",
+			},
+		},
+	}
+	if b.location != "" {
+		request["jobReference"] = map[string]string{"location": b.location}
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	if err := b.call(http.MethodPost, fmt.Sprintf("%s/projects/%s/jobs", bigqueryAPI, url.PathEscape(b.project)), "application/json", bytes.NewReader(body), &job); err != nil {
+		return err
+	}
+
+	delay := time.Second
+	for job.Status.State != "DONE" {
+		time.Sleep(delay)
+		if delay < 30*time.Second {
+			delay *= 2
+		}
+		endpoint := fmt.Sprintf("%s/projects/%s/jobs/%s?location=%s", bigqueryAPI, url.PathEscape(b.project), url.PathEscape(job.JobReference.JobID), url.QueryEscape(job.JobReference.Location))
+		if err := b.call(http.MethodGet, endpoint, "", nil, &job); err != nil {
+			return err
+		}
+	}
+	if job.Status.ErrorResult != nil {
+		message := job.Status.ErrorResult.Message
+		if len(job.Status.Errors) > 1 {
+			message += fmt.Sprintf(" (%d errors, first: %s)", len(job.Status.Errors), job.Status.Errors[0].Message)
+		}
+		return fmt.Errorf("%s", message)
+	}
+	return nil
+}
+
+// call sends a request to a Google API, decoding the JSON response into result unless it is nil.
+func (b *bigqueryDestination) call(method, endpoint, contentType string, body io.Reader, result interface{}) error {
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s", responseError(resp))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// Close abandons an upload that was not ended, so no staging object is created.
+func (b *bigqueryDestination) Close() error {
+	if b.pipe != nil {
+		b.pipe.CloseWithError(fmt.Errorf("upload aborted"))
+		<-b.uploaded
+		b.pipe = nil
+	}
+	return nil
+}
+
+// bigqueryValue renders the value of a field as JSON for a column of the given BigQuery type.
+// Values the dump stored as NULL, and MySQL's zero dates, become null. Bytes are base64 encoded
+// as BigQuery expects.
+func bigqueryValue(field extractor.Field, columnType string) []byte {
+	value := field.Value
+	switch {
+	case field.Null:
+		return []byte("null")
+	case columnType == "BYTES":
+		value = base64.StdEncoding.EncodeToString([]byte(value))
+	case columnType == "DATE" || columnType == "DATETIME" || columnType == "TIMESTAMP":
+		if strings.HasPrefix(value, "0000-00-00") {
+			return []byte("null")
+		}
+	}
+	encoded, _ := json.Marshal(value)
+	return encoded
+}
+
+// bigqueryType maps a MySQL column type to the BigQuery type of the load job schema.
+func bigqueryType(declared string) string {
+	declared = strings.ToLower(declared)
+	match := mysqlTypeRegex.FindStringSubmatch(declared)
+	if match == nil {
+		return "STRING"
+	}
+	name, args := match[1], match[2]
+
+	switch name {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "year", "bool", "boolean":
+		return "INTEGER"
+	case "bigint":
+		// Unsigned values above the range of INT64 only fit a NUMERIC.
+		if strings.Contains(declared, "unsigned") {
+			return "NUMERIC"
+		}
+		return "INTEGER"
+	case "decimal", "numeric", "dec", "fixed":
+		precision, scale, _ := strings.Cut(args, ",")
+		p, _ := strconv.Atoi(strings.TrimSpace(precision))
+		s, _ := strconv.Atoi(strings.TrimSpace(scale))
+		if p-s > 29 || s > 9 {
+			return "BIGNUMERIC"
+		}
+		return "NUMERIC"
+	case "float", "double", "real":
+		return "FLOAT"
+	case "date":
+		return "DATE"
+	case "datetime":
+		return "DATETIME"
+	case "timestamp":
+		return "TIMESTAMP"
+	case "time":
+		return "TIME"
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		return "BYTES"
+	case "json":
+		return "JSON"
+	default:
+		// Text, enum, set, bit and anything unknown.
+		return "STRING"
+	}
+}
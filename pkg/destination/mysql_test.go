@@ -0,0 +1,37 @@
+package destination
+
+import (
+	"testing"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+func TestMysqlCreateTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		create  string
+		dialect string
+		want    string
+	}{
+		{
+			"mysql", "CREATE TABLE `users` (\n  `id` int(10) unsigned NOT NULL,\n  `code` smallint(5) UNSIGNED ZEROFILL,\n  `balance` decimal(10,2) signed,\n  `name` varchar(255)\n) ENGINE=InnoDB;", "mysql",
+			"CREATE TABLE IF NOT EXISTS `users` (`id` int(10) unsigned, `code` smallint(5) UNSIGNED ZEROFILL, `balance` decimal(10,2) signed, `name` varchar(255))",
+		},
+		{
+			"postgres", "CREATE TABLE public.users (\n    id integer NOT NULL,\n    name character varying(64),\n    created timestamp with time zone\n);", "postgres",
+			"CREATE TABLE IF NOT EXISTS `users` (`id` INT, `name` VARCHAR(64), `created` VARCHAR(64))",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dialect, _ := extractor.LookupDialect(test.dialect)
+			columns, err := dialect.Columns(test.create)
+			if err != nil {
+				t.Fatalf("Columns: %v", err)
+			}
+			if got := mysqlCreateTable("users", columns, test.dialect); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package destination
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/redis/go-redis/v9"
+)
+
+func init() {
+	Register(Scheme{Name: "redis", Open: openRedis})
+	Register(Scheme{Name: "rediss", Open: openRedis})
+}
+
+// Query parameters configuring the destination rather than the connection.
+var redisParams = []string{"key", "mode", "value", "ttl", "batch"}
+
+// redisDestination loads every record into Redis under a key rendered from its fields, either as
+// a hash of its fields or as a single string value.
+//
+//	redis://:password@host:6379/0?key=user:{{.email}}&mode=hash&ttl=24h&batch=1000
+//
+// key is a text/template over the fields of a record, by default <table>:{{.<first column>}}.
+// In string mode the value is the column named by value, or the record as JSON.
+type redisDestination struct {
+	client      *redis.Client
+	keyTemplate *template.Template
+	hashes      bool
+	valueColumn string
+	ttl         time.Duration
+	batchSize   int
+
+	key     *template.Template
+	pipe    redis.Pipeliner
+	pending int
+}
+
+func openRedis(u *url.URL, opts Options) (Destination, error) {
+	query := u.Query()
+	batchSize, err := intParam(u, "batch", 1000)
+	if err != nil {
+		return nil, err
+	}
+	r := &redisDestination{valueColumn: query.Get("value"), batchSize: batchSize}
+	switch mode := query.Get("mode"); mode {
+	case "", "hash":
+		r.hashes = true
+	case "string":
+	default:
+		return nil, configErrorf("unknown mode parameter %s, expected hash or string", mode)
+	}
+	if r.hashes && r.valueColumn != "" {
+		return nil, configErrorf("the value parameter only applies to mode=string")
+	}
+	if key := query.Get("key"); key != "" {
+		if r.keyTemplate, err = parseKeyTemplate(key); err != nil {
+			return nil, err
+		}
+	}
+	if ttl := query.Get("ttl"); ttl != "" {
+		if r.ttl, err = time.ParseDuration(ttl); err != nil || r.ttl <= 0 {
+			return nil, configErrorf("invalid ttl parameter %q, expected a duration such as 24h", ttl)
+		}
+	}
+	for _, name := range redisParams {
+		query.Del(name)
+	}
+
+	connection := *u
+	connection.RawQuery = query.Encode()
+	options, err := redis.ParseURL(connection.String())
+	if err != nil {
+		return nil, configErrorf("invalid Redis URL %s: %s", u.Redacted(), err)
+	}
+	r.client = redis.NewClient(options)
+	if err := r.client.Ping(context.Background()).Err(); err != nil {
+		r.client.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func parseKeyTemplate(key string) (*template.Template, error) {
+	t, err := template.New("key").Option("missingkey=error").Parse(key)
+	if err != nil {
+		return nil, configErrorf("invalid key template %s: %s", key, err)
+	}
+	return t, nil
+}
+
+func (r *redisDestination) Begin(tableName string, columns []extractor.Column) error {
+	r.key = r.keyTemplate
+	if r.key == nil {
+		if len(columns) == 0 {
+			return fmt.Errorf("table %s has no columns to build keys from", tableName)
+		}
+		key, err := parseKeyTemplate(fmt.Sprintf("%s:{{index . %q}}", tableName, columns[0].Name))
+		if err != nil {
+			return err
+		}
+		r.key = key
+	}
+	r.pipe = r.client.Pipeline()
+	return nil
+}
+
+func (r *redisDestination) WriteRecord(record extractor.Record) error {
+	fields := make(map[string]string, len(record))
+	for _, field := range record {
+		fields[field.Name] = field.Value
+	}
+	var key strings.Builder
+	if err := r.key.Execute(&key, fields); err != nil {
+		return fmt.Errorf("Error rendering key: %s", err)
+	}
+
+	ctx := context.Background()
+	if r.hashes {
+		values := make([]interface{}, 0, 2*len(record))
+		for _, field := range record {
+			// Hashes cannot hold nil, fields the dump stored as NULL are left out.
+			if !field.Null {
+				values = append(values, field.Name, field.Value)
+			}
+		}
+		if len(values) > 0 {
+			r.pipe.HSet(ctx, key.String(), values...)
+		}
+		if r.ttl > 0 {
+			r.pipe.Expire(ctx, key.String(), r.ttl)
+		}
+	} else if r.valueColumn != "" {
+		value, ok := fields[r.valueColumn]
+		if !ok {
+			return fmt.Errorf("no column %s for the value parameter", r.valueColumn)
+		}
+		r.pipe.Set(ctx, key.String(), value, r.ttl)
+	} else {
+		var document bytes.Buffer
+		writeJSONDocument(&document, record)
+		r.pipe.Set(ctx, key.String(), document.Bytes(), r.ttl)
+	}
+
+	r.pending++
+	if r.pending >= r.batchSize {
+		return r.flush()
+	}
+	return nil
+}
+
+// flush sends the pending commands in one round trip.
+func (r *redisDestination) flush() error {
+	if r.pending == 0 {
+		return nil
+	}
+	r.pending = 0
+	if _, err := r.pipe.Exec(context.Background()); err != nil {
+		return fmt.Errorf("Error loading into Redis: %s", err)
+	}
+	return nil
+}
+
+func (r *redisDestination) End() error {
+	return r.flush()
+}
+
+func (r *redisDestination) Close() error {
+	return r.client.Close()
+}
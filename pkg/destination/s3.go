@@ -0,0 +1,150 @@
+package destination
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+func init() {
+	Register(Scheme{Name: "s3", Open: openObjectStore("s3.amazonaws.com")})
+	Register(Scheme{Name: "gs", Open: openObjectStore("storage.googleapis.com")})
+}
+
+// Size of the parts of multipart uploads. Outputs are streamed without knowing their size, so
+// this bounds the memory used per upload and, with at most 10000 parts, the size of an object.
+const objectPartSize = 64 << 20
+
+// objectStoreDestination uploads every table as an object in the chosen output format, streaming
+// the output as a multipart upload instead of writing a local file first.
+//
+//	s3://bucket/prefix/?region=eu-west-1&endpoint=minio.internal:9000&tls=false
+//	gs://bucket/prefix/
+//
+// Objects are named prefix + table + extension of the format. A path not ending in a slash names
+// the object itself, for a single table. Credentials are read from the environment like the AWS
+// tools do, from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, ~/.aws/credentials or the instance
+// role. Google Cloud Storage is accessed through its S3 compatible API, with HMAC keys.
+type objectStoreDestination struct {
+	client *minio.Client
+	scheme string
+	bucket string
+	path   string
+	format output.Format
+
+	key     string
+	pipe    *io.PipeWriter
+	buffer  *bufio.Writer
+	writer  output.Writer
+	done    chan error
+	written int
+}
+
+func openObjectStore(defaultEndpoint string) func(u *url.URL, opts Options) (Destination, error) {
+	return func(u *url.URL, opts Options) (Destination, error) {
+		if u.Host == "" {
+			return nil, configErrorf("no bucket given in %s", u.Redacted())
+		}
+		query := u.Query()
+		endpoint := query.Get("endpoint")
+		if endpoint == "" {
+			endpoint = defaultEndpoint
+		}
+		client, err := minio.New(endpoint, &minio.Options{
+			Creds: credentials.NewChainCredentials([]credentials.Provider{
+				&credentials.EnvAWS{},
+				&credentials.FileAWSCredentials{},
+				&credentials.EnvMinio{},
+				&credentials.IAM{},
+			}),
+			Secure: query.Get("tls") != "false",
+			Region: query.Get("region"),
+		})
+		if err != nil {
+			return nil, configErrorf("invalid object store endpoint %s: %s", endpoint, err)
+		}
+		return &objectStoreDestination{
+			client: client,
+			scheme: u.Scheme,
+			bucket: u.Host,
+			path:   strings.TrimPrefix(u.Path, "/"),
+			format: opts.Format,
+		}, nil
+	}
+}
+
+func (s *objectStoreDestination) Begin(tableName string, columns []extractor.Column) error {
+	s.key = s.path
+	if s.path == "" || strings.HasSuffix(s.path, "/") {
+		s.key = s.path + tableName + s.format.Extension
+	} else if s.written > 0 {
+		return fmt.Errorf("%s names a single object, end it with a slash to upload several tables", s.path)
+	}
+
+	reader, pipe := io.Pipe()
+	s.pipe = pipe
+	s.buffer = bufio.NewWriter(pipe)
+	s.writer = s.format.New(s.buffer)
+	s.done = make(chan error, 1)
+	go func() {
+		_, err := s.client.PutObject(context.Background(), s.bucket, s.key, reader, -1, minio.PutObjectOptions{
+			ContentType: s.format.ContentType,
+			PartSize:    objectPartSize,
+		})
+		// Unblocks writers if the upload failed before reading everything.
+		reader.CloseWithError(err)
+		s.done <- err
+	}()
+	return s.writer.Begin(tableName, columns)
+}
+
+func (s *objectStoreDestination) WriteRecord(record extractor.Record) error {
+	if err := s.writer.WriteRecord(record); err != nil {
+		return s.fail(err)
+	}
+	return nil
+}
+
+// fail reports the error of a write, which is usually caused by the upload having failed.
+func (s *objectStoreDestination) fail(err error) error {
+	s.pipe.CloseWithError(err)
+	if uploadErr := <-s.done; uploadErr != nil {
+		err = uploadErr
+	}
+	s.pipe = nil
+	return fmt.Errorf("Error uploading %s://%s/%s: %s", s.scheme, s.bucket, s.key, err)
+}
+
+func (s *objectStoreDestination) End() error {
+	if err := s.writer.End(); err != nil {
+		return s.fail(err)
+	}
+	if err := s.buffer.Flush(); err != nil {
+		return s.fail(err)
+	}
+	s.pipe.Close()
+	s.pipe = nil
+	if err := <-s.done; err != nil {
+		return fmt.Errorf("Error uploading %s://%s/%s: %s", s.scheme, s.bucket, s.key, err)
+	}
+	s.written++
+	return nil
+}
+
+// Close aborts an upload that was not ended, so no partial object is created.
+func (s *objectStoreDestination) Close() error {
+	if s.pipe != nil {
+		s.pipe.CloseWithError(fmt.Errorf("upload aborted"))
+		<-s.done
+		s.pipe = nil
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package destination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"golang.org/x/oauth2/google"
+)
+
+func init() {
+	Register(Scheme{Name: "sheets", Open: openSheets})
+}
+
+var sheetsAPI = "https://sheets.googleapis.com/v4/spreadsheets"
+
+// Longest text a cell of a Google Sheet can hold.
+const sheetsCellLimit = 50000
+
+// sheetsDestination writes small extractions into a tab of a Google Sheet, the columns as header
+// row followed by the rows.
+//
+//	sheets://<spreadsheet id>/<tab>?limit=5000
+//
+// The tab defaults to the table name and is created if needed, or cleared if it exists. Rows
+// beyond limit are dropped, and values longer than a cell can hold are cut, both with a warning.
+// Credentials are Google's Application Default Credentials.
+type sheetsDestination struct {
+	client        *http.Client
+	spreadsheetID string
+	tab           string
+	limit         int
+
+	title     string
+	rows      [][]string
+	written   int
+	dropped   int
+	truncated int
+}
+
+func openSheets(u *url.URL, opts Options) (Destination, error) {
+	limit, err := intParam(u, "limit", 10000)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, configErrorf("no spreadsheet given, expected sheets://<spreadsheet id>/<tab>")
+	}
+	client, err := google.DefaultClient(context.Background(), "https://www.googleapis.com/auth/spreadsheets")
+	if err != nil {
+		return nil, err
+	}
+	return &sheetsDestination{
+		client:        client,
+		spreadsheetID: u.Host,
+		tab:           strings.Trim(u.Path, "/"),
+		limit:         limit,
+	}, nil
+}
+
+func (s *sheetsDestination) Begin(tableName string, columns []extractor.Column) error {
+	s.title = s.tab
+	if s.title == "" {
+		s.title = tableName
+	}
+	s.written, s.dropped, s.truncated = 0, 0, 0
+
+	var spreadsheet struct {
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+	if err := s.call(http.MethodGet, "?fields=sheets.properties.title", nil, &spreadsheet); err != nil {
+		return err
+	}
+	exists := false
+	for _, sheet := range spreadsheet.Sheets {
+		exists = exists || sheet.Properties.Title == s.title
+	}
+	if exists {
+		if err := s.call(http.MethodPost, "/values/"+url.PathEscape(s.quotedTitle())+":clear", struct{}{}, nil); err != nil {
+			return err
+		}
+	} else {
+		addSheet := map[string]interface{}{
+			"requests": []interface{}{
+				map[string]interface{}{"addSheet": map[string]interface{}{"properties": map[string]string{"title": s.title}}},
+			},
+		}
+		if err := s.call(http.MethodPost, ":batchUpdate", addSheet, nil); err != nil {
+			return err
+		}
+	}
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	s.rows = append(s.rows[:0], header)
+	return nil
+}
+
+// quotedTitle is the tab in A1 notation.
+func (s *sheetsDestination) quotedTitle() string {
+	return "'" + strings.ReplaceAll(s.title, "'", "''") + "'"
+}
+
+func (s *sheetsDestination) WriteRecord(record extractor.Record) error {
+	// The header row is not counted.
+	if s.written+len(s.rows)-1 >= s.limit {
+		s.dropped++
+		return nil
+	}
+	row := make([]string, len(record))
+	for i, field := range record {
+		row[i] = field.Value
+		if field.Null {
+			row[i] = ""
+		}
+		if len(row[i]) > sheetsCellLimit {
+			row[i] = strings.ToValidUTF8(row[i][:sheetsCellLimit], "")
+			s.truncated++
+		}
+	}
+	s.rows = append(s.rows, row)
+	if len(s.rows) >= 1000 {
+		return s.flush()
+	}
+	return nil
+}
+
+// flush appends the pending rows to the tab.
+func (s *sheetsDestination) flush() error {
+	if len(s.rows) == 0 {
+		return nil
+	}
+	path := "/values/" + url.PathEscape(s.quotedTitle()) + ":append?valueInputOption=RAW&insertDataOption=INSERT_ROWS"
+	if err := s.call(http.MethodPost, path, map[string]interface{}{"values": s.rows}, nil); err != nil {
+		return err
+	}
+	s.written += len(s.rows)
+	s.rows = s.rows[:0]
+	return nil
+}
+
+func (s *sheetsDestination) End() error {
+	if err := s.flush(); err != nil {
+		return err
+	}
+	if s.dropped > 0 {
+		slog.Warn("sheet truncated, rows over the limit dropped", "tab", s.title, "limit", s.limit, "dropped", s.dropped)
+	}
+	if s.truncated > 0 {
+		slog.Warn("values cut to the cell limit", "tab", s.title, "values", s.truncated, "limit", sheetsCellLimit)
+	}
+	return nil
+}
+
+func (s *sheetsDestination) Close() error {
+	return nil
+}
+
+// call sends a request about the spreadsheet to the Sheets API, encoding body and decoding the
+// response as JSON.
+func (s *sheetsDestination) call(method, path string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, sheetsAPI+"/"+url.PathEscape(s.spreadsheetID)+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Error writing to sheet %s: %s", s.title, responseError(resp))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
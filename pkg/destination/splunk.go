@@ -0,0 +1,134 @@
+package destination
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+func init() {
+	Register(Scheme{Name: "splunk", Open: openSplunk})
+}
+
+// Event metadata that can be given as query parameters.
+var splunkMetadata = []string{"index", "sourcetype", "source", "host"}
+
+// splunkDestination sends every record as an event to a Splunk HTTP Event Collector.
+//
+//	splunk://hec.internal:8088/?token=secret&index=breaches&sourcetype=_json&source=dump.sql&batch=100
+//
+// Events are posted over HTTPS unless tls=false, insecure=true accepts self-signed certificates.
+// The source defaults to the table name.
+type splunkDestination struct {
+	client    *http.Client
+	url       string
+	token     string
+	metadata  map[string]string
+	batchSize int
+
+	table   string
+	body    bytes.Buffer
+	pending int
+}
+
+func openSplunk(u *url.URL, opts Options) (Destination, error) {
+	query := u.Query()
+	batchSize, err := intParam(u, "batch", 100)
+	if err != nil {
+		return nil, err
+	}
+	token := query.Get("token")
+	if token == "" {
+		return nil, configErrorf("no HEC token given, add token=<token> to %s", u.Redacted())
+	}
+	scheme := "https"
+	if query.Get("tls") == "false" {
+		scheme = "http"
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if query.Get("insecure") == "true" {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	s := &splunkDestination{
+		client:    &http.Client{Timeout: time.Minute, Transport: transport},
+		url:       scheme + "://" + u.Host + "/services/collector/event",
+		token:     token,
+		metadata:  make(map[string]string),
+		batchSize: batchSize,
+	}
+	for _, name := range splunkMetadata {
+		if value := query.Get(name); value != "" {
+			s.metadata[name] = value
+		}
+	}
+	return s, nil
+}
+
+func (s *splunkDestination) Begin(tableName string, columns []extractor.Column) error {
+	s.table = tableName
+	return nil
+}
+
+func (s *splunkDestination) WriteRecord(record extractor.Record) error {
+	// Events are JSON objects sent one after another, each wrapping a record with its metadata.
+	var event bytes.Buffer
+	writeJSONDocument(&event, record)
+	s.body.WriteString(`{"event":`)
+	s.body.Write(event.Bytes())
+	if _, ok := s.metadata["source"]; !ok {
+		source, _ := json.Marshal(s.table)
+		s.body.WriteString(`,"source":`)
+		s.body.Write(source)
+	}
+	for _, name := range splunkMetadata {
+		if value, ok := s.metadata[name]; ok {
+			encoded, _ := json.Marshal(value)
+			fmt.Fprintf(&s.body, `,%q:%s`, name, encoded)
+		}
+	}
+	s.body.WriteString("}\n")
+
+	s.pending++
+	if s.pending >= s.batchSize {
+		return s.flush()
+	}
+	return nil
+}
+
+// flush posts the pending events in one request.
+func (s *splunkDestination) flush() error {
+	if s.pending == 0 {
+		return nil
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(s.body.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Splunk "+s.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Error sending events to %s: %s", s.url, responseError(resp))
+	}
+	s.body.Reset()
+	s.pending = 0
+	return nil
+}
+
+func (s *splunkDestination) End() error {
+	return s.flush()
+}
+
+func (s *splunkDestination) Close() error {
+	return nil
+}
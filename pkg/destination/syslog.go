@@ -0,0 +1,125 @@
+package destination
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+func init() {
+	Register(Scheme{Name: "syslog", Open: openSyslog("udp")})
+	Register(Scheme{Name: "syslog+tcp", Open: openSyslog("tcp")})
+	Register(Scheme{Name: "syslog+tls", Open: openSyslog("tls")})
+}
+
+// syslogDestination sends every record as an RFC 5424 syslog message whose text is the record as
+// JSON.
+//
+//	syslog://siem.internal:514?facility=16&severity=6&app=sql-data-extractor
+//	syslog+tcp://siem.internal:601
+//	syslog+tls://siem.internal:6514
+//
+// Over TCP and TLS messages are framed by octet counting, as described by RFC 6587. The message
+// id is the table name.
+type syslogDestination struct {
+	conn     net.Conn
+	stream   bool
+	buf      *bufio.Writer
+	priority int
+	hostname string
+	app      string
+
+	table string
+}
+
+func openSyslog(network string) func(u *url.URL, opts Options) (Destination, error) {
+	return func(u *url.URL, opts Options) (Destination, error) {
+		query := u.Query()
+		facility, err := syslogNumber(query.Get("facility"), "facility", 1, 23)
+		if err != nil {
+			return nil, err
+		}
+		severity, err := syslogNumber(query.Get("severity"), "severity", 6, 7)
+		if err != nil {
+			return nil, err
+		}
+		if u.Port() == "" {
+			return nil, configErrorf("no port given in %s", u.Redacted())
+		}
+		app := query.Get("app")
+		if app == "" {
+			app = "sql-data-extractor"
+		}
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "-"
+		}
+
+		var conn net.Conn
+		switch network {
+		case "tls":
+			conn, err = tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", u.Host, &tls.Config{ServerName: u.Hostname()})
+		default:
+			conn, err = net.DialTimeout(network, u.Host, 30*time.Second)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &syslogDestination{
+			conn:     conn,
+			stream:   network != "udp",
+			buf:      bufio.NewWriter(conn),
+			priority: facility*8 + severity,
+			hostname: hostname,
+			app:      app,
+		}, nil
+	}
+}
+
+// syslogNumber parses the facility or severity parameter.
+func syslogNumber(value, name string, fallback, max int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || n > max {
+		return 0, configErrorf("invalid %s parameter %q, expected a number from 0 to %d", name, value, max)
+	}
+	return n, nil
+}
+
+func (s *syslogDestination) Begin(tableName string, columns []extractor.Column) error {
+	s.table = tableName
+	return nil
+}
+
+func (s *syslogDestination) WriteRecord(record extractor.Record) error {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "<%d>1 %s %s %s %d %s - ", s.priority, time.Now().UTC().Format(time.RFC3339Nano), s.hostname, s.app, os.Getpid(), s.table)
+	writeJSONDocument(&message, record)
+
+	if !s.stream {
+		// Every datagram carries one message.
+		_, err := s.conn.Write(message.Bytes())
+		return err
+	}
+	fmt.Fprintf(s.buf, "%d ", message.Len())
+	_, err := s.buf.Write(message.Bytes())
+	return err
+}
+
+func (s *syslogDestination) End() error {
+	return s.buf.Flush()
+}
+
+func (s *syslogDestination) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,185 @@
+package extractor
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Length of the start of a dump its compression is recognised by, which covers the magic of tar
+// archives at offset 257.
+const magicLength = 512
+
+// compressionExtensions maps the extensions of compressed dumps to the format they name, which
+// a dump bearing one must be in.
+var compressionExtensions = map[string]string{
+	".gz":  "gzip",
+	".tgz": "gzip",
+	".bz2": "bzip2",
+	".zip": "zip",
+	".tar": "tar",
+}
+
+// Decompress returns a function opening the dump like open, decompressing it as it is read if it
+// is compressed with gzip or bzip2, and reading the first .sql file of zip and tar archives,
+// including gzip or bzip2 compressed tar archives. The compression is recognised by the first
+// bytes of the dump, name being its file name: dumps named with the extension of a compression
+// format fail to open if they aren't in it. Reading a zip archive requires the reader open
+// returns to implement io.ReaderAt and a Stat method, as *os.File does.
+func Decompress(name string, open func() (io.ReadCloser, error)) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		r, err := open()
+		if err != nil {
+			return nil, err
+		}
+		decompressed, err := decompress(name, r, r, true)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		return decompressed, nil
+	}
+}
+
+// decompressedReader reads a decompressed dump, closing the readers it is read through.
+type decompressedReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *decompressedReader) Close() error {
+	var err error
+	for _, c := range r.closers {
+		if closeErr := c.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// decompress returns the content of r, which is named name and read from file, the reader open
+// returned. root is set for file itself, which plain dumps are returned as, keeping it seekable
+// for Dump.Truncated.
+func decompress(name string, r io.Reader, file io.ReadCloser, root bool) (io.ReadCloser, error) {
+	head := make([]byte, magicLength)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+	// The start of r again, read from head.
+	rest := io.MultiReader(bytes.NewReader(head), r)
+
+	switch {
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		z, err := gzip.NewReader(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		inner, err := decompress(trimCompression(name), z, file, false)
+		if err != nil {
+			return nil, err
+		}
+		return &decompressedReader{Reader: inner, closers: []io.Closer{inner, z}}, nil
+	case bytes.HasPrefix(head, []byte("BZh")):
+		return decompress(trimCompression(name), bzip2.NewReader(rest), file, false)
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")) || bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return zipMember(name, file, root)
+	case len(head) >= 262 && string(head[257:262]) == "ustar":
+		return tarMember(name, tar.NewReader(rest), file)
+	}
+
+	if format, ok := compressionExtensions[strings.ToLower(path.Ext(name))]; ok {
+		return nil, fmt.Errorf("%s is not a %s file", name, format)
+	}
+	if seeker, ok := file.(io.Seeker); ok && root {
+		if _, err := seeker.Seek(0, io.SeekStart); err == nil {
+			return file, nil
+		}
+	}
+	return &decompressedReader{Reader: rest, closers: []io.Closer{file}}, nil
+}
+
+// zipMember returns the first .sql file of a zip archive, which is read from file.
+func zipMember(name string, file io.ReadCloser, root bool) (io.ReadCloser, error) {
+	archive, ok := file.(interface {
+		io.ReaderAt
+		Stat() (fs.FileInfo, error)
+	})
+	if !ok || !root {
+		return nil, fmt.Errorf("%s: zip archives can only be read from a file", name)
+	}
+	info, err := archive.Stat()
+	if err != nil {
+		return nil, err
+	}
+	z, err := zip.NewReader(archive, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	var names []string
+	for _, f := range z.File {
+		names = append(names, f.Name)
+	}
+	member := dumpMember(names)
+	if member < 0 {
+		return nil, fmt.Errorf("%s holds no .sql file", name)
+	}
+	m, err := z.File[member].Open()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	inner, err := decompress(z.File[member].Name, m, file, false)
+	if err != nil {
+		m.Close()
+		return nil, err
+	}
+	return &decompressedReader{Reader: inner, closers: []io.Closer{inner, m}}, nil
+}
+
+// tarMember returns the first .sql file of a tar archive.
+func tarMember(name string, archive *tar.Reader, file io.ReadCloser) (io.ReadCloser, error) {
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s holds no .sql file", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		if header.Typeflag == tar.TypeReg && dumpMember([]string{header.Name}) == 0 {
+			return decompress(header.Name, archive, file, false)
+		}
+	}
+}
+
+// dumpMember returns the index of the first of the files of an archive that is a dump, a .sql
+// file compressed or not, or -1 if there is none.
+func dumpMember(names []string) int {
+	for i, name := range names {
+		if strings.HasSuffix(strings.ToLower(trimCompression(name)), ".sql") {
+			return i
+		}
+	}
+	return -1
+}
+
+// trimCompression removes the extension of a compression format from name, naming what it
+// holds: dump.sql.gz holds dump.sql and dump.tgz holds dump.tar.
+func trimCompression(name string) string {
+	ext := path.Ext(name)
+	switch strings.ToLower(ext) {
+	case ".gz", ".bz2":
+		return strings.TrimSuffix(name, ext)
+	case ".tgz":
+		return strings.TrimSuffix(name, ext) + ".tar"
+	}
+	return name
+}
@@ -0,0 +1,161 @@
+package extractor
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const compressedDump = "CREATE TABLE `t` (\n  `id` int\n);\nINSERT INTO `t` VALUES (1),(2);\n"
+
+// compressedDump compressed with bzip2, which the standard library can't write.
+const compressedDumpBzip2 = "425a68393141592653591edd2e2200000edf800010406430083a259f00442104002000486a8da43d1a6a7a87" +
+	"a9e50111900001a0a7c60a5fb623a39104c2d954662f28dad5e1a74ddce05a104a1ec52230e0a3a623e20f8bb9229c28480f6e971100"
+
+func gzipped(t *testing.T, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zipped returns a zip archive of files, given as name and content pairs.
+func zipped(t *testing.T, files ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for i := 0; i < len(files); i += 2 {
+		f, err := w.Create(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(files[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// tarred returns a tar archive of files, given as name and content pairs.
+func tarred(t *testing.T, files ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for i := 0; i < len(files); i += 2 {
+		if err := w.WriteHeader(&tar.Header{Name: files[i], Mode: 0o644, Size: int64(len(files[i+1]))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestOpenCompressed(t *testing.T) {
+	bzipped, err := hex.DecodeString(compressedDumpBzip2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		file    string
+		content []byte
+		// err is part of the error opening the dump, empty if it opens.
+		err string
+	}{
+		{"plain", "dump.sql", []byte(compressedDump), ""},
+		{"plain without extension", "dump", []byte(compressedDump), ""},
+		{"gzip", "dump.sql.gz", gzipped(t, []byte(compressedDump)), ""},
+		{"gzip without extension", "dump.sql", gzipped(t, []byte(compressedDump)), ""},
+		{"bzip2", "dump.sql.bz2", bzipped, ""},
+		{"zip", "dump.zip", zipped(t, "readme.txt", "not a dump", "dump.sql", compressedDump), ""},
+		{"zip of a gzip dump", "dump.zip", zipped(t, "dump.sql.gz", string(gzipped(t, []byte(compressedDump)))), ""},
+		{"tar", "dump.tar", tarred(t, "readme.txt", "not a dump", "dump.sql", compressedDump), ""},
+		{"gzip tar", "dump.tgz", gzipped(t, tarred(t, "dump.sql", compressedDump)), ""},
+		{"gzip named bzip2", "dump.sql.bz2", gzipped(t, []byte(compressedDump)), ""},
+		{"plain named gzip", "dump.sql.gz", []byte(compressedDump), "is not a gzip file"},
+		{"plain named zip", "dump.zip", []byte(compressedDump), "is not a zip file"},
+		{"zip without dump", "dump.zip", zipped(t, "readme.txt", "not a dump"), "holds no .sql file"},
+		{"tar without dump", "dump.tar", tarred(t, "readme.txt", "not a dump"), "holds no .sql file"},
+		{"truncated gzip", "dump.sql.gz", gzipped(t, []byte(compressedDump))[:20], "unexpected EOF"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), test.file)
+			if err := os.WriteFile(filename, test.content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			dump, err := Open(filename)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			records, err := dump.Extract("t", Options{})
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got error %v, want one containing %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+			var ids []string
+			for _, record := range records {
+				ids = append(ids, record[0].Value)
+			}
+			if want := []string{"1", "2"}; !slices.Equal(ids, want) {
+				t.Errorf("got rows %v, want %v", ids, want)
+			}
+		})
+	}
+}
+
+func TestDecompressZipRequiresFile(t *testing.T) {
+	content := zipped(t, "dump.sql", compressedDump)
+	open := Decompress("dump.zip", func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(content)), nil
+	})
+	if _, err := open(); err == nil || !strings.Contains(err.Error(), "can only be read from a file") {
+		t.Errorf("got error %v, want one saying zip archives are read from files", err)
+	}
+}
+
+func TestDumpMember(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  int
+	}{
+		{[]string{"dump.sql"}, 0},
+		{[]string{"readme.txt", "backup/DUMP.SQL"}, 1},
+		{[]string{"readme.txt", "dump.sql.gz", "dump.sql"}, 1},
+		{[]string{"dump.sql.bz2"}, 0},
+		{[]string{"dump.tgz", "dump.txt"}, -1},
+		{nil, -1},
+	}
+	for _, test := range tests {
+		if got := dumpMember(test.names); got != test.want {
+			t.Errorf("dumpMember(%q) = %d, want %d", test.names, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package extractor
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Dialect captures the syntax of a particular kind of SQL dump. Dumps are split into statements
+// as they are read, and the extractor recognises tables, column definitions and row data among
+// them exclusively through the dump's Dialect. Statements are passed without the comments
+// preceding them and without their terminating semicolon.
+type Dialect interface {
+	// Name selects the dialect on the command line.
+	Name() string
+	// QuoteIdentifier quotes a table or column name the way the dialect's dumps do.
+	QuoteIdentifier(name string) string
+	// Syntax returns the lexical rules the dialect's dumps are split into statements by.
+	Syntax() Syntax
+	// CreatedTable returns the name of the table a CREATE TABLE statement creates, or false if
+	// the statement creates no table.
+	CreatedTable(statement string) (string, bool)
+	// EndsTable reports whether a statement ends the part of the dump belonging to the table
+	// created last, the way the creation of the next table does.
+	EndsTable(statement string) bool
+	// Columns parses the column definitions of a CREATE TABLE statement, returning a *ParseError
+	// if they cannot be parsed.
+	Columns(createStatement string) ([]Column, error)
+	// InsertedTable returns the name of the table a statement inserts rows into, or false if the
+	// statement carries no row data.
+	InsertedTable(statement string) (string, bool)
+	// Tuples splits a data statement into the raw text of its rows.
+	Tuples(statement string) []string
+	// Values splits the raw text of a row into its raw values.
+	Values(tuple string) []string
+	// Unescape turns a raw value into the value it represents, removing quotes and resolving
+	// escape sequences.
+	Unescape(value string) string
+}
+
+// Syntax describes how the statements of a dialect's dumps are delimited. Statements end with a
+// semicolon outside of strings, quoted identifiers and comments ("--", "#" and "/* */").
+type Syntax struct {
+	// BackslashEscapes is set if a backslash escapes the next character in strings, as in MySQL.
+	// Without it, backslashes only escape in E'...' strings, as in PostgreSQL.
+	BackslashEscapes bool
+	// DollarQuotes is set if strings can also be quoted between $tag$ delimiters.
+	DollarQuotes bool
+	// PsqlScript is set for dumps run by psql, in which COPY ... FROM stdin statements are
+	// followed by their rows, one per line up to a line holding "\.", and lines starting with a
+	// backslash between statements are psql commands. The rows are passed as part of the COPY
+	// statement, after its semicolon. psql commands are skipped like comments.
+	PsqlScript bool
+}
+
+// ColumnLister is implemented by dialects whose data statements may name the columns their rows
+// hold, in an order of their own or leaving some out, as INSERT statements written by mysqldump
+// --complete-insert or by hand do. The values of those rows are matched to the table's columns
+// by name, and the columns left out are NULL.
+type ColumnLister interface {
+	// InsertedColumns returns the names of the columns a data statement lists, in the order of
+	// the values of its rows, or nil if it lists none.
+	InsertedColumns(statement string) []string
+}
+
+// TupleReader is implemented by dialects that can tell where the rows of a data statement stop
+// being readable, as in a statement cut short by a truncated dump or damaged in its middle. The
+// unreadable rest is skipped as a malformed row, rather than the rows it holds being dropped
+// unnoticed.
+type TupleReader interface {
+	// ReadTuples returns the rows of a data statement as Tuples does, along with the text from
+	// the first thing that is neither a row nor a clause that may follow the rows, empty if
+	// there is none.
+	ReadTuples(statement string) (tuples []string, unread string)
+}
+
+// Detector is implemented by dialects whose dumps can be recognised from their start.
+type Detector interface {
+	// Detect reports whether the first bytes of a dump look like one of the dialect's dumps.
+	Detect(head string) bool
+}
+
+// AutoDetect is the dialect name SetDialect detects the dialect of the dump for.
+const AutoDetect = "auto"
+
+// Length of the start of a dump passed to Detector.Detect.
+const detectLength = 64 << 10
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = make(map[string]Dialect)
+)
+
+// RegisterDialect makes a dialect available by name. It panics if the name is already taken.
+func RegisterDialect(dialect Dialect) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	if _, exists := dialects[dialect.Name()]; exists {
+		panic(fmt.Sprintf("extractor: dialect %s registered twice", dialect.Name()))
+	}
+	dialects[dialect.Name()] = dialect
+}
+
+// LookupDialect returns the dialect registered under name.
+func LookupDialect(name string) (Dialect, error) {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	dialect, ok := dialects[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown SQL dialect %s", name)
+	}
+	return dialect, nil
+}
+
+// SetDialect sets the dialect of the dump to the one registered under name, or, if name is
+// AutoDetect, to the dialect detected from the start of the dump.
+func (d *Dump) SetDialect(name string) error {
+	if name != AutoDetect {
+		dialect, err := LookupDialect(name)
+		if err != nil {
+			return err
+		}
+		d.Dialect = dialect
+		return nil
+	}
+	dialect, err := d.DetectDialect()
+	if err != nil {
+		return err
+	}
+	d.Dialect = dialect
+	return nil
+}
+
+// DetectDialect returns the first registered dialect, in name order, recognising the start of
+// the dump, or MySQL if none does.
+func (d *Dump) DetectDialect() (Dialect, error) {
+	r, err := d.open()
+	if err != nil {
+		return nil, &ReadError{Err: err}
+	}
+	defer r.Close()
+	head := make([]byte, detectLength)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, &ReadError{Err: err}
+	}
+
+	for _, name := range Dialects() {
+		dialect, _ := LookupDialect(name)
+		if detector, ok := dialect.(Detector); ok && detector.Detect(string(head[:n])) {
+			return dialect, nil
+		}
+	}
+	return MySQL, nil
+}
+
+// Dialects returns the names of all registered dialects, sorted.
+func Dialects() []string {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,34 @@
+package extractor
+
+import "fmt"
+
+// TableNotFoundError is returned when a requested table is not created in the dump.
+type TableNotFoundError struct {
+	Table string
+}
+
+func (e *TableNotFoundError) Error() string {
+	return fmt.Sprintf("table %s not found in the dump", e.Table)
+}
+
+// ParseError is returned when part of the dump needed to answer a request cannot be parsed.
+type ParseError struct {
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return e.Reason
+}
+
+// ReadError is returned when the dump cannot be read.
+type ReadError struct {
+	Err error
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("error reading the dump: %s", e.Err)
+}
+
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,781 @@
+// Package extractor parses SQL dump files and extracts the rows of individual tables.
+//
+// The syntax of a dump is described by a Dialect. Dumps are parsed as MySQL unless another
+// registered dialect is assigned to Dump.Dialect.
+//
+// A dump is opened with Open (or New for in-memory content), after which its tables can be
+// listed with Tables, inspected with Columns or Schema and read with Extract:
+//
+//	dump, err := extractor.Open("dump.sql")
+//	if err != nil {
+//		return err
+//	}
+//	records, err := dump.Extract("users", extractor.Options{Columns: []string{"email", "pass"}})
+//
+// Dumps are never loaded into memory: every call reads through the dump, one statement at a
+// time, and stops once it has what it needs. Extract returns all rows at once. For large tables,
+// Iterate yields the rows one at a time as they are read, so memory stays bounded by the size of
+// the longest statement whatever the size of the dump:
+//
+//	it, err := dump.Iterate("users", extractor.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for {
+//		record, err := it.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+//
+// ForEach does the same with a callback:
+//
+//	err := dump.ForEach("users", extractor.Options{}, func(record extractor.Record) error {
+//		fmt.Println(record.Values())
+//		return nil
+//	})
+package extractor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Dump is a SQL dump, read from its source whenever the dump is queried.
+type Dump struct {
+	// Dialect determines how the dump is parsed. New dumps use MySQL.
+	Dialect Dialect
+
+	open func() (io.ReadCloser, error)
+}
+
+// Options controls which data Extract returns.
+type Options struct {
+	// Columns restricts the output to the named columns, in the order given. When empty, all
+	// columns are included in the order of the table.
+	Columns []string
+	// OnStatement, if set, is called for every data statement as the iterator starts reading it.
+	OnStatement func(StatementInfo)
+	// OnMalformed, if set, is called for every row skipped for not having as many values as the
+	// table has columns, and for the rest of a data statement that can't be read as rows.
+	OnMalformed func(MalformedRow)
+	// Strict ends the iteration at the first malformed row, which Next returns as a
+	// *ParseError, rather than skipping it.
+	Strict bool
+	// Workers, if above 1, is the number of goroutines parsing the data statements of the table
+	// read by Iterate, while another one reads the dump ahead of them. Rows are still returned
+	// in the order of the dump, and callbacks called from the goroutine calling Next, though
+	// the methods of the dialect are then called from several goroutines at once.
+	// IterateTables reads on a single goroutine.
+	Workers int
+	// Unordered lets a worker pool return the rows of the statements it parsed in the order
+	// they are parsed in, rather than that of the dump, so that a long statement doesn't hold
+	// back the others. The rows of a statement stay in order.
+	Unordered bool
+}
+
+// StatementInfo describes a data statement read by an Iterator.
+type StatementInfo struct {
+	Table string
+	// Offset and Length locate the statement in the dump in bytes.
+	Offset int
+	Length int
+	// Rows is the number of row tuples found in the statement.
+	Rows int
+}
+
+// MalformedRow describes a row the iterator skipped.
+type MalformedRow struct {
+	Table string `json:"table"`
+	// Line is the line of the dump the row starts on, counting from 1, and Offset its position in bytes.
+	Line   int    `json:"line"`
+	Offset int    `json:"offset"`
+	Reason string `json:"reason"`
+	// Snippet is the start of the row's raw text.
+	Snippet string `json:"snippet"`
+}
+
+// Length of MalformedRow.Snippet, longer rows are cut.
+const snippetLength = 200
+
+// Column describes a table column as declared in its CREATE TABLE statement.
+type Column struct {
+	Name string `json:"name"`
+	// Type is the declared SQL type, including its length or precision, e.g. "varchar(255)".
+	Type string `json:"type"`
+}
+
+// ColumnIndex returns the position of the named column among columns, or -1 if none has the name.
+func ColumnIndex(columns []Column, name string) int {
+	for i, column := range columns {
+		if column.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Field is a single column value of a record.
+type Field struct {
+	Name  string
+	Value string
+	// Null is set for SQL NULL values, whose Value is the text NULL as it appears in dumps.
+	Null bool
+}
+
+// Record is one row of a table, holding its fields in table column order.
+type Record []Field
+
+// Values returns the field values of the record in order.
+func (r Record) Values() []string {
+	values := make([]string, len(r))
+	for i, field := range r {
+		values[i] = field.Value
+	}
+	return values
+}
+
+// Hash identifies the record by a 128 bit hash of its values, so that records can be told apart
+// in little memory whatever the length of their values. Each value is hashed with its length, so
+// that no two different records hash alike but by collision, and NULL apart from any text.
+func (r Record) Hash() [16]byte {
+	h := fnv.New128a()
+	var prefix []byte
+	for _, field := range r {
+		if field.Null {
+			h.Write([]byte{0})
+			continue
+		}
+		prefix = binary.AppendUvarint(append(prefix[:0], 1), uint64(len(field.Value)))
+		h.Write(prefix)
+		h.Write([]byte(field.Value))
+	}
+	var sum [16]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// Open returns the SQL dump at filename, failing if it cannot be opened. The file is read anew
+// by every call to the dump, and decompressed as it is read if it is compressed or an archive,
+// see Decompress.
+func Open(filename string) (*Dump, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+	return NewSource(Decompress(filename, func() (io.ReadCloser, error) { return os.Open(filename) })), nil
+}
+
+// ResolveWithin returns the file the relative path name refers to below dir, with the symbolic
+// links on the way resolved, failing if name or one of the links leads outside of dir. Servers
+// use it to open the dumps their clients name.
+func ResolveWithin(dir, name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("path %s is outside of the data directory", name)
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Join(root, name))
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("path %s is outside of the data directory", name)
+	}
+	return resolved, nil
+}
+
+// New creates a Dump from the raw content of a SQL dump.
+func New(content []byte) *Dump {
+	return NewSource(func() (io.ReadCloser, error) { return bytesReader{bytes.NewReader(content)}, nil })
+}
+
+// NewSource creates a Dump read from the readers open returns, which is called for every pass
+// over the dump and must return a reader positioned at its start. The reader is closed once the
+// pass ends.
+func NewSource(open func() (io.ReadCloser, error)) *Dump {
+	return &Dump{Dialect: MySQL, open: open}
+}
+
+// bytesReader adds a Close method to a bytes.Reader, keeping it seekable.
+type bytesReader struct {
+	*bytes.Reader
+}
+
+func (bytesReader) Close() error {
+	return nil
+}
+
+// pass is a reading of the dump from its start.
+type pass struct {
+	*scanner
+	closer io.Closer
+	closed bool
+}
+
+func (d *Dump) pass() (*pass, error) {
+	r, err := d.open()
+	if err != nil {
+		return nil, &ReadError{Err: err}
+	}
+	return &pass{scanner: newScanner(r, d.Dialect.Syntax()), closer: r}, nil
+}
+
+// Close closes the reader of the pass, once.
+func (p *pass) Close() error {
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	return p.closer.Close()
+}
+
+// next returns the next statement of the pass, io.EOF at the end of the dump or a *ReadError.
+func (p *pass) next() (statement, error) {
+	stmt, err := p.scanner.next()
+	if err != nil && err != io.EOF {
+		err = &ReadError{Err: err}
+	}
+	return stmt, err
+}
+
+// findCreate reads the pass up to the statement creating the table, returning a
+// *TableNotFoundError if the dump ends before it.
+func (p *pass) findCreate(dialect Dialect, tableName string) (statement, error) {
+	for {
+		stmt, err := p.next()
+		if err == io.EOF {
+			return statement{}, &TableNotFoundError{Table: tableName}
+		}
+		if err != nil {
+			return statement{}, err
+		}
+		if name, ok := dialect.CreatedTable(stmt.text); ok && name == tableName {
+			return stmt, nil
+		}
+	}
+}
+
+// Tables returns the names of all tables created in the dump, in order of appearance.
+func (d *Dump) Tables() ([]string, error) {
+	p, err := d.pass()
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+	var tables []string
+	for {
+		stmt, err := p.next()
+		if err == io.EOF {
+			return tables, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if name, ok := d.Dialect.CreatedTable(stmt.text); ok {
+			tables = append(tables, name)
+		}
+	}
+}
+
+// Columns returns the columns of a table as declared in its CREATE TABLE statement.
+func (d *Dump) Columns(tableName string) ([]Column, error) {
+	create, err := d.createStatement(tableName)
+	if err != nil {
+		return nil, err
+	}
+	return d.Dialect.Columns(create.text)
+}
+
+// createStatement reads the dump up to the statement creating the table.
+func (d *Dump) createStatement(tableName string) (statement, error) {
+	p, err := d.pass()
+	if err != nil {
+		return statement{}, err
+	}
+	defer p.Close()
+	return p.findCreate(d.Dialect, tableName)
+}
+
+// tableSection is the part of a dump belonging to a table, from its CREATE TABLE statement up to
+// the statement ending it.
+type tableSection struct {
+	create statement
+	// description holds the CREATE TABLE statement followed by the comments and statements of
+	// the section other than its data statements, which dialects may state row counts in.
+	description string
+	// size is the number of bytes of the CREATE TABLE statement and the table's data statements.
+	size int
+}
+
+// section reads the section of a table, skipping over its data.
+func (d *Dump) section(tableName string) (*tableSection, error) {
+	p, err := d.pass()
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+	create, err := p.findCreate(d.Dialect, tableName)
+	if err != nil {
+		return nil, err
+	}
+	section := &tableSection{create: create, size: create.end - create.offset}
+	var description strings.Builder
+	description.WriteString(create.text)
+	for {
+		stmt, err := p.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if d.Dialect.EndsTable(stmt.text) {
+			break
+		}
+		description.WriteString(stmt.comments)
+		if table, isData := d.Dialect.InsertedTable(stmt.text); !isData {
+			description.WriteString(stmt.text + ";")
+		} else if table == tableName {
+			section.size += stmt.end - stmt.offset
+		}
+	}
+	section.description = description.String()
+	return section, nil
+}
+
+// Extract returns every row inserted into the given table.
+func (d *Dump) Extract(tableName string, opts Options) ([]Record, error) {
+	it, err := d.Iterate(tableName, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var records []Record
+	for {
+		record, err := it.Next()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// ForEach calls fn with every row inserted into the given table, as the rows are read. It stops
+// at the first error fn returns, which it returns.
+func (d *Dump) ForEach(tableName string, opts Options, fn func(Record) error) error {
+	it, err := d.Iterate(tableName, opts)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for {
+		record, err := it.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+// Iterate returns an Iterator over the rows inserted into the given table. The dump is read up
+// to the table's CREATE TABLE statement, the rest as rows are requested.
+func (d *Dump) Iterate(tableName string, opts Options) (*Iterator, error) {
+	p, err := d.pass()
+	if err != nil {
+		return nil, err
+	}
+	create, err := p.findCreate(d.Dialect, tableName)
+	if err != nil {
+		p.Close()
+		return nil, err
+	}
+	columns, err := d.Dialect.Columns(create.text)
+	if err != nil {
+		p.Close()
+		return nil, err
+	}
+
+	it := newIterator(d.Dialect, tableName, columns, opts)
+	it.describe(create.text)
+	it.pass = p
+	if opts.Workers > 1 {
+		it.startWorkers(opts.Workers, opts.Unordered)
+	}
+	return it, nil
+}
+
+func newIterator(dialect Dialect, tableName string, columns []Column, opts Options) *Iterator {
+	it := &Iterator{
+		dialect:     dialect,
+		tableName:   tableName,
+		onStatement: opts.OnStatement,
+		onMalformed: opts.OnMalformed,
+		strict:      opts.Strict,
+		columns:     columns,
+	}
+	if len(opts.Columns) == 0 {
+		for i := range columns {
+			it.selected = append(it.selected, i)
+		}
+	}
+	// Columns are output in the order they are asked for, those the table lacks left out.
+	for _, name := range opts.Columns {
+		for i, col := range columns {
+			if col.Name == name && !slices.Contains(it.selected, i) {
+				it.selected = append(it.selected, i)
+				break
+			}
+		}
+	}
+	return it
+}
+
+// Iterator yields the rows of a table one at a time. Statements are only read from the dump
+// and parsed as the rows they contain are requested.
+type Iterator struct {
+	dialect     Dialect
+	tableName   string
+	onStatement func(StatementInfo)
+	onMalformed func(MalformedRow)
+	strict      bool
+	pass        *pass
+	columns     []Column
+	// selected holds the position among columns of each field of the records, in order.
+	selected []int
+	pending  []string
+	// unread is the rest of the statement being read that the dialect couldn't read as rows,
+	// skipped once pending is.
+	unread string
+	// positions holds the column of each value of the rows of the statement being read, nil if
+	// they follow the columns of the table, and positionsErr why its column list doesn't fit
+	// the table.
+	positions    []int
+	positionsErr error
+	rows         int
+	malformed    int
+	err          error
+	// parseErr is the malformed row a strict iterator stopped at.
+	parseErr error
+	// description holds the section of the table read so far without its data, as
+	// Describer.DeclaredRows takes it, if the dialect is a Describer.
+	description *strings.Builder
+	// workers parses the statements of the table if Options.Workers is set, and parsed holds
+	// the records of the statement parsed last.
+	workers *workerPool
+	parsed  []Record
+
+	// Only used with onMalformed or strict: the statement being read, where its next malformed
+	// tuple is searched from, and the line reached by lineOffset.
+	statement   statement
+	tupleCursor int
+	line        int
+	lineOffset  int
+}
+
+// Columns returns the columns present in the records yielded by Next, in order: that of
+// Options.Columns if given, of the table otherwise.
+func (it *Iterator) Columns() []Column {
+	selected := make([]Column, len(it.selected))
+	for i, position := range it.selected {
+		selected[i] = it.columns[position]
+	}
+	return selected
+}
+
+// Table returns the name of the table the iterator reads.
+func (it *Iterator) Table() string {
+	return it.tableName
+}
+
+// Rows returns the number of records returned by Next so far.
+func (it *Iterator) Rows() int {
+	return it.rows
+}
+
+// Malformed returns how many of the rows read so far were skipped for having a different number
+// of values than the table has columns, or than their statement lists, for being inserted by a
+// statement whose column list doesn't fit the table, or for being the rest of a statement that
+// can't be read as rows.
+func (it *Iterator) Malformed() int {
+	return it.malformed
+}
+
+// Next returns the next record of the table, or io.EOF once all rows have been read. Malformed
+// rows are skipped. The dump is closed once its end or an error is reached.
+func (it *Iterator) Next() (Record, error) {
+	if it.workers != nil {
+		return it.nextParsed()
+	}
+	for {
+		for len(it.pending) == 0 && it.unread == "" {
+			if it.err != nil {
+				return nil, it.err
+			}
+			stmt, err := it.pass.next()
+			if err == nil && it.dialect.EndsTable(stmt.text) {
+				err = io.EOF
+			}
+			if err != nil {
+				it.err = err
+				it.Close()
+				continue
+			}
+			if table, ok := it.dialect.InsertedTable(stmt.text); !ok || table != it.tableName {
+				it.describeStatement(stmt, ok)
+				continue
+			}
+			it.describeStatement(stmt, true)
+			it.load(stmt)
+		}
+		if record, ok := it.nextPending(); ok {
+			return record, nil
+		}
+		if it.parseErr != nil {
+			it.pending, it.unread, it.err = nil, "", it.parseErr
+			it.Close()
+		}
+	}
+}
+
+// describe starts the description of the table with its CREATE TABLE statement, if the dialect
+// states row counts for DeclaredRows to read.
+func (it *Iterator) describe(create string) {
+	if _, ok := it.dialect.(Describer); ok {
+		it.description = &strings.Builder{}
+		it.description.WriteString(create)
+	}
+}
+
+// describeStatement adds a statement of the table's section to its description: its comments,
+// and the statement itself unless it holds data.
+func (it *Iterator) describeStatement(stmt statement, isData bool) {
+	if it.description == nil {
+		return
+	}
+	it.description.WriteString(stmt.comments)
+	if !isData {
+		it.description.WriteString(stmt.text + ";")
+	}
+}
+
+// DeclaredRows returns the number of rows the dump states the table has, like
+// Dump.DeclaredRows, from the part of the table's section read so far. Once Next returned
+// io.EOF, that is the whole section.
+func (it *Iterator) DeclaredRows() (RowCount, bool) {
+	if it.description == nil {
+		return RowCount{}, false
+	}
+	return it.dialect.(Describer).DeclaredRows(it.description.String())
+}
+
+// load splits a data statement of the table into the tuples Next returns rows from.
+func (it *Iterator) load(stmt statement) {
+	if reader, ok := it.dialect.(TupleReader); ok {
+		it.pending, it.unread = reader.ReadTuples(stmt.text)
+	} else {
+		it.pending, it.unread = it.dialect.Tuples(stmt.text), ""
+	}
+	it.positions, it.positionsErr = statementColumns(it.dialect, stmt.text, it.columns)
+	if it.onStatement != nil {
+		it.onStatement(StatementInfo{
+			Table:  it.tableName,
+			Offset: stmt.offset,
+			Length: stmt.end - stmt.offset,
+			Rows:   len(it.pending),
+		})
+	}
+	it.statement, it.tupleCursor = stmt, 0
+	it.line, it.lineOffset = stmt.line, 0
+}
+
+// nextPending returns the record of the next well-formed pending tuple, or false once there
+// are none left or a strict iterator skipped one.
+func (it *Iterator) nextPending() (Record, bool) {
+	for len(it.pending) > 0 && it.parseErr == nil {
+		match := it.pending[0]
+		it.pending = it.pending[1:]
+		if record, ok := it.processSingleMatch(match); ok {
+			it.rows++
+			return record, true
+		}
+	}
+	if it.unread != "" && it.parseErr == nil {
+		unread := it.unread
+		it.unread = ""
+		reason := "rows can't be read past this point"
+		if unread[0] == '(' {
+			reason = "row is not closed, the statement may be truncated"
+		}
+		it.skip(unread, reason)
+	}
+	return nil, false
+}
+
+// Close closes the dump. It only needs to be called when the iterator is given up on before
+// Next returns an error or io.EOF.
+func (it *Iterator) Close() error {
+	if it.err == nil {
+		it.err = io.EOF
+	}
+	if it.pass == nil {
+		return nil
+	}
+	if it.workers != nil {
+		it.workers.close()
+	}
+	return it.pass.Close()
+}
+
+// This function processes a single match and returns the record made of its cleaned values, or
+// false if the match is malformed.
+func (it *Iterator) processSingleMatch(match string) (Record, bool) {
+	values := it.dialect.Values(match)
+	width := len(it.columns)
+	if it.positions != nil {
+		width = len(it.positions)
+	}
+	if it.positionsErr != nil || len(values) != width {
+		reason := fmt.Sprintf("%d values for %d columns", len(values), width)
+		if it.positionsErr != nil {
+			reason = it.positionsErr.Error()
+		}
+		it.skip(match, reason)
+		return nil, false
+	}
+	if it.positions != nil {
+		// Columns the statement leaves out are NULL.
+		aligned := make([]string, len(it.columns))
+		for i := range aligned {
+			aligned[i] = "NULL"
+		}
+		for i, position := range it.positions {
+			aligned[position] = values[i]
+		}
+		values = aligned
+	}
+	var record Record
+	for _, position := range it.selected {
+		columnName, value := it.columns[position].Name, values[position]
+		if isNull(value) {
+			record = append(record, Field{Name: columnName, Value: "NULL", Null: true})
+			continue
+		}
+		cleanValue := it.dialect.Unescape(value)
+		record = append(record, Field{Name: columnName, Value: cleanValue})
+	}
+	return record, true
+}
+
+// statementColumns returns the position among columns of each value of the rows of a data
+// statement that lists its columns, or nil if it lists none or lists them all in order. An error
+// is returned if the list names a column the table lacks, or one twice.
+func statementColumns(dialect Dialect, statement string, columns []Column) ([]int, error) {
+	lister, ok := dialect.(ColumnLister)
+	if !ok {
+		return nil, nil
+	}
+	names := lister.InsertedColumns(statement)
+	if names == nil {
+		return nil, nil
+	}
+	positions := make([]int, len(names))
+	inOrder := len(names) == len(columns)
+	seen := make(map[int]bool, len(names))
+	for i, name := range names {
+		position := -1
+		for j, col := range columns {
+			if col.Name == name {
+				position = j
+				break
+			}
+		}
+		if position < 0 {
+			// MySQL column names are case-insensitive.
+			for j, col := range columns {
+				if strings.EqualFold(col.Name, name) {
+					position = j
+					break
+				}
+			}
+		}
+		switch {
+		case position < 0:
+			return nil, fmt.Errorf("column %s is not in the table", name)
+		case seen[position]:
+			return nil, fmt.Errorf("column %s is listed twice", name)
+		}
+		seen[position] = true
+		positions[i] = position
+		inOrder = inOrder && position == i
+	}
+	if inOrder {
+		return nil, nil
+	}
+	return positions, nil
+}
+
+// isNull reports whether a raw value is the NULL keyword, which is written unquoted.
+func isNull(value string) bool {
+	return strings.EqualFold(strings.TrimSpace(value), "NULL")
+}
+
+// skip counts a malformed row of the current statement, passing it to onMalformed. A strict
+// iterator stops at it.
+func (it *Iterator) skip(match string, reason string) {
+	it.malformed++
+	if it.onMalformed == nil && !it.strict {
+		return
+	}
+	row := it.malformedRow(match, reason)
+	if it.onMalformed != nil {
+		it.onMalformed(row)
+	}
+	if it.strict {
+		it.parseErr = &ParseError{Reason: fmt.Sprintf("line %d: malformed row of table %s: %s", row.Line, row.Table, row.Reason)}
+	}
+}
+
+// malformedRow describes a skipped row of the current statement.
+func (it *Iterator) malformedRow(match string, reason string) MalformedRow {
+	// Tuples come in order, so the search for each one starts where the last one found ended.
+	// Dialects may rewrite tuples, such as PostgreSQL's COPY rows, which are then located there.
+	position := it.tupleCursor
+	if i := strings.Index(it.statement.text[it.tupleCursor:], match); i >= 0 {
+		position += i
+		it.tupleCursor = position + len(match)
+	}
+	it.line += strings.Count(it.statement.text[it.lineOffset:position], "\n")
+	it.lineOffset = position
+	if len(match) > snippetLength {
+		match = match[:snippetLength] + "..."
+	}
+	return MalformedRow{
+		Table:   it.tableName,
+		Line:    it.line,
+		Offset:  it.statement.offset + position,
+		Reason:  reason,
+		Snippet: match,
+	}
+}
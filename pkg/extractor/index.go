@@ -0,0 +1,116 @@
+package extractor
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// Index summarises the tables of a dump, so they can be listed and described without parsing
+// the dump again. Indexes are stored as JSON next to the dump, see IndexPath.
+type Index struct {
+	Dialect string `json:"dialect"`
+	// Size and ModTime record the state of the dump file the index was built from, so stale
+	// indexes can be recognised.
+	Size    int64        `json:"size"`
+	ModTime time.Time    `json:"modTime"`
+	Tables  []TableIndex `json:"tables"`
+}
+
+// TableIndex describes one table of an indexed dump.
+type TableIndex struct {
+	Name string `json:"name"`
+	// Offset and Length locate the table in the dump in bytes, from its CREATE TABLE statement
+	// to its last data statement.
+	Offset  int      `json:"offset"`
+	Length  int      `json:"length"`
+	Rows    int      `json:"rows"`
+	Columns []Column `json:"columns"`
+}
+
+// IndexPath returns where the index of the dump at filename is stored.
+func IndexPath(filename string) string {
+	return filename + ".idx"
+}
+
+// BuildIndex reads the dump once, recording the location, columns and row count of every table.
+// Rows are counted like Iterate counts them, leaving out malformed rows.
+func (d *Dump) BuildIndex() (*Index, error) {
+	idx := &Index{Dialect: d.Dialect.Name(), Tables: []TableIndex{}}
+	p, err := d.pass()
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+
+	// Only the first table created under a name is indexed, the one Iterate reads. open holds
+	// the tables whose part of the dump is being read, by name, as their index in idx.Tables.
+	indexed := make(map[string]bool)
+	open := make(map[string]int)
+	for {
+		stmt, err := p.next()
+		if err == io.EOF {
+			return idx, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(open) > 0 && d.Dialect.EndsTable(stmt.text) {
+			clear(open)
+		}
+		if name, ok := d.Dialect.CreatedTable(stmt.text); ok {
+			if indexed[name] {
+				continue
+			}
+			columns, err := d.Dialect.Columns(stmt.text)
+			if err != nil {
+				return nil, err
+			}
+			indexed[name] = true
+			idx.Tables = append(idx.Tables, TableIndex{Name: name, Offset: stmt.offset, Length: stmt.end - stmt.offset, Columns: columns})
+			open[name] = len(idx.Tables) - 1
+			continue
+		}
+		name, ok := d.Dialect.InsertedTable(stmt.text)
+		if !ok {
+			continue
+		}
+		if i, ok := open[name]; ok {
+			table := &idx.Tables[i]
+			table.Length = stmt.end - table.Offset
+			positions, err := statementColumns(d.Dialect, stmt.text, table.Columns)
+			width := len(table.Columns)
+			if positions != nil {
+				width = len(positions)
+			}
+			for _, tuple := range d.Dialect.Tuples(stmt.text) {
+				if err == nil && len(d.Dialect.Values(tuple)) == width {
+					table.Rows++
+				}
+			}
+		}
+	}
+}
+
+// Write stores the index as JSON at path.
+func (idx *Index) Write(path string) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ReadIndex loads an index written by Index.Write.
+func ReadIndex(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	idx := &Index{}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
@@ -0,0 +1,45 @@
+package extractor
+
+// ForeignKey is a constraint whose columns reference the key of another table.
+type ForeignKey struct {
+	Columns    []string
+	Table      string
+	References []string
+}
+
+// KeyReader is implemented by dialects that can read the keys tables declare.
+type KeyReader interface {
+	// PrimaryKey returns the columns of the primary key declared by a CREATE TABLE statement, or
+	// nil if it declares none.
+	PrimaryKey(createStatement string) []string
+	// ForeignKeys returns the foreign keys declared by a CREATE TABLE statement.
+	ForeignKeys(createStatement string) []ForeignKey
+}
+
+// PrimaryKey returns the columns of the table's primary key, or nil if the table has none or the
+// dialect can't read it.
+func (d *Dump) PrimaryKey(tableName string) ([]string, error) {
+	create, err := d.createStatement(tableName)
+	if err != nil {
+		return nil, err
+	}
+	reader, ok := d.Dialect.(KeyReader)
+	if !ok {
+		return nil, nil
+	}
+	return reader.PrimaryKey(create.text), nil
+}
+
+// ForeignKeys returns the foreign keys of the table, or nil if it has none or the dialect can't
+// read them.
+func (d *Dump) ForeignKeys(tableName string) ([]ForeignKey, error) {
+	create, err := d.createStatement(tableName)
+	if err != nil {
+		return nil, err
+	}
+	reader, ok := d.Dialect.(KeyReader)
+	if !ok {
+		return nil, nil
+	}
+	return reader.ForeignKeys(create.text), nil
+}
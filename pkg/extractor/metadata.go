@@ -0,0 +1,92 @@
+package extractor
+
+import (
+	"io"
+)
+
+// RowCount is the number of rows a dump says a table has.
+type RowCount struct {
+	Rows int
+	// Approximate counts are estimates of the server the dump was taken from, such as InnoDB's.
+	Approximate bool
+}
+
+// Length of the start and end of a dump passed to Describer.Completed.
+const dumpEndLength = 1024
+
+// Describer is implemented by dialects whose dumps describe their content in comments. Dumps
+// check what they extract against it, which catches both truncated dumps and parser bugs.
+type Describer interface {
+	// DeclaredRows returns the row count a table's section states, if any. The section is passed
+	// without its data: the CREATE TABLE statement followed by the comments and other
+	// statements up to the table's end.
+	DeclaredRows(tableSection string) (RowCount, bool)
+	// Completed reports whether a dump, of which head is the first and tail the last kilobyte,
+	// ends the way the tool that wrote it ends finished dumps. known is false if the dump does
+	// not come from a tool marking its end.
+	Completed(head, tail string) (completed, known bool)
+}
+
+// DeclaredRows returns the number of rows the dump states the table has. ok is false if the
+// dialect or the dump doesn't record it.
+func (d *Dump) DeclaredRows(tableName string) (count RowCount, ok bool) {
+	describer, isDescriber := d.Dialect.(Describer)
+	if !isDescriber {
+		return RowCount{}, false
+	}
+	section, err := d.section(tableName)
+	if err != nil {
+		return RowCount{}, false
+	}
+	return describer.DeclaredRows(section.description)
+}
+
+// Truncated reports whether the dump lacks the end its tool writes once the dump is complete,
+// as happens when a dump is interrupted or only partially copied. Only dumps whose source can
+// seek, such as files, are checked, others are never reported truncated.
+func (d *Dump) Truncated() bool {
+	describer, ok := d.Dialect.(Describer)
+	if !ok {
+		return false
+	}
+	r, err := d.open()
+	if err != nil {
+		return false
+	}
+	defer r.Close()
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return false
+	}
+
+	head := make([]byte, dumpEndLength)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return false
+	}
+	head = head[:n]
+	tail := head
+	if size, err := seeker.Seek(0, io.SeekEnd); err == nil && size > int64(len(head)) {
+		if _, err := seeker.Seek(-min(size, dumpEndLength), io.SeekEnd); err != nil {
+			return false
+		}
+		tail = make([]byte, dumpEndLength)
+		n, err := io.ReadFull(r, tail)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return false
+		}
+		tail = tail[:n]
+	}
+	completed, known := describer.Completed(string(head), string(tail))
+	return known && !completed
+}
+
+// TableSize returns the size in bytes of the statements of the dump holding the table: its
+// CREATE TABLE statement and its data.
+func (d *Dump) TableSize(tableName string) (int, error) {
+	section, err := d.section(tableName)
+	if err != nil {
+		return 0, err
+	}
+	return section.size, nil
+}
@@ -0,0 +1,294 @@
+package extractor
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	RegisterDialect(MySQL)
+}
+
+// MySQL is the dialect of dumps written by mysqldump and compatible tools.
+var MySQL Dialect = mysqlDialect{}
+
+var (
+	// Hand-written dumps may leave table names unquoted or qualify them by their database.
+	createTableRegex = regexp.MustCompile("(?i)^CREATE\\s+TABLE\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?(?:(?:`[^`]+`|\\w+)\\.)?(?:`([^`]+)`|(\\w+))")
+	endTableRegex    = regexp.MustCompile(`(?i)^(?:DROP TABLE|UNLOCK TABLES)\b`)
+	insertRegex      = regexp.MustCompile("(?i)^(?:INSERT|REPLACE)\\s+(?:(?:LOW_PRIORITY|DELAYED|HIGH_PRIORITY|IGNORE)\\s+)*INTO\\s+(?:(?:`[^`]+`|\\w+)\\.)?(?:`([^`]+)`|(\\w+))")
+	valuesRegex      = regexp.MustCompile(`(?i)^\s*VALUES?\b`)
+	// The type of a column includes its length and the modifiers following it, as in
+	// "int(10) unsigned zerofill".
+	columnRegex        = regexp.MustCompile("`([a-zA-Z0-9_]+)`\\s+([a-zA-Z]+(?:\\([^)]*\\))?(?:\\s+(?i:unsigned|signed|zerofill)\\b)*)")
+	columnSectionRegex = regexp.MustCompile(`(?is)CREATE TABLE.*?\((.*?)(?:,\s*(?:PRIMARY KEY|KEY|UNIQUE KEY|CONSTRAINT)|\)\s*(?:ENGINE|$))`)
+	// HeidiSQL states the row count of a table before its data, e.g.
+	// "-- Dumping data for table shop.users: ~4 rows (approximately)".
+	dumpingDataRegex = regexp.MustCompile(`(?m)^-- Dumping data for table [^\n]*?: (~?)(\d+) rows`)
+	dumpHeaderRegex  = regexp.MustCompile(`^(?:-- (?:MySQL|MariaDB) dump|/\*!999999)`)
+	// Strings may be preceded by the character set they are in, e.g. _binary'...' or _utf8mb4 '...'.
+	charsetIntroducerRegex = regexp.MustCompile(`^_[A-Za-z0-9]+\s*$`)
+	// Key columns may carry a prefix length, as in PRIMARY KEY (`name`(10)).
+	primaryKeyRegex = regexp.MustCompile("(?i)PRIMARY KEY\\s*\\(((?:[^()]|\\(\\d+\\))*)\\)")
+	keyPartRegex    = regexp.MustCompile("`([^`]+)`")
+	// The referenced table may be qualified by its database.
+	foreignKeyRegex = regexp.MustCompile("(?i)FOREIGN KEY\\s*\\(([^)]*)\\)\\s*REFERENCES\\s*(?:`[^`]+`\\.)?`([^`]+)`\\s*\\(([^)]*)\\)")
+)
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string {
+	return "mysql"
+}
+
+func (mysqlDialect) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (mysqlDialect) Syntax() Syntax {
+	return Syntax{BackslashEscapes: true}
+}
+
+// Detect recognises the header mysqldump and mariadb-dump start their dumps with.
+func (mysqlDialect) Detect(head string) bool {
+	return dumpHeaderRegex.MatchString(strings.TrimLeft(head, "\ufeff"))
+}
+
+func (mysqlDialect) CreatedTable(statement string) (string, bool) {
+	match := createTableRegex.FindStringSubmatch(statement)
+	if match == nil {
+		return "", false
+	}
+	return match[1] + match[2], true
+}
+
+// EndsTable recognises the statements mysqldump writes around a table's data: the DROP TABLE
+// before the next table's CREATE TABLE, and the UNLOCK TABLES after the data when tables are
+// locked.
+func (m mysqlDialect) EndsTable(statement string) bool {
+	_, creates := m.CreatedTable(statement)
+	return creates || endTableRegex.MatchString(statement)
+}
+
+func (mysqlDialect) Columns(createStatement string) ([]Column, error) {
+	// First, extract only the column definition portion from the CREATE TABLE block
+	// by stopping at the first line that doesn't start with a backtick, indicating the start of keys or other table-level definitions.
+	columnSectionMatch := columnSectionRegex.FindStringSubmatch(createStatement)
+	if len(columnSectionMatch) < 2 {
+		return nil, &ParseError{Reason: "unable to extract column definitions from table content"}
+	}
+	columnSection := columnSectionMatch[1]
+
+	// Then, within this column definition portion, match the column names and their types.
+	matches := columnRegex.FindAllStringSubmatch(columnSection, -1)
+
+	var columns []Column
+	for _, match := range matches {
+		columns = append(columns, Column{Name: match[1], Type: match[2]})
+	}
+
+	if len(columns) == 0 {
+		return nil, &ParseError{Reason: "no columns found in table section"}
+	}
+	return columns, nil
+}
+
+func (mysqlDialect) DeclaredRows(tableSection string) (RowCount, bool) {
+	match := dumpingDataRegex.FindStringSubmatch(tableSection)
+	if match == nil {
+		return RowCount{}, false
+	}
+	rows, err := strconv.Atoi(match[2])
+	if err != nil {
+		return RowCount{}, false
+	}
+	return RowCount{Rows: rows, Approximate: match[1] == "~"}, true
+}
+
+// Completed looks for the "-- Dump completed" comment mysqldump and mariadb-dump end their dumps
+// with, unless they were run with --skip-comments.
+func (mysqlDialect) Completed(head, tail string) (completed, known bool) {
+	if !dumpHeaderRegex.MatchString(strings.TrimLeft(head, "\ufeff")) {
+		return false, false
+	}
+	return strings.Contains(tail, "-- Dump completed"), true
+}
+
+func (mysqlDialect) PrimaryKey(createStatement string) []string {
+	match := primaryKeyRegex.FindStringSubmatch(createStatement)
+	if match == nil {
+		return nil
+	}
+	return keyColumns(match[1])
+}
+
+func (mysqlDialect) ForeignKeys(createStatement string) []ForeignKey {
+	var keys []ForeignKey
+	for _, match := range foreignKeyRegex.FindAllStringSubmatch(createStatement, -1) {
+		keys = append(keys, ForeignKey{Columns: keyColumns(match[1]), Table: match[2], References: keyColumns(match[3])})
+	}
+	return keys
+}
+
+// keyColumns returns the quoted column names of a key's column list.
+func keyColumns(list string) []string {
+	var columns []string
+	for _, part := range keyPartRegex.FindAllStringSubmatch(list, -1) {
+		columns = append(columns, part[1])
+	}
+	return columns
+}
+
+func (mysqlDialect) InsertedTable(statement string) (string, bool) {
+	match := insertRegex.FindStringSubmatch(statement)
+	if match == nil {
+		return "", false
+	}
+	return match[1] + match[2], true
+}
+
+// InsertedColumns returns the column list of an INSERT or REPLACE statement, without the
+// backticks quoting the names.
+func (mysqlDialect) InsertedColumns(statement string) []string {
+	loc := insertRegex.FindStringIndex(statement)
+	if loc == nil {
+		return nil
+	}
+	names := columnList(statement[loc[1]:], mysqlSkip)
+	for i, name := range names {
+		if len(name) >= 2 && name[0] == '`' && name[len(name)-1] == '`' {
+			names[i] = strings.ReplaceAll(name[1:len(name)-1], "``", "`")
+		}
+	}
+	return names
+}
+
+// Tuples returns the rows following VALUES in an INSERT or REPLACE statement, walking them byte
+// by byte so that commas, parentheses and quotes within strings don't split them.
+func (d mysqlDialect) Tuples(statement string) []string {
+	tuples, _ := d.ReadTuples(statement)
+	return tuples
+}
+
+// ReadTuples returns the rows of Tuples, and the text after them unless it is an ON DUPLICATE
+// KEY UPDATE clause or the row alias preceding one.
+func (mysqlDialect) ReadTuples(statement string) ([]string, string) {
+	loc := insertRegex.FindStringIndex(statement)
+	if loc == nil {
+		return nil, ""
+	}
+	rest := skipColumnList(statement[loc[1]:], mysqlSkip)
+	values := valuesRegex.FindStringIndex(rest)
+	if values == nil {
+		return nil, ""
+	}
+	tuples, unread := splitTuples(rest[values[1]:], mysqlSkip)
+	return tuples, unreadRows(unread, "ON", "AS")
+}
+
+// Values splits a row at the commas outside of strings, quoted identifiers and parentheses.
+func (mysqlDialect) Values(tuple string) []string {
+	return splitList(tuple, mysqlSkip)
+}
+
+// Unescape strips the quotes of a string literal and resolves MySQL backslash escapes as well
+// as doubled quotes. The character set introducer of strings such as _binary'...' is dropped
+// and hexadecimal literals written X'...' are returned in the 0x... notation of mysqldump's
+// --hex-blob. Other values, such as numbers, 0x... and b'...' literals, are returned as they are.
+func (mysqlDialect) Unescape(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "_") {
+		if i := strings.IndexAny(value, `'"`); i > 0 && charsetIntroducerRegex.MatchString(value[:i]) {
+			value = value[i:]
+		}
+	}
+	if len(value) >= 3 && value[1] == '\'' && value[len(value)-1] == '\'' {
+		switch value[0] {
+		case 'X', 'x':
+			return "0x" + value[2:len(value)-1]
+		case 'B', 'b':
+			return value
+		}
+	}
+	if len(value) < 2 || value[0] != value[len(value)-1] || value[0] != '\'' && value[0] != '"' {
+		return strings.Trim(value, "'")
+	}
+	quote := value[0]
+	value = value[1 : len(value)-1]
+	if !strings.ContainsAny(value, `\'"`) {
+		return value
+	}
+
+	var unescaped strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == quote && i+1 < len(value) && value[i+1] == quote {
+			unescaped.WriteByte(quote)
+			i++
+			continue
+		}
+		if c != '\\' || i+1 == len(value) {
+			unescaped.WriteByte(c)
+			continue
+		}
+		i++
+		switch value[i] {
+		case '0':
+			unescaped.WriteByte(0)
+		case 'b':
+			unescaped.WriteByte('\b')
+		case 'n':
+			unescaped.WriteByte('\n')
+		case 'r':
+			unescaped.WriteByte('\r')
+		case 't':
+			unescaped.WriteByte('\t')
+		case 'Z':
+			unescaped.WriteByte(0x1a)
+		case '%', '_':
+			// MySQL keeps the backslash for these so they stay usable in LIKE patterns.
+			unescaped.WriteByte('\\')
+			unescaped.WriteByte(value[i])
+		default:
+			unescaped.WriteByte(value[i])
+		}
+	}
+	return unescaped.String()
+}
+
+// mysqlSkip returns the position following the string, quoted identifier or parenthesized
+// expression starting at i, or a position past the end of s if it isn't closed. Strings are
+// quoted with ' or " and escape with a backslash or a doubled quote, identifiers are quoted with
+// backticks.
+func mysqlSkip(s string, i int) int {
+	switch open := s[i]; open {
+	case '\'', '"', '`':
+		for j := i + 1; j < len(s); j++ {
+			switch {
+			case s[j] == '\\' && open != '`':
+				j++
+			case s[j] == open:
+				if j+1 < len(s) && s[j+1] == open {
+					j++
+					continue
+				}
+				return j + 1
+			}
+		}
+		return len(s) + 1
+	case '(':
+		for j := i + 1; j < len(s); {
+			switch s[j] {
+			case ')':
+				return j + 1
+			case '\'', '"', '`', '(':
+				j = mysqlSkip(s, j)
+			default:
+				j++
+			}
+		}
+		return len(s) + 1
+	}
+	return i + 1
+}
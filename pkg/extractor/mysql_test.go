@@ -0,0 +1,111 @@
+package extractor
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMysqlSkip(t *testing.T) {
+	tests := []struct {
+		s    string
+		i    int
+		want int
+	}{
+		{"'abc', 1", 0, 5},
+		{`'a\'b', 1`, 0, 6},
+		{`'a\\', 1`, 0, 5},
+		{"'it''s', 1", 0, 7},
+		{`"a'b", 1`, 0, 5},
+		{"`a\\`, 1", 0, 4},
+		{"`a``b`, 1", 0, 6},
+		{"(1, ')', (2)), 3", 0, 13},
+		{"1, 2", 0, 1},
+		{"x, 'abc'", 3, 8},
+		{"'abc", 0, 5},
+		{"(1, (2)", 0, 8},
+	}
+	for _, test := range tests {
+		if got := mysqlSkip(test.s, test.i); got != test.want {
+			t.Errorf("mysqlSkip(%q, %d) = %d, want %d", test.s, test.i, got, test.want)
+		}
+	}
+}
+
+func TestMySQLUnescape(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"'abc'", "abc"},
+		{" 'abc' ", "abc"},
+		{`"abc"`, "abc"},
+		{"123", "123"},
+		{"NULL", "NULL"},
+		{"'it''s'", "it's"},
+		{`"say ""hi"""`, `say "hi"`},
+		{`'it\'s'`, "it's"},
+		{`'a\\b'`, `a\b`},
+		{`'\0\b\n\r\t\Z'`, "\x00\b\n\r\t\x1a"},
+		{`'100\%\_'`, `100\%\_`},
+		{`'\q'`, "q"},
+		{`'a\'`, `a\`},
+		{"_utf8mb4'abc'", "abc"},
+		{"_binary'\\0'", "\x00"},
+		{"X'4142'", "0x4142"},
+		{"x'4142'", "0x4142"},
+		{"b'101'", "b'101'"},
+		{"0x4142", "0x4142"},
+		{"''", ""},
+	}
+	for _, test := range tests {
+		if got := MySQL.Unescape(test.value); got != test.want {
+			t.Errorf("Unescape(%q) = %q, want %q", test.value, got, test.want)
+		}
+	}
+}
+
+func TestMySQLReadTuples(t *testing.T) {
+	tests := []struct {
+		statement  string
+		wantTuples []string
+		wantRest   string
+	}{
+		{"INSERT INTO `t` VALUES (1,'a'),(2,'b')", []string{"1,'a'", "2,'b'"}, ""},
+		{"INSERT INTO `t` (`id`, `name`) VALUES (1,'a)(')", []string{"1,'a)('"}, ""},
+		{"REPLACE INTO `t` VALUES (1,'a\\'),(')", []string{"1,'a\\'),('"}, ""},
+		{"INSERT INTO `t` VALUES (1) ON DUPLICATE KEY UPDATE id=id", []string{"1"}, ""},
+		{"INSERT INTO `t` VALUES (1) garbage", []string{"1"}, "garbage"},
+		{"UPDATE `t` SET id=1", nil, ""},
+	}
+	for _, test := range tests {
+		tuples, rest := mysqlDialect{}.ReadTuples(test.statement)
+		if !slices.Equal(tuples, test.wantTuples) || rest != test.wantRest {
+			t.Errorf("ReadTuples(%q) = %q, %q, want %q, %q", test.statement, tuples, rest, test.wantTuples, test.wantRest)
+		}
+	}
+}
+
+func TestMySQLColumns(t *testing.T) {
+	tests := []struct {
+		definition string
+		want       string
+	}{
+		{"`id` int", "int"},
+		{"`id` int(10) NOT NULL", "int(10)"},
+		{"`id` int(10) unsigned NOT NULL", "int(10) unsigned"},
+		{"`id` smallint(5) UNSIGNED ZEROFILL DEFAULT NULL", "smallint(5) UNSIGNED ZEROFILL"},
+		{"`price` decimal(10,2) signed", "decimal(10,2) signed"},
+		{"`name` varchar(255) CHARACTER SET utf8mb4", "varchar(255)"},
+		{"`unsignedness` int", "int"},
+	}
+	for _, test := range tests {
+		columns, err := mysqlDialect{}.Columns("CREATE TABLE `t` (\n  " + test.definition + "\n) ENGINE=InnoDB;")
+		if err != nil {
+			t.Errorf("Columns(%q): %v", test.definition, err)
+			continue
+		}
+		if len(columns) != 1 || columns[0].Type != test.want {
+			t.Errorf("Columns(%q) = %+v, want one column of type %q", test.definition, columns, test.want)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package extractor
+
+import (
+	"regexp"
+	"strings"
+)
+
+func init() {
+	RegisterDialect(Postgres)
+}
+
+// Postgres is the dialect of plain-text dumps written by pg_dump, with their data in COPY blocks
+// or, with --inserts, in INSERT statements.
+//
+// Tables are named without their schema when it is public, the default, and as schema.table
+// otherwise. pg_dump writes the data of all tables after their definitions, so the part of the
+// dump belonging to a table runs up to the end of the dump.
+var Postgres Dialect = postgresDialect{}
+
+// Identifiers are either double quoted or left bare, and may be qualified by their schema.
+const (
+	pgIdentifier = `(?:"(?:[^"]|"")+"|[A-Za-z_][A-Za-z0-9_$]*)`
+	pgTableName  = `(` + pgIdentifier + `(?:\s*\.\s*` + pgIdentifier + `)?)`
+)
+
+var (
+	pgCreateTableRegex = regexp.MustCompile(`(?i)^CREATE\s+(?:(?:GLOBAL|LOCAL)\s+)?(?:(?:TEMP|TEMPORARY|UNLOGGED)\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?` + pgTableName)
+	pgInsertRegex      = regexp.MustCompile(`(?i)^INSERT\s+INTO\s+` + pgTableName)
+	pgCopyRegex        = regexp.MustCompile(`(?i)^COPY\s+` + pgTableName + `(?:\s*(\([^)]*\)))?\s+FROM\s+stdin\s*;`)
+	pgIdentifierRegex  = regexp.MustCompile(pgIdentifier)
+	pgValuesRegex      = regexp.MustCompile(`(?i)^\s*VALUES\b`)
+	// Table constraints, listed among the columns of a CREATE TABLE statement.
+	pgConstraintRegex = regexp.MustCompile(`(?i)^(?:CONSTRAINT|PRIMARY\s+KEY|UNIQUE|CHECK|FOREIGN\s+KEY|EXCLUDE|LIKE)\b`)
+	// The column constraints following the type of a column.
+	pgColumnConstraintRegex = regexp.MustCompile(`(?i)\s(?:NOT\s+NULL|NULL|DEFAULT|CONSTRAINT|PRIMARY\s+KEY|UNIQUE|CHECK|REFERENCES|GENERATED|COLLATE)\b`)
+	pgDumpHeaderRegex       = regexp.MustCompile(`(?m)^-- PostgreSQL database dump`)
+	pgDetectRegex           = regexp.MustCompile(`(?mi)^(?:-- PostgreSQL database dump|SET standard_conforming_strings|SELECT pg_catalog\.|COPY .* FROM stdin;)`)
+)
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (postgresDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (postgresDialect) Syntax() Syntax {
+	return Syntax{DollarQuotes: true, PsqlScript: true}
+}
+
+// Detect recognises the header of pg_dump, the settings it starts its dumps with, and COPY blocks.
+func (postgresDialect) Detect(head string) bool {
+	return pgDetectRegex.MatchString(head)
+}
+
+func (postgresDialect) CreatedTable(statement string) (string, bool) {
+	match := pgCreateTableRegex.FindStringSubmatch(statement)
+	if match == nil {
+		return "", false
+	}
+	return pgName(match[1]), true
+}
+
+func (postgresDialect) EndsTable(statement string) bool {
+	return false
+}
+
+func (postgresDialect) Columns(createStatement string) ([]Column, error) {
+	match := pgCreateTableRegex.FindStringIndex(createStatement)
+	if match == nil {
+		return nil, &ParseError{Reason: "unable to extract column definitions from table content"}
+	}
+	rest := createStatement[match[1]:]
+	start := strings.IndexByte(rest, '(')
+	if start < 0 || strings.TrimSpace(rest[:start]) != "" {
+		return nil, &ParseError{Reason: "unable to extract column definitions from table content"}
+	}
+	end := pgSkip(rest, start)
+	if end > len(rest) {
+		return nil, &ParseError{Reason: "unable to extract column definitions from table content"}
+	}
+
+	var columns []Column
+	for _, definition := range splitList(rest[start+1:end-1], pgSkip) {
+		definition = strings.TrimSpace(definition)
+		if definition == "" || pgConstraintRegex.MatchString(definition) {
+			continue
+		}
+		name := pgIdentifierRegex.FindString(definition)
+		if name == "" || !strings.HasPrefix(definition, name) {
+			continue
+		}
+		columnType := definition[len(name):]
+		if loc := pgColumnConstraintRegex.FindStringIndex(columnType); loc != nil {
+			columnType = columnType[:loc[0]]
+		}
+		columns = append(columns, Column{Name: pgUnquote(name), Type: strings.Join(strings.Fields(columnType), " ")})
+	}
+
+	if len(columns) == 0 {
+		return nil, &ParseError{Reason: "no columns found in table section"}
+	}
+	return columns, nil
+}
+
+// Completed looks for the comment pg_dump ends its dumps with.
+func (postgresDialect) Completed(head, tail string) (completed, known bool) {
+	if !pgDumpHeaderRegex.MatchString(head) {
+		return false, false
+	}
+	return strings.Contains(tail, "-- PostgreSQL database dump complete"), true
+}
+
+// DeclaredRows always returns false, pg_dump doesn't state row counts.
+func (postgresDialect) DeclaredRows(tableSection string) (RowCount, bool) {
+	return RowCount{}, false
+}
+
+func (postgresDialect) InsertedTable(statement string) (string, bool) {
+	match := pgInsertRegex.FindStringSubmatch(statement)
+	if match == nil {
+		match = pgCopyRegex.FindStringSubmatch(statement)
+	}
+	if match == nil {
+		return "", false
+	}
+	return pgName(match[1]), true
+}
+
+// InsertedColumns returns the column list of an INSERT or COPY statement. pg_dump lists the
+// columns of every COPY statement, leaving out generated columns.
+func (postgresDialect) InsertedColumns(statement string) []string {
+	var names []string
+	if match := pgCopyRegex.FindStringSubmatch(statement); match != nil {
+		names = columnList(match[2], pgSkip)
+	} else if loc := pgInsertRegex.FindStringIndex(statement); loc != nil {
+		names = columnList(statement[loc[1]:], pgSkip)
+	}
+	for i, name := range names {
+		names[i] = pgUnquote(name)
+	}
+	return names
+}
+
+// Tuples returns the rows of an INSERT statement or of the data following a COPY statement.
+// COPY rows are turned into the syntax of INSERT rows, so Values and Unescape take both apart
+// alike.
+func (d postgresDialect) Tuples(statement string) []string {
+	tuples, _ := d.ReadTuples(statement)
+	return tuples
+}
+
+// ReadTuples returns the rows of Tuples, and the text after the rows of an INSERT statement
+// unless it is an ON CONFLICT or RETURNING clause. The lines of COPY data are all rows, those
+// cut short having too few values.
+func (postgresDialect) ReadTuples(statement string) ([]string, string) {
+	if loc := pgCopyRegex.FindStringIndex(statement); loc != nil {
+		return pgCopyTuples(statement[loc[1]:]), ""
+	}
+	loc := pgInsertRegex.FindStringIndex(statement)
+	if loc == nil {
+		return nil, ""
+	}
+	rest := skipColumnList(statement[loc[1]:], pgSkip)
+	values := pgValuesRegex.FindStringIndex(rest)
+	if values == nil {
+		return nil, ""
+	}
+	tuples, unread := splitTuples(rest[values[1]:], pgSkip)
+	return tuples, unreadRows(unread, "ON", "RETURNING")
+}
+
+// pgCopyTuples converts the lines of COPY data, whose values are separated by tabs, into rows
+// of SQL literals: \N becomes NULL and other values escape strings, whose backslash escapes are
+// nearly the same as COPY's.
+func pgCopyTuples(data string) []string {
+	data = strings.TrimPrefix(strings.TrimPrefix(data, "\r"), "\n")
+	var tuples []string
+	for len(data) > 0 {
+		line, rest, _ := strings.Cut(data, "\n")
+		data = rest
+		line = strings.TrimSuffix(line, "\r")
+
+		var tuple strings.Builder
+		for i, value := range strings.Split(line, "\t") {
+			if i > 0 {
+				tuple.WriteByte(',')
+			}
+			if value == `\N` {
+				tuple.WriteString("NULL")
+				continue
+			}
+			if !strings.ContainsAny(value, `\'`) {
+				tuple.WriteString("'" + value + "'")
+				continue
+			}
+			tuple.WriteString("E'")
+			for j := 0; j < len(value); j++ {
+				switch c := value[j]; {
+				case c == '\'':
+					tuple.WriteString("''")
+				case c == '\\' && j+1 < len(value):
+					j++
+					// Escape strings have no \v.
+					if value[j] == 'v' {
+						tuple.WriteString(`\013`)
+					} else {
+						tuple.WriteByte('\\')
+						tuple.WriteByte(value[j])
+					}
+				default:
+					tuple.WriteByte(c)
+				}
+			}
+			tuple.WriteByte('\'')
+		}
+		tuples = append(tuples, tuple.String())
+	}
+	return tuples
+}
+
+// Values splits a row at the commas outside of strings, quoted identifiers and parentheses.
+func (postgresDialect) Values(tuple string) []string {
+	return splitList(tuple, pgSkip)
+}
+
+// Unescape strips the quotes of a string literal, resolving doubled quotes and, in escape
+// strings written E'...', backslash escapes. Other values are returned as they are.
+func (postgresDialect) Unescape(value string) string {
+	value = strings.TrimSpace(value)
+	// E only introduces an escape string when a quote follows it, unlike in "Emily".
+	escaped := len(value) > 1 && (value[0] == 'E' || value[0] == 'e') && value[1] == '\''
+	if escaped {
+		value = value[1:]
+	}
+	if len(value) < 2 || value[0] != '\'' || value[len(value)-1] != '\'' {
+		return value
+	}
+	value = strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	if !escaped || !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var unescaped strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '\\' || i+1 == len(value) {
+			unescaped.WriteByte(c)
+			continue
+		}
+		i++
+		switch c = value[i]; c {
+		case 'b':
+			unescaped.WriteByte('\b')
+		case 'f':
+			unescaped.WriteByte('\f')
+		case 'n':
+			unescaped.WriteByte('\n')
+		case 'r':
+			unescaped.WriteByte('\r')
+		case 't':
+			unescaped.WriteByte('\t')
+		case 'x':
+			// Up to two hexadecimal digits.
+			n, digits := 0, 0
+			for ; digits < 2 && i+1 < len(value) && isHexDigit(value[i+1]); digits++ {
+				i++
+				n = n*16 + hexValue(value[i])
+			}
+			if digits == 0 {
+				unescaped.WriteByte('x')
+			} else {
+				unescaped.WriteByte(byte(n))
+			}
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			// Up to three octal digits.
+			n := int(c - '0')
+			for digits := 1; digits < 3 && i+1 < len(value) && value[i+1] >= '0' && value[i+1] <= '7'; digits++ {
+				i++
+				n = n*8 + int(value[i]-'0')
+			}
+			unescaped.WriteByte(byte(n))
+		default:
+			unescaped.WriteByte(c)
+		}
+	}
+	return unescaped.String()
+}
+
+// pgName returns the name a table is known by, leaving out the public schema.
+func pgName(qualified string) string {
+	parts := pgIdentifierRegex.FindAllString(qualified, -1)
+	for i, part := range parts {
+		parts[i] = pgUnquote(part)
+	}
+	if len(parts) == 2 && parts[0] == "public" {
+		parts = parts[1:]
+	}
+	return strings.Join(parts, ".")
+}
+
+// pgUnquote returns the name an identifier stands for. Unquoted identifiers are case-insensitive,
+// which PostgreSQL implements by lowering them.
+func pgUnquote(identifier string) string {
+	if strings.HasPrefix(identifier, `"`) {
+		return strings.ReplaceAll(identifier[1:len(identifier)-1], `""`, `"`)
+	}
+	return strings.ToLower(identifier)
+}
+
+// pgSkip returns the position following the string, quoted identifier or bracketed expression
+// starting at i, or a position past the end of s if it isn't closed.
+func pgSkip(s string, i int) int {
+	switch open := s[i]; open {
+	case '\'', '"':
+		// Backslashes only escape in escape strings, E'...'.
+		backslash := open == '\'' && i > 0 && (s[i-1] == 'E' || s[i-1] == 'e')
+		for j := i + 1; j < len(s); j++ {
+			switch {
+			case s[j] == '\\' && backslash:
+				j++
+			case s[j] == open:
+				// A doubled quote is part of the string.
+				if j+1 < len(s) && s[j+1] == open {
+					j++
+					continue
+				}
+				return j + 1
+			}
+		}
+		return len(s) + 1
+	case '(', '[':
+		close := byte(')')
+		if open == '[' {
+			close = ']'
+		}
+		for j := i + 1; j < len(s); {
+			switch s[j] {
+			case close:
+				return j + 1
+			case '\'', '"', '(', '[':
+				j = pgSkip(s, j)
+			default:
+				j++
+			}
+		}
+		return len(s) + 1
+	}
+	return i + 1
+}
+
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func hexValue(c byte) int {
+	switch {
+	case c >= 'a':
+		return int(c-'a') + 10
+	case c >= 'A':
+		return int(c-'A') + 10
+	}
+	return int(c - '0')
+}
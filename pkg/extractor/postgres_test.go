@@ -0,0 +1,110 @@
+package extractor
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPgCopyTuples(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{"values", "\n1\tabc\n2\tdef\n", []string{"'1','abc'", "'2','def'"}},
+		{"null", "\n1\t\\N\n", []string{"'1',NULL"}},
+		{"empty string", "\n1\t\n", []string{"'1',''"}},
+		{"quote", "\n1\tit's\n", []string{"'1',E'it''s'"}},
+		{"escapes", "\n1\ta\\tb\\nc\\\\\n", []string{`'1',E'a\tb\nc\\'`}},
+		{"vertical tab", "\n1\t\\v\n", []string{`'1',E'\013'`}},
+		{"octal", "\n1\t\\101\n", []string{`'1',E'\101'`}},
+		{"CRLF", "\r\n1\tabc\r\n2\tdef\r\n", []string{"'1','abc'", "'2','def'"}},
+		{"last line without newline", "\n1\tabc", []string{"'1','abc'"}},
+		{"no rows", "\n", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := pgCopyTuples(test.data); !slices.Equal(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPostgresReadTuples(t *testing.T) {
+	tests := []struct {
+		statement  string
+		wantTuples []string
+		wantRest   string
+	}{
+		{"COPY public.users (id, name) FROM stdin;\n1\tann\n2\t\\N\n", []string{"'1','ann'", "'2',NULL"}, ""},
+		{"COPY \"Odd Name\" FROM stdin;\n1\n", []string{"'1'"}, ""},
+		{"INSERT INTO public.users VALUES (1, 'a'), (2, E'b\\'')", []string{"1, 'a'", "2, E'b\\''"}, ""},
+		{"INSERT INTO users (id, name) VALUES (1, 'a\\'), (2, 'b')", []string{"1, 'a\\'", "2, 'b'"}, ""},
+		{"INSERT INTO users VALUES (1, ARRAY[1,2]) ON CONFLICT DO NOTHING", []string{"1, ARRAY[1,2]"}, ""},
+		{"INSERT INTO users VALUES (1) RETURNING id", []string{"1"}, ""},
+		{"COPY users TO stdout", nil, ""},
+	}
+	for _, test := range tests {
+		tuples, rest := postgresDialect{}.ReadTuples(test.statement)
+		if !slices.Equal(tuples, test.wantTuples) || rest != test.wantRest {
+			t.Errorf("ReadTuples(%q) = %q, %q, want %q, %q", test.statement, tuples, rest, test.wantTuples, test.wantRest)
+		}
+	}
+}
+
+func TestPgSkip(t *testing.T) {
+	tests := []struct {
+		s    string
+		i    int
+		want int
+	}{
+		{"'abc', 1", 0, 5},
+		{`'a\', 1`, 0, 4},
+		{`E'a\'b', 1`, 1, 7},
+		{"'it''s', 1", 0, 7},
+		{`"a""b", 1`, 0, 6},
+		{"(1, ')', (2)), 3", 0, 13},
+		{"[1, ']'], 2", 0, 8},
+		{"1, 2", 0, 1},
+		{"'abc", 0, 5},
+		{"[1, 2", 0, 6},
+	}
+	for _, test := range tests {
+		if got := pgSkip(test.s, test.i); got != test.want {
+			t.Errorf("pgSkip(%q, %d) = %d, want %d", test.s, test.i, got, test.want)
+		}
+	}
+}
+
+func TestPostgresUnescape(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"'abc'", "abc"},
+		{" 'abc' ", "abc"},
+		{"123", "123"},
+		{"NULL", "NULL"},
+		{"'it''s'", "it's"},
+		{`'a\nb'`, `a\nb`},
+		{`E'a\nb'`, "a\nb"},
+		{`e'a\tb'`, "a\tb"},
+		{`E'\b\f\r\\'`, "\b\f\r\\"},
+		{`E'\x41\x4a\xg'`, "AJxg"},
+		{`E'\101\0'`, "A\x00"},
+		{`E'\q'`, "q"},
+		{`E'it''s'`, "it's"},
+		{"''", ""},
+		{"Emily", "Emily"},
+		{"e", "e"},
+		{"E", "E"},
+		{"'E'", "E"},
+		{"true", "true"},
+	}
+	for _, test := range tests {
+		if got := Postgres.Unescape(test.value); got != test.want {
+			t.Errorf("Unescape(%q) = %q, want %q", test.value, got, test.want)
+		}
+	}
+}
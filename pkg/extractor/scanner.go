@@ -0,0 +1,331 @@
+package extractor
+
+import (
+	"bytes"
+	"io"
+)
+
+// Size of the buffer dumps are read through. Statements longer than it are assembled across reads.
+const scanBufferSize = 1 << 20
+
+// statement is a SQL statement read from a dump by a scanner.
+type statement struct {
+	// text is the statement without the whitespace and comments before it and without its
+	// terminating semicolon.
+	text string
+	// comments holds the whitespace and comments read between the previous statement and this one.
+	comments string
+	// offset is the position of text in the dump in bytes, line the line it starts on, counting
+	// from 1, and end the position following its semicolon.
+	offset, line, end int
+}
+
+// States of a scanner.
+const (
+	scanBetween      = iota // in whitespace and comments between statements
+	scanBetweenLine         // in a line comment between statements
+	scanBetweenBlock        // in a block comment between statements
+	scanStatement
+	scanLine   // in a line comment within a statement
+	scanBlock  // in a block comment within a statement
+	scanQuoted // in a quoted string or identifier, closed by quote
+	scanDollar // in a dollar quoted string, closed by tag
+	scanCopy   // in the rows following a COPY statement
+)
+
+// Dollar quote tags longer than this are not recognised, as PostgreSQL limits identifiers to 63
+// bytes.
+const maxDollarTag = 64
+
+// scanner splits a dump into statements as it reads it, holding no more of the dump in memory
+// than the statement being read. Semicolons end statements unless they are quoted or part of a
+// comment. Quotes are escaped by doubling them, or with a backslash as the dialect's Syntax says.
+type scanner struct {
+	r      io.Reader
+	err    error
+	syntax Syntax
+
+	// buf holds the bytes read from r, of which buf[pos:n] are not scanned yet. base is the
+	// position of buf[0] in the dump.
+	buf    []byte
+	pos, n int
+	base   int
+	line   int
+
+	state int
+	quote byte
+	// backslash is set if backslashes escape in the string being read, and escaped after one.
+	backslash, escaped bool
+	// star is set after a '*' in a block comment, whose next '/' ends it.
+	star bool
+	// prev is the last byte read in a statement outside of quotes and comments.
+	prev byte
+	// tag is the delimiter of the dollar quoted string being read, e.g. "$body$".
+	tag []byte
+	// lineStart is set at the start of a line of COPY rows.
+	lineStart bool
+
+	// The statement being read: its text up to textStart, where the buffered part of its text
+	// begins, and the comments before it.
+	text      []byte
+	textStart int
+	comments  []byte
+	stmtStart int
+	stmtLine  int
+}
+
+func newScanner(r io.Reader, syntax Syntax) *scanner {
+	return &scanner{r: r, syntax: syntax, buf: make([]byte, scanBufferSize), line: 1}
+}
+
+// next returns the next statement of the dump, or the error that ended the reading, io.EOF once
+// the dump is read completely. A last statement lacking its semicolon, as in a truncated dump,
+// is returned as well.
+func (s *scanner) next() (statement, error) {
+	for {
+		if stmt, ok := s.scan(); ok {
+			return stmt, nil
+		}
+		if s.err != nil && s.pos == s.n {
+			if s.state >= scanStatement {
+				return s.emit(s.n, s.n), nil
+			}
+			return statement{}, s.err
+		}
+		s.fill()
+	}
+}
+
+// scan reads the buffered bytes until a statement ends, returning it, or until the buffer is
+// exhausted or more bytes are needed to tell what a byte starts.
+func (s *scanner) scan() (statement, bool) {
+	buf := s.buf[:s.n]
+	for i := s.pos; i < len(buf); i++ {
+		c := buf[i]
+		if c == '\n' {
+			s.line++
+		}
+		switch s.state {
+		case scanBetween:
+			switch c {
+			case ' ', '\t', '\n', '\r', ';':
+				// Semicolons ending empty statements, such as after MySQL's executable comments.
+				s.comments = append(s.comments, c)
+				continue
+			case '#':
+				s.state = scanBetweenLine
+				s.comments = append(s.comments, c)
+				continue
+			case '\\':
+				if s.syntax.PsqlScript {
+					s.state = scanBetweenLine
+					s.comments = append(s.comments, c)
+					continue
+				}
+			case '-', '/':
+				if i+1 == len(buf) && s.err == nil {
+					s.pos = i
+					return statement{}, false
+				}
+				if i+1 < len(buf) && (c == '-' && buf[i+1] == '-' || c == '/' && buf[i+1] == '*') {
+					if c == '-' {
+						s.state = scanBetweenLine
+					} else {
+						s.state, s.star = scanBetweenBlock, false
+					}
+					s.comments = append(s.comments, c, buf[i+1])
+					i++
+					continue
+				}
+			}
+			s.state, s.prev = scanStatement, 0
+			s.textStart, s.stmtStart, s.stmtLine = i, s.base+i, s.line
+			i--
+		case scanBetweenLine:
+			s.comments = append(s.comments, c)
+			if c == '\n' {
+				s.state = scanBetween
+			}
+		case scanBetweenBlock:
+			s.comments = append(s.comments, c)
+			if s.star && c == '/' {
+				s.state = scanBetween
+			}
+			s.star = c == '*'
+		case scanStatement:
+			switch c {
+			case ';':
+				if s.syntax.PsqlScript {
+					s.keepText(i)
+					if isCopyFromStdin(s.text) {
+						s.text = append(s.text, c)
+						s.textStart = i + 1
+						s.state, s.lineStart = scanCopy, false
+						continue
+					}
+				}
+				stmt := s.emit(i, i+1)
+				s.pos = i + 1
+				return stmt, true
+			case '\'', '"', '`':
+				s.state, s.quote, s.escaped = scanQuoted, c, false
+				// PostgreSQL's escape strings are written E'...'.
+				s.backslash = s.syntax.BackslashEscapes && c != '`' || c == '\'' && (s.prev == 'E' || s.prev == 'e')
+			case '$':
+				if !s.syntax.DollarQuotes || isTagByte(s.prev) {
+					break
+				}
+				j := i + 1
+				for j < len(buf) && j-i <= maxDollarTag && isTagByte(buf[j]) {
+					j++
+				}
+				if j == len(buf) && s.err == nil {
+					s.keepText(i)
+					return statement{}, false
+				}
+				// Tags can't start with a digit, unlike the $1 parameters of functions.
+				if j < len(buf) && buf[j] == '$' && (j == i+1 || buf[i+1] > '9') {
+					s.tag = append(s.tag[:0], buf[i:j+1]...)
+					s.state = scanDollar
+					i = j
+				}
+			case '-', '/':
+				if i+1 == len(buf) && s.err == nil {
+					s.keepText(i)
+					return statement{}, false
+				}
+				if i+1 < len(buf) && c == '-' && buf[i+1] == '-' {
+					s.state = scanLine
+					i++
+				} else if i+1 < len(buf) && c == '/' && buf[i+1] == '*' {
+					s.state, s.star = scanBlock, false
+					i++
+				}
+			}
+			if s.state == scanStatement {
+				s.prev = c
+			}
+		case scanLine:
+			if c == '\n' {
+				s.state = scanStatement
+			}
+		case scanBlock:
+			if s.star && c == '/' {
+				s.state = scanStatement
+			}
+			s.star = c == '*'
+		case scanQuoted:
+			switch {
+			case s.escaped:
+				s.escaped = false
+			case c == '\\' && s.backslash:
+				s.escaped = true
+			case c == s.quote:
+				s.state, s.prev = scanStatement, c
+			}
+		case scanDollar:
+			if c != '$' {
+				break
+			}
+			if i+len(s.tag) > len(buf) && s.err == nil {
+				s.keepText(i)
+				return statement{}, false
+			}
+			if bytes.HasPrefix(buf[i:], s.tag) {
+				s.state, s.prev = scanStatement, c
+				i += len(s.tag) - 1
+			}
+		case scanCopy:
+			if s.lineStart && c == '\\' {
+				// The rows end with a line holding "\.", which takes up to three bytes to tell.
+				if i+3 > len(buf) && s.err == nil {
+					s.keepText(i)
+					return statement{}, false
+				}
+				if next, ok := copyEnd(buf, i); ok {
+					if buf[next-1] == '\n' {
+						s.line++
+					}
+					stmt := s.emit(i, next)
+					s.pos = next
+					return stmt, true
+				}
+			}
+			s.lineStart = c == '\n'
+		}
+	}
+	if s.state >= scanStatement {
+		s.keepText(len(buf))
+	} else {
+		s.pos = len(buf)
+	}
+	return statement{}, false
+}
+
+// keepText stops the scan at i, moving the text of the statement read so far out of the buffer.
+func (s *scanner) keepText(i int) {
+	s.text = append(s.text, s.buf[s.textStart:i]...)
+	s.textStart = i
+	s.pos = i
+}
+
+// fill moves the bytes not scanned yet to the front of the buffer and reads more after them.
+func (s *scanner) fill() {
+	s.base += s.pos
+	s.textStart -= s.pos
+	s.n = copy(s.buf, s.buf[s.pos:s.n])
+	s.pos = 0
+	for s.err == nil && s.n < len(s.buf) {
+		var n int
+		n, s.err = s.r.Read(s.buf[s.n:])
+		s.n += n
+		if n > 0 {
+			return
+		}
+	}
+}
+
+// emit returns the statement whose text ends at end in the buffer, followed by its terminator
+// up to next, and starts looking for the next statement.
+func (s *scanner) emit(end, next int) statement {
+	s.text = append(s.text, s.buf[s.textStart:end]...)
+	stmt := statement{
+		text:     string(s.text),
+		comments: string(s.comments),
+		offset:   s.stmtStart,
+		line:     s.stmtLine,
+		end:      s.base + next,
+	}
+	s.text, s.comments = s.text[:0], s.comments[:0]
+	s.state = scanBetween
+	return stmt
+}
+
+// copyEnd reports whether buf holds the line ending COPY rows at i, returning where the next
+// statement starts.
+func copyEnd(buf []byte, i int) (int, bool) {
+	if i+1 == len(buf) || buf[i+1] != '.' {
+		return 0, false
+	}
+	next := i + 2
+	if next < len(buf) && buf[next] == '\r' {
+		next++
+	}
+	if next < len(buf) && buf[next] == '\n' {
+		return next + 1, true
+	}
+	return next, next == len(buf)
+}
+
+// isCopyFromStdin reports whether a statement is a COPY reading its rows from the lines following it.
+func isCopyFromStdin(text []byte) bool {
+	text = bytes.TrimSpace(text)
+	const suffix = "from stdin"
+	return len(text) > len(suffix) && bytes.EqualFold(text[:5], []byte("COPY ")) &&
+		bytes.EqualFold(text[len(text)-len(suffix):], []byte(suffix))
+}
+
+// isTagByte reports whether c can be part of a dollar quote tag.
+func isTagByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
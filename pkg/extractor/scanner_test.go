@@ -0,0 +1,95 @@
+package extractor
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// scanAll returns the text of every statement a scanner reads from r.
+func scanAll(t *testing.T, r io.Reader, syntax Syntax) []string {
+	t.Helper()
+	s := newScanner(r, syntax)
+	var texts []string
+	for {
+		stmt, err := s.next()
+		if err == io.EOF {
+			return texts
+		}
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		texts = append(texts, stmt.text)
+	}
+}
+
+func TestScanner(t *testing.T) {
+	mysql := MySQL.Syntax()
+	postgres := Postgres.Syntax()
+	tests := []struct {
+		name   string
+		syntax Syntax
+		dump   string
+		want   []string
+	}{
+		{"statements", mysql, "SELECT 1;\nSELECT 2;", []string{"SELECT 1", "SELECT 2"}},
+		{"empty statements", mysql, "/*!40101 SET NAMES utf8 */;;\n;SELECT 1;", []string{"SELECT 1"}},
+		{"comments between statements", mysql, "-- a; b\n# c; d\n/* e; f */SELECT 1;", []string{"SELECT 1"}},
+		{"comments within a statement", mysql, "SELECT 1 -- a; b\n, /* c; d */ 2;", []string{"SELECT 1 -- a; b\n, /* c; d */ 2"}},
+		{"semicolon in string", mysql, "INSERT INTO t VALUES ('a;b');", []string{"INSERT INTO t VALUES ('a;b')"}},
+		{"doubled quote", mysql, "INSERT INTO t VALUES ('it''s;');", []string{"INSERT INTO t VALUES ('it''s;')"}},
+		{"backslash escape", mysql, `INSERT INTO t VALUES ('a\';b');`, []string{`INSERT INTO t VALUES ('a\';b')`}},
+		{"escaped backslash", mysql, `INSERT INTO t VALUES ('a\\');SELECT 1;`, []string{`INSERT INTO t VALUES ('a\\')`, "SELECT 1"}},
+		{"quoted identifier", mysql, "INSERT INTO `a;b` VALUES (1);", []string{"INSERT INTO `a;b` VALUES (1)"}},
+		{"backslash within a backtick", mysql, "SELECT `a\\`;SELECT 1;", []string{"SELECT `a\\`", "SELECT 1"}},
+		{"truncated statement", mysql, "SELECT 1;\nINSERT INTO t VALUES (1", []string{"SELECT 1", "INSERT INTO t VALUES (1"}},
+		{"truncated string", mysql, "INSERT INTO t VALUES ('a;", []string{"INSERT INTO t VALUES ('a;"}},
+		{"backslash without escapes", postgres, `INSERT INTO t VALUES ('a\');SELECT 1;`, []string{`INSERT INTO t VALUES ('a\')`, "SELECT 1"}},
+		{"escape string", postgres, `INSERT INTO t VALUES (E'a\';b');`, []string{`INSERT INTO t VALUES (E'a\';b')`}},
+		{"dollar quotes", postgres, "CREATE FUNCTION f() AS $$ SELECT 1; $$;SELECT 2;", []string{"CREATE FUNCTION f() AS $$ SELECT 1; $$", "SELECT 2"}},
+		{"tagged dollar quotes", postgres, "SELECT $body$ $$; ' $body$;SELECT 2;", []string{"SELECT $body$ $$; ' $body$", "SELECT 2"}},
+		{"parameter", postgres, "SELECT $1;SELECT 2;", []string{"SELECT $1", "SELECT 2"}},
+		{"dollar in identifier", postgres, "SELECT a$b, 'c;';", []string{"SELECT a$b, 'c;'"}},
+		{"dollar quotes disabled", mysql, "SELECT $$;SELECT 2;", []string{"SELECT $$", "SELECT 2"}},
+		{"psql command", postgres, "\\connect db\nSELECT 1;", []string{"SELECT 1"}},
+		{"copy rows", postgres, "COPY t (a, b) FROM stdin;\n1\tx;y\n2\t\\N\n\\.\nSELECT 1;", []string{"COPY t (a, b) FROM stdin;\n1\tx;y\n2\t\\N\n", "SELECT 1"}},
+		{"copy rows with CRLF", postgres, "COPY t FROM stdin;\r\n1\r\n\\.\r\nSELECT 1;", []string{"COPY t FROM stdin;\r\n1\r\n", "SELECT 1"}},
+		{"copy rows at the end", postgres, "COPY t FROM stdin;\n1\n\\.", []string{"COPY t FROM stdin;\n1\n"}},
+		{"backslash within a copy row", postgres, "COPY t FROM stdin;\na\\.b\n\\.\n", []string{"COPY t FROM stdin;\na\\.b\n"}},
+		{"copy to stdout", postgres, "COPY t TO stdout;SELECT 1;", []string{"COPY t TO stdout", "SELECT 1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := scanAll(t, strings.NewReader(test.dump), test.syntax); !slices.Equal(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+			// Reading a byte at a time, every state is left at the end of the buffer.
+			if got := scanAll(t, iotest.OneByteReader(strings.NewReader(test.dump)), test.syntax); !slices.Equal(got, test.want) {
+				t.Errorf("reading a byte at a time, got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScannerPositions(t *testing.T) {
+	dump := "-- header\nSELECT 1;\n\nSELECT\n2;"
+	s := newScanner(strings.NewReader(dump), MySQL.Syntax())
+	want := []statement{
+		{text: "SELECT 1", comments: "-- header\n", offset: 10, line: 2, end: 19},
+		{text: "SELECT\n2", comments: "\n\n", offset: 21, line: 4, end: 30},
+	}
+	for _, w := range want {
+		got, err := s.next()
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		if got != w {
+			t.Errorf("got %+v, want %+v", got, w)
+		}
+	}
+	if _, err := s.next(); err != io.EOF {
+		t.Errorf("got %v after the last statement, want io.EOF", err)
+	}
+}
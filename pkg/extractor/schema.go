@@ -0,0 +1,62 @@
+package extractor
+
+import "io"
+
+// TableSchema describes a table as its CREATE TABLE statement declares it.
+type TableSchema struct {
+	Name    string
+	Columns []Column
+	// PrimaryKey and ForeignKeys are nil if the table declares none or the dialect can't read
+	// them, see KeyReader.
+	PrimaryKey  []string
+	ForeignKeys []ForeignKey
+}
+
+// Schema returns the schema of a table.
+func (d *Dump) Schema(tableName string) (*TableSchema, error) {
+	create, err := d.createStatement(tableName)
+	if err != nil {
+		return nil, err
+	}
+	return d.schema(tableName, create.text)
+}
+
+// Schemas returns the schemas of every table created in the dump, in order of appearance,
+// reading the dump once.
+func (d *Dump) Schemas() ([]*TableSchema, error) {
+	p, err := d.pass()
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+	var schemas []*TableSchema
+	for {
+		stmt, err := p.next()
+		if err == io.EOF {
+			return schemas, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if name, ok := d.Dialect.CreatedTable(stmt.text); ok {
+			schema, err := d.schema(name, stmt.text)
+			if err != nil {
+				return nil, err
+			}
+			schemas = append(schemas, schema)
+		}
+	}
+}
+
+func (d *Dump) schema(tableName, createStatement string) (*TableSchema, error) {
+	columns, err := d.Dialect.Columns(createStatement)
+	if err != nil {
+		return nil, err
+	}
+	schema := &TableSchema{Name: tableName, Columns: columns}
+	if reader, ok := d.Dialect.(KeyReader); ok {
+		schema.PrimaryKey = reader.PrimaryKey(createStatement)
+		schema.ForeignKeys = reader.ForeignKeys(createStatement)
+	}
+	return schema, nil
+}
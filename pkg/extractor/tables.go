@@ -0,0 +1,165 @@
+package extractor
+
+import (
+	"errors"
+	"io"
+)
+
+// errReadByTables is returned by the Next method of the iterators of a TablesIterator, whose
+// rows are read through the TablesIterator.
+var errReadByTables = errors.New("the rows of the table are read through its TablesIterator")
+
+// IterateTables returns a TablesIterator over the rows of the given tables, or of every table of
+// the dump if tableNames is empty, reading the dump once for all of them. Options apply to every
+// table, Columns selecting the columns of each table by name.
+func (d *Dump) IterateTables(tableNames []string, opts Options) (*TablesIterator, error) {
+	p, err := d.pass()
+	if err != nil {
+		return nil, err
+	}
+	t := &TablesIterator{
+		dialect: d.Dialect,
+		opts:    opts,
+		pass:    p,
+		tables:  make(map[string]*Iterator),
+		open:    make(map[string]*Iterator),
+	}
+	if len(tableNames) > 0 {
+		t.names = tableNames
+		t.requested = make(map[string]bool)
+		for _, name := range tableNames {
+			t.requested[name] = true
+		}
+	}
+	return t, nil
+}
+
+// TablesIterator yields the rows of several tables in the order the dump holds them. Each table
+// has an Iterator describing its columns and counting its rows, created once its CREATE TABLE
+// statement is read; its rows are only returned by TablesIterator.Next.
+type TablesIterator struct {
+	dialect Dialect
+	opts    Options
+	pass    *pass
+	// names lists the tables to read, and requested holds them, nil for all tables.
+	names     []string
+	requested map[string]bool
+	tables    map[string]*Iterator
+	order     []*Iterator
+	// open holds the tables whose part of the dump is being read, whose data statements are
+	// taken, as the Iterator of a single table does.
+	open    map[string]*Iterator
+	current *Iterator
+	err     error
+}
+
+// Next returns the next row of any of the tables, along with the Iterator of its table, or
+// io.EOF once the dump is read. A *TableNotFoundError is returned at the end of the dump if a
+// requested table wasn't created in it.
+func (t *TablesIterator) Next() (*Iterator, Record, error) {
+	for {
+		if t.current != nil {
+			if record, ok := t.current.nextPending(); ok {
+				return t.current, record, nil
+			}
+			if t.current.parseErr != nil {
+				t.err = t.current.parseErr
+				t.Close()
+			}
+			t.current = nil
+		}
+		if t.err != nil {
+			return nil, nil, t.err
+		}
+		// Every requested table was read once none is left that may get more rows.
+		if t.requested != nil && len(t.tables) == len(t.requested) && len(t.open) == 0 {
+			t.err = io.EOF
+			t.Close()
+			continue
+		}
+
+		stmt, err := t.pass.next()
+		if err == io.EOF {
+			err = t.missing()
+		}
+		if err != nil {
+			t.err = err
+			t.Close()
+			continue
+		}
+		if t.dialect.EndsTable(stmt.text) {
+			clear(t.open)
+		}
+		inserted, isData := t.dialect.InsertedTable(stmt.text)
+		for _, it := range t.open {
+			it.describeStatement(stmt, isData)
+		}
+		if name, ok := t.dialect.CreatedTable(stmt.text); ok {
+			if t.requested != nil && !t.requested[name] {
+				continue
+			}
+			it := t.tables[name]
+			if it == nil {
+				columns, err := t.dialect.Columns(stmt.text)
+				if err != nil {
+					t.err = err
+					t.Close()
+					continue
+				}
+				it = newIterator(t.dialect, name, columns, t.opts)
+				it.err = errReadByTables
+				it.describe(stmt.text)
+				t.tables[name] = it
+				t.order = append(t.order, it)
+			}
+			t.open[name] = it
+			continue
+		}
+		if isData && t.open[inserted] != nil {
+			t.current = t.open[inserted]
+			t.current.load(stmt)
+		}
+	}
+}
+
+// missing returns a *TableNotFoundError for the first requested table the dump didn't create.
+func (t *TablesIterator) missing() error {
+	for _, name := range t.names {
+		if t.tables[name] == nil {
+			return &TableNotFoundError{Table: name}
+		}
+	}
+	return io.EOF
+}
+
+// Reading reports whether the section of a table is being read, so that more of its rows may
+// follow. Once it ended, say at the next table's CREATE TABLE statement, the table only gets
+// more rows if the dump creates it again.
+func (t *TablesIterator) Reading(tableName string) bool {
+	return t.open[tableName] != nil
+}
+
+// Tables returns the Iterators of the tables created so far, in the order of their creation.
+// Once Next returned io.EOF, these are all the tables read.
+func (t *TablesIterator) Tables() []*Iterator {
+	return t.order
+}
+
+// DeclaredRows returns the number of rows the dump states a table read so far has, like
+// Iterator.DeclaredRows.
+func (t *TablesIterator) DeclaredRows(tableName string) (RowCount, bool) {
+	it := t.tables[tableName]
+	if it == nil {
+		return RowCount{}, false
+	}
+	return it.DeclaredRows()
+}
+
+// Close closes the dump. It only needs to be called when the iteration is given up on before
+// Next returns an error or io.EOF.
+func (t *TablesIterator) Close() error {
+	if t.err == nil {
+		t.err = io.EOF
+	}
+	return t.pass.Close()
+}
@@ -0,0 +1,95 @@
+package extractor
+
+import "strings"
+
+// skipFunc returns the position following the string, quoted identifier or bracketed expression
+// starting at i in s, or a position past the end of s if it isn't closed. Positions holding
+// anything else are skipped by one byte.
+type skipFunc func(s string, i int) int
+
+// splitList splits s at the commas outside of the tokens skip steps over.
+func splitList(s string, skip skipFunc) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+			i++
+		case '\'', '"', '`', '(', '[':
+			i = skip(s, i)
+		default:
+			i++
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// splitTuples returns the text inside the parentheses of each row of a VALUES list, stopping at
+// the first thing following the rows, such as an ON DUPLICATE KEY UPDATE clause, or at a row
+// that isn't closed. The text it stopped at is returned as well, empty if the rows ran to the
+// end of rows.
+func splitTuples(rows string, skip skipFunc) ([]string, string) {
+	var tuples []string
+	for i := 0; i < len(rows); {
+		switch rows[i] {
+		case '(':
+			end := skip(rows, i)
+			if end > len(rows) {
+				return tuples, rows[i:]
+			}
+			tuples = append(tuples, rows[i+1:end-1])
+			i = end
+		case ' ', '\t', '\r', '\n', ',':
+			i++
+		default:
+			return tuples, rows[i:]
+		}
+	}
+	return tuples, ""
+}
+
+// unreadRows returns the text splitTuples stopped at, unless it is one of the clauses that may
+// follow the rows of a statement, starting with one of keywords.
+func unreadRows(rest string, keywords ...string) string {
+	for _, keyword := range keywords {
+		if len(rest) > len(keyword) && strings.EqualFold(rest[:len(keyword)], keyword) && !isIdentifierByte(rest[len(keyword)]) {
+			return ""
+		}
+	}
+	return rest
+}
+
+// isIdentifierByte reports whether c can be part of an unquoted identifier or keyword.
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// columnList returns the raw names of the explicit column list at the start of rest, the rest
+// of an INSERT statement following its table name, or nil if there is none.
+func columnList(rest string, skip skipFunc) []string {
+	trimmed := strings.TrimLeft(rest, " \t\r\n")
+	if !strings.HasPrefix(trimmed, "(") {
+		return nil
+	}
+	end := skip(trimmed, 0)
+	if end > len(trimmed) {
+		return nil
+	}
+	names := splitList(trimmed[1:end-1], skip)
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+	}
+	return names
+}
+
+// skipColumnList returns the rest of an INSERT statement following its table name, past the
+// explicit column list if there is one.
+func skipColumnList(rest string, skip skipFunc) string {
+	trimmed := strings.TrimLeft(rest, " \t\r\n")
+	if !strings.HasPrefix(trimmed, "(") {
+		return rest
+	}
+	return trimmed[min(skip(trimmed, 0), len(trimmed)):]
+}
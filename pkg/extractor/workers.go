@@ -0,0 +1,197 @@
+package extractor
+
+import (
+	"io"
+	"sync"
+)
+
+// parsedStatement holds the rows of a data statement parsed by a worker.
+type parsedStatement struct {
+	info    StatementInfo
+	records []Record
+	// malformed counts the rows skipped, which rows describes if the iterator reports them.
+	malformed int
+	rows      []MalformedRow
+	// err is the *ParseError a strict iterator stopped at, after records.
+	err error
+}
+
+// workerPool reads the data statements of a table ahead of its Iterator and parses them on
+// several goroutines, so that parsing no longer waits on reading and tokenizing the rows of one
+// statement no longer waits on the previous one.
+type workerPool struct {
+	// ordered passes, in the order of the dump, the channel each statement is delivered on once
+	// parsed. Unordered pools deliver the statements on unordered as they are parsed. Either is
+	// closed once the reading ended, with err.
+	ordered   chan chan parsedStatement
+	unordered chan parsedStatement
+	err       error
+
+	done    chan struct{}
+	stopped chan struct{}
+	stop    sync.Once
+}
+
+// job is a statement waiting for a worker.
+type job struct {
+	stmt   statement
+	result chan parsedStatement
+}
+
+// startWorkers reads the rest of the table on a goroutine, parsing its statements on the given
+// number of workers. Statements in flight are bounded by twice their number, keeping memory
+// bounded by as many statements.
+func (it *Iterator) startWorkers(workers int, unordered bool) {
+	pool := &workerPool{done: make(chan struct{}), stopped: make(chan struct{})}
+	if unordered {
+		pool.unordered = make(chan parsedStatement, workers)
+	} else {
+		pool.ordered = make(chan chan parsedStatement, workers)
+	}
+	it.workers = pool
+
+	jobs := make(chan job, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			parser := &Iterator{dialect: it.dialect, tableName: it.tableName, columns: it.columns, selected: it.selected, strict: it.strict}
+			for j := range jobs {
+				parsed := parser.parse(j.stmt, it.onMalformed != nil)
+				if j.result != nil {
+					j.result <- parsed
+					continue
+				}
+				select {
+				case pool.unordered <- parsed:
+				case <-pool.done:
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(pool.stopped)
+		pool.err = it.readJobs(pool, jobs)
+		close(jobs)
+		wg.Wait()
+		if unordered {
+			close(pool.unordered)
+		} else {
+			close(pool.ordered)
+		}
+	}()
+}
+
+// readJobs passes the data statements of the table to the workers, returning the error the
+// reading ended with, io.EOF at the end of the table. Stopping the pool ends it with io.EOF.
+func (it *Iterator) readJobs(pool *workerPool, jobs chan<- job) error {
+	for {
+		stmt, err := it.pass.next()
+		if err == nil && it.dialect.EndsTable(stmt.text) {
+			err = io.EOF
+		}
+		if err != nil {
+			return err
+		}
+		if table, ok := it.dialect.InsertedTable(stmt.text); !ok || table != it.tableName {
+			it.describeStatement(stmt, ok)
+			continue
+		}
+		it.describeStatement(stmt, true)
+		j := job{stmt: stmt}
+		if pool.ordered != nil {
+			// Buffered so that workers never wait on the iterator.
+			j.result = make(chan parsedStatement, 1)
+			select {
+			case pool.ordered <- j.result:
+			case <-pool.done:
+				return io.EOF
+			}
+		}
+		select {
+		case jobs <- j:
+		case <-pool.done:
+			return io.EOF
+		}
+	}
+}
+
+// parse returns the rows of a data statement. The iterator is a worker's own.
+func (it *Iterator) parse(stmt statement, reportMalformed bool) parsedStatement {
+	var parsed parsedStatement
+	it.onStatement = func(info StatementInfo) { parsed.info = info }
+	it.onMalformed = nil
+	if reportMalformed {
+		it.onMalformed = func(row MalformedRow) { parsed.rows = append(parsed.rows, row) }
+	}
+	malformed := it.malformed
+	it.load(stmt)
+	for {
+		record, ok := it.nextPending()
+		if !ok {
+			break
+		}
+		parsed.records = append(parsed.records, record)
+	}
+	parsed.malformed = it.malformed - malformed
+	// The worker goes on with the statements it is given, which a strict iterator drops once it
+	// stopped.
+	parsed.err, it.parseErr = it.parseErr, nil
+	return parsed
+}
+
+// next returns the next parsed statement, or the error the reading ended with once all were
+// returned.
+func (p *workerPool) next() (parsedStatement, error) {
+	if p.ordered != nil {
+		result, ok := <-p.ordered
+		if !ok {
+			return parsedStatement{}, p.err
+		}
+		return <-result, nil
+	}
+	parsed, ok := <-p.unordered
+	if !ok {
+		return parsedStatement{}, p.err
+	}
+	return parsed, nil
+}
+
+// close stops the reading and waits for the goroutines of the pool to end.
+func (p *workerPool) close() {
+	p.stop.Do(func() { close(p.done) })
+	<-p.stopped
+}
+
+// nextParsed returns the next record of an iterator reading through a worker pool.
+func (it *Iterator) nextParsed() (Record, error) {
+	for len(it.parsed) == 0 {
+		if it.parseErr != nil && it.err == nil {
+			it.err = it.parseErr
+			it.Close()
+		}
+		if it.err != nil {
+			return nil, it.err
+		}
+		parsed, err := it.workers.next()
+		if err != nil {
+			it.err = err
+			it.Close()
+			continue
+		}
+		if it.onStatement != nil {
+			it.onStatement(parsed.info)
+		}
+		it.malformed += parsed.malformed
+		for _, row := range parsed.rows {
+			it.onMalformed(row)
+		}
+		it.parsed, it.parseErr = parsed.records, parsed.err
+	}
+	record := it.parsed[0]
+	it.parsed = it.parsed[1:]
+	it.rows++
+	return record, nil
+}
@@ -0,0 +1,128 @@
+package extractor
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// workersDump returns a dump of a table whose rows are numbered from 0, inserted by statements
+// of varying length so that later statements may be parsed first, and the ids of the rows of
+// each statement.
+func workersDump(statements int) (string, [][]string) {
+	var dump strings.Builder
+	var ids [][]string
+	n := 0
+	dump.WriteString("CREATE TABLE `t` (\n  `id` int,\n  `name` varchar(255)\n);\n")
+	for i := 0; i < statements; i++ {
+		dump.WriteString("INSERT INTO `t` VALUES ")
+		var statementIDs []string
+		for j := 0; j <= i%7; j++ {
+			if j > 0 {
+				dump.WriteByte(',')
+			}
+			id := strconv.Itoa(n)
+			n++
+			statementIDs = append(statementIDs, id)
+			fmt.Fprintf(&dump, "(%s,'row %s; (%d)')", id, id, j)
+		}
+		ids = append(ids, statementIDs)
+		dump.WriteString(";\n")
+	}
+	dump.WriteString("CREATE TABLE `u` (\n  `id` int\n);\nINSERT INTO `u` VALUES (1);\n")
+	return dump.String(), ids
+}
+
+// iterateIDs returns the ids of the rows of table t, and the rows read.
+func iterateIDs(t *testing.T, dump string, opts Options) ([]string, int) {
+	t.Helper()
+	it, err := New([]byte(dump)).Iterate("t", opts)
+	if err != nil {
+		t.Fatalf("Iterate: %v", err)
+	}
+	defer it.Close()
+	var ids []string
+	for {
+		record, err := it.Next()
+		if err == io.EOF {
+			return ids, it.Rows()
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		if want := "row " + record[0].Value + "; ("; !strings.HasPrefix(record[1].Value, want) {
+			t.Fatalf("record %v holds the name of another row", record)
+		}
+		ids = append(ids, record[0].Value)
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	dump, statements := workersDump(200)
+	want := slices.Concat(statements...)
+	tests := []struct {
+		name      string
+		workers   int
+		unordered bool
+	}{
+		{"single goroutine", 0, false},
+		{"one worker", 1, false},
+		{"two workers", 2, false},
+		{"eight workers", 8, false},
+		{"unordered", 8, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ids, rows := iterateIDs(t, dump, Options{Workers: test.workers, Unordered: test.unordered})
+			if rows != len(want) {
+				t.Errorf("Rows() = %d, want %d", rows, len(want))
+			}
+			if !test.unordered {
+				if !slices.Equal(ids, want) {
+					t.Errorf("got rows %v, want %v", ids, want)
+				}
+				return
+			}
+			// The rows of a statement stay in order, those of different statements needn't.
+			position := make(map[string]int)
+			for i, id := range ids {
+				position[id] = i
+			}
+			for _, statementIDs := range statements {
+				for i := 1; i < len(statementIDs); i++ {
+					if position[statementIDs[i]] < position[statementIDs[i-1]] {
+						t.Fatalf("row %s is returned before row %s of the same statement", statementIDs[i], statementIDs[i-1])
+					}
+				}
+			}
+			slices.SortFunc(ids, func(a, b string) int {
+				x, _ := strconv.Atoi(a)
+				y, _ := strconv.Atoi(b)
+				return x - y
+			})
+			if !slices.Equal(ids, want) {
+				t.Errorf("got rows %v, want %v", ids, want)
+			}
+		})
+	}
+}
+
+func TestWorkersClose(t *testing.T) {
+	dump, _ := workersDump(200)
+	for _, unordered := range []bool{false, true} {
+		it, err := New([]byte(dump)).Iterate("t", Options{Workers: 4, Unordered: unordered})
+		if err != nil {
+			t.Fatalf("Iterate: %v", err)
+		}
+		if _, err := it.Next(); err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		// Closing while the workers are ahead of the iterator stops them.
+		if err := it.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	}
+}
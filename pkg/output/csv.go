@@ -0,0 +1,49 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+func init() {
+	Register(Format{
+		Name:        "csv",
+		Extension:   ".csv",
+		ContentType: "text/csv; charset=utf-8",
+		New:         func(w io.Writer) Writer { return &csvWriter{w: csv.NewWriter(w)} },
+	})
+}
+
+// csvWriter writes a header row with the column names followed by a row per record, quoting
+// values holding commas, quotes or line breaks as RFC 4180 does. NULL values are left empty.
+type csvWriter struct {
+	w   *csv.Writer
+	row []string
+}
+
+func (c *csvWriter) Begin(tableName string, columns []extractor.Column) error {
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	return c.w.Write(header)
+}
+
+func (c *csvWriter) WriteRecord(record extractor.Record) error {
+	c.row = c.row[:0]
+	for _, field := range record {
+		if field.Null {
+			c.row = append(c.row, "")
+			continue
+		}
+		c.row = append(c.row, field.Value)
+	}
+	return c.w.Write(c.row)
+}
+
+func (c *csvWriter) End() error {
+	c.w.Flush()
+	return c.w.Error()
+}
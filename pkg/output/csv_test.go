@@ -0,0 +1,32 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+func TestCSV(t *testing.T) {
+	format, _ := Lookup("csv")
+	columns := []extractor.Column{{Name: "id"}, {Name: "name"}}
+	tests := []struct {
+		name   string
+		record extractor.Record
+		want   string
+	}{
+		{"values", extractor.Record{{Name: "id", Value: "1"}, {Name: "name", Value: "ann"}}, "id,name\n1,ann\n"},
+		{"comma", extractor.Record{{Name: "id", Value: "1"}, {Name: "name", Value: "a,b"}}, "id,name\n1,\"a,b\"\n"},
+		{"quote", extractor.Record{{Name: "id", Value: "1"}, {Name: "name", Value: `say "hi"`}}, "id,name\n1,\"say \"\"hi\"\"\"\n"},
+		{"line break", extractor.Record{{Name: "id", Value: "1"}, {Name: "name", Value: "a\nb"}}, "id,name\n1,\"a\nb\"\n"},
+		{"null", extractor.Record{{Name: "id", Value: "1"}, {Name: "name", Value: "NULL", Null: true}}, "id,name\n1,\n"},
+		{"text NULL", extractor.Record{{Name: "id", Value: "1"}, {Name: "name", Value: "NULL"}}, "id,name\n1,NULL\n"},
+		{"empty", extractor.Record{{Name: "id", Value: "1"}, {Name: "name", Value: ""}}, "id,name\n1,\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := write(t, format, columns, test.record); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
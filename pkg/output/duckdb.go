@@ -0,0 +1,197 @@
+//go:build cgo
+
+package output
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/marcboeker/go-duckdb"
+)
+
+func init() {
+	Register(Format{
+		Name:        "duckdb",
+		Extension:   ".duckdb",
+		ContentType: "application/vnd.duckdb",
+		New:         func(w io.Writer) Writer { return &duckdbWriter{w: w} },
+	})
+}
+
+// duckdbWriter writes a DuckDB database file holding the table. DuckDB needs a file it can seek
+// in, so the database is built in a temporary directory and copied to w once complete.
+//
+// Rows are appended as text to a staging table, which End converts into the table with the
+// declared types mapped to DuckDB types. Values that do not convert, such as MySQL's zero
+// dates, become NULL, and invalid UTF-8 in text is replaced.
+type duckdbWriter struct {
+	w io.Writer
+
+	dir      string
+	db       *sql.DB
+	conn     *sql.Conn
+	appender *duckdb.Appender
+	table    string
+	columns  []extractor.Column
+	blobs    []bool
+	values   []driver.Value
+}
+
+func (d *duckdbWriter) Begin(tableName string, columns []extractor.Column) error {
+	dir, err := os.MkdirTemp("", "sql-data-extractor-*")
+	if err != nil {
+		return err
+	}
+	d.dir = dir
+	if d.db, err = sql.Open("duckdb", filepath.Join(dir, "out.duckdb")); err != nil {
+		return err
+	}
+	if d.conn, err = d.db.Conn(context.Background()); err != nil {
+		return err
+	}
+
+	d.table = tableName
+	d.columns = columns
+	d.blobs = make([]bool, len(columns))
+	d.values = make([]driver.Value, len(columns))
+	definitions := make([]string, len(columns))
+	for i, col := range columns {
+		d.blobs[i] = duckdbType(col.Type) == "BLOB"
+		if d.blobs[i] {
+			definitions[i] = quoteDuckDB(col.Name) + " BLOB"
+		} else {
+			definitions[i] = quoteDuckDB(col.Name) + " VARCHAR"
+		}
+	}
+	if _, err := d.conn.ExecContext(context.Background(), fmt.Sprintf("CREATE TABLE %s (%s)", quoteDuckDB(d.staging()), strings.Join(definitions, ", "))); err != nil {
+		return err
+	}
+	return d.conn.Raw(func(raw interface{}) error {
+		d.appender, err = duckdb.NewAppenderFromConn(raw.(driver.Conn), "", d.staging())
+		return err
+	})
+}
+
+// staging names the table rows are appended to before conversion.
+func (d *duckdbWriter) staging() string {
+	return d.table + "_staging"
+}
+
+func (d *duckdbWriter) WriteRecord(record extractor.Record) error {
+	for i := range d.values {
+		switch {
+		case i >= len(record) || record[i].Null:
+			d.values[i] = nil
+		case d.blobs[i]:
+			d.values[i] = []byte(record[i].Value)
+		default:
+			// DuckDB text must be valid UTF-8.
+			d.values[i] = strings.ToValidUTF8(record[i].Value, "\uFFFD")
+		}
+	}
+	return d.appender.AppendRow(d.values...)
+}
+
+func (d *duckdbWriter) End() error {
+	defer os.RemoveAll(d.dir)
+	if err := d.appender.Close(); err != nil {
+		return err
+	}
+
+	selected := make([]string, len(d.columns))
+	for i, col := range d.columns {
+		selected[i] = fmt.Sprintf("TRY_CAST(%s AS %s) AS %s", quoteDuckDB(col.Name), duckdbType(col.Type), quoteDuckDB(col.Name))
+	}
+	ctx := context.Background()
+	for _, statement := range []string{
+		fmt.Sprintf("CREATE TABLE %s AS SELECT %s FROM %s", quoteDuckDB(d.table), strings.Join(selected, ", "), quoteDuckDB(d.staging())),
+		fmt.Sprintf("DROP TABLE %s", quoteDuckDB(d.staging())),
+		"CHECKPOINT",
+	} {
+		if _, err := d.conn.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	d.conn.Close()
+	if err := d.db.Close(); err != nil {
+		return err
+	}
+
+	file, err := os.Open(filepath.Join(d.dir, "out.duckdb"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(d.w, file)
+	return err
+}
+
+func quoteDuckDB(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// The name and arguments of a MySQL column type, e.g. "decimal" and "10,2" in "decimal(10,2) unsigned".
+var duckdbTypeRegex = regexp.MustCompile(`^(\w+)\s*(?:\(([^)]*)\))?`)
+
+// duckdbType maps a MySQL column type to a DuckDB type.
+func duckdbType(declared string) string {
+	declared = strings.ToLower(declared)
+	match := duckdbTypeRegex.FindStringSubmatch(declared)
+	if match == nil {
+		return "VARCHAR"
+	}
+	name, args := match[1], match[2]
+	unsigned := strings.Contains(declared, "unsigned")
+	integer := func(signed, withoutSign string) string {
+		if unsigned {
+			return withoutSign
+		}
+		return signed
+	}
+
+	switch name {
+	case "tinyint", "bool", "boolean":
+		return integer("TINYINT", "UTINYINT")
+	case "smallint", "year":
+		return integer("SMALLINT", "USMALLINT")
+	case "mediumint", "int", "integer":
+		return integer("INTEGER", "UINTEGER")
+	case "bigint":
+		return integer("BIGINT", "UBIGINT")
+	case "decimal", "numeric", "dec", "fixed":
+		// DuckDB decimals hold at most 38 digits.
+		var precision, scale int
+		if n, _ := fmt.Sscanf(args, "%d,%d", &precision, &scale); n > 0 && precision <= 38 {
+			return fmt.Sprintf("DECIMAL(%d,%d)", precision, scale)
+		}
+		if args == "" {
+			return "DECIMAL(10,0)"
+		}
+		return "DOUBLE"
+	case "float":
+		return "FLOAT"
+	case "double", "real":
+		return "DOUBLE"
+	case "date":
+		return "DATE"
+	case "datetime", "timestamp":
+		return "TIMESTAMP"
+	case "time":
+		return "TIME"
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		return "BLOB"
+	case "json":
+		return "JSON"
+	default:
+		// Text, enum, set, bit and anything unknown.
+		return "VARCHAR"
+	}
+}
@@ -0,0 +1,55 @@
+package output
+
+import (
+	"io"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+func init() {
+	Register(Hashcat(":"))
+}
+
+// Hashcat returns the hashcat format writing the values of a record separated by separator.
+// The format registered as hashcat separates them with ':'.
+func Hashcat(separator string) Format {
+	return Format{
+		Name:        "hashcat",
+		Extension:   ".txt",
+		ContentType: "text/plain; charset=utf-8",
+		New:         func(w io.Writer) Writer { return &hashcatWriter{w: w, separator: separator} },
+	}
+}
+
+// hashcatWriter writes one line per record with the values separated by separator, NULL values
+// as empty ones.
+type hashcatWriter struct {
+	w         io.Writer
+	separator string
+	count     int
+}
+
+func (h *hashcatWriter) Begin(tableName string, columns []extractor.Column) error {
+	return nil
+}
+
+func (h *hashcatWriter) WriteRecord(record extractor.Record) error {
+	values := record.Values()
+	for i, field := range record {
+		if field.Null {
+			values[i] = ""
+		}
+	}
+	line := strings.Join(values, h.separator)
+	if h.count > 0 {
+		line = "\n" + line
+	}
+	h.count++
+	_, err := io.WriteString(h.w, line)
+	return err
+}
+
+func (h *hashcatWriter) End() error {
+	return nil
+}
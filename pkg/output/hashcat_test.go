@@ -0,0 +1,57 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// write returns the output of format for records.
+func write(t *testing.T, format Format, columns []extractor.Column, records ...extractor.Record) string {
+	t.Helper()
+	var out strings.Builder
+	w := format.New(&out)
+	if err := w.Begin("users", columns); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	for _, record := range records {
+		if err := w.WriteRecord(record); err != nil {
+			t.Fatalf("WriteRecord: %v", err)
+		}
+	}
+	if err := w.End(); err != nil {
+		t.Fatalf("End: %v", err)
+	}
+	return out.String()
+}
+
+func TestHashcat(t *testing.T) {
+	columns := []extractor.Column{{Name: "email"}, {Name: "hash"}}
+	tests := []struct {
+		name      string
+		separator string
+		records   []extractor.Record
+		want      string
+	}{
+		{"values", ":", []extractor.Record{
+			{{Name: "email", Value: "a@x.com"}, {Name: "hash", Value: "5f4d"}},
+			{{Name: "email", Value: "b@x.com"}, {Name: "hash", Value: "e10a"}},
+		}, "a@x.com:5f4d\nb@x.com:e10a"},
+		{"null", ":", []extractor.Record{
+			{{Name: "email", Value: "a@x.com"}, {Name: "hash", Value: "NULL", Null: true}},
+			{{Name: "email", Value: "NULL"}, {Name: "hash", Value: "e10a"}},
+		}, "a@x.com:\nNULL:e10a"},
+		{"separator", "\t", []extractor.Record{
+			{{Name: "email", Value: "a@x.com"}, {Name: "hash", Value: "sha1:5f4d"}},
+		}, "a@x.com\tsha1:5f4d"},
+		{"no records", ":", nil, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := write(t, Hashcat(test.separator), columns, test.records...); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+func init() {
+	Register(Format{
+		Name:        "json",
+		Extension:   ".json",
+		ContentType: "application/json",
+		New:         func(w io.Writer) Writer { return &jsonWriter{w: w} },
+	})
+}
+
+// jsonWriter writes an indented JSON array with one object per record.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonWriter) Begin(tableName string, columns []extractor.Column) error {
+	_, err := io.WriteString(j.w, "[")
+	return err
+}
+
+func (j *jsonWriter) WriteRecord(record extractor.Record) error {
+	recordMap := make(map[string]interface{})
+	for _, field := range record {
+		if field.Null {
+			recordMap[field.Name] = nil
+			continue
+		}
+		recordMap[field.Name] = field.Value
+	}
+	data, err := json.MarshalIndent(recordMap, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	separator := ",\n  "
+	if j.count == 0 {
+		separator = "\n  "
+	}
+	j.count++
+	if _, err := io.WriteString(j.w, separator); err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonWriter) End() error {
+	closing := "\n]"
+	if j.count == 0 {
+		closing = "]"
+	}
+	_, err := io.WriteString(j.w, closing)
+	return err
+}
@@ -0,0 +1,55 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+func init() {
+	Register(Format{
+		Name:        "jsonl",
+		Extension:   ".jsonl",
+		ContentType: "application/x-ndjson",
+		New:         func(w io.Writer) Writer { return &jsonlWriter{w: w} },
+	})
+}
+
+// jsonlWriter writes one JSON object per line and record, with the fields in column order, so
+// the output can be processed as it is written, for instance by jq.
+type jsonlWriter struct {
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+func (j *jsonlWriter) Begin(tableName string, columns []extractor.Column) error {
+	return nil
+}
+
+func (j *jsonlWriter) WriteRecord(record extractor.Record) error {
+	j.buf.Reset()
+	j.buf.WriteByte('{')
+	for i, field := range record {
+		if i > 0 {
+			j.buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(field.Name)
+		j.buf.Write(name)
+		j.buf.WriteByte(':')
+		if field.Null {
+			j.buf.WriteString("null")
+			continue
+		}
+		value, _ := json.Marshal(field.Value)
+		j.buf.Write(value)
+	}
+	j.buf.WriteString("}\n")
+	_, err := j.w.Write(j.buf.Bytes())
+	return err
+}
+
+func (j *jsonlWriter) End() error {
+	return nil
+}
@@ -0,0 +1,42 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+func TestJSONL(t *testing.T) {
+	format, _ := Lookup("jsonl")
+	columns := []extractor.Column{{Name: "id"}, {Name: "name"}}
+	tests := []struct {
+		name    string
+		records []extractor.Record
+		want    string
+	}{
+		{"values", []extractor.Record{
+			{{Name: "id", Value: "1"}, {Name: "name", Value: "ann"}},
+			{{Name: "id", Value: "2"}, {Name: "name", Value: "bob"}},
+		}, "{\"id\":\"1\",\"name\":\"ann\"}\n{\"id\":\"2\",\"name\":\"bob\"}\n"},
+		{"quotes and line breaks", []extractor.Record{
+			{{Name: "id", Value: "1"}, {Name: "name", Value: "say \"hi\"\n\\"}},
+		}, `{"id":"1","name":"say \"hi\"\n\\"}` + "\n"},
+		{"null", []extractor.Record{
+			{{Name: "id", Value: "1"}, {Name: "name", Value: "NULL", Null: true}},
+		}, `{"id":"1","name":null}` + "\n"},
+		{"text NULL", []extractor.Record{
+			{{Name: "id", Value: "1"}, {Name: "name", Value: "NULL"}},
+		}, `{"id":"1","name":"NULL"}` + "\n"},
+		{"column order", []extractor.Record{
+			{{Name: "name", Value: "ann"}, {Name: "id", Value: "1"}},
+		}, `{"name":"ann","id":"1"}` + "\n"},
+		{"no records", nil, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := write(t, format, columns, test.records...); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
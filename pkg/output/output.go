@@ -0,0 +1,130 @@
+// Package output writes extracted records in the formats supported by the command line tool.
+//
+// Every format implements Writer and is registered under a name with Register. Adding a
+// format only requires registering it, typically from an init function:
+//
+//	func init() {
+//		output.Register(output.Format{
+//			Name:        "tsv",
+//			Extension:   ".tsv",
+//			ContentType: "text/tab-separated-values",
+//			New:         func(w io.Writer) output.Writer { return &tsvWriter{w: w} },
+//		})
+//	}
+//
+// after which it can be selected with -format tsv.
+package output
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// Writer receives the records of one table. Begin is called once before the first record and End
+// once after the last, so formats needing a header or footer can write them there.
+type Writer interface {
+	Begin(tableName string, columns []extractor.Column) error
+	WriteRecord(record extractor.Record) error
+	End() error
+}
+
+// Format describes a registered output format.
+type Format struct {
+	// Name selects the format on the command line.
+	Name string
+	// Extension is appended to output file names, including the leading dot.
+	Extension string
+	// ContentType is the MIME type of the output, used when serving it over HTTP.
+	ContentType string
+	// New creates a Writer emitting to w.
+	New func(w io.Writer) Writer
+}
+
+var (
+	formatsMu sync.RWMutex
+	formats   = make(map[string]Format)
+)
+
+// Register makes a format available by name. It panics if the name is already taken.
+func Register(format Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	if _, exists := formats[format.Name]; exists {
+		panic(fmt.Sprintf("output: format %s registered twice", format.Name))
+	}
+	formats[format.Name] = format
+}
+
+// Lookup returns the format registered under name.
+func Lookup(name string) (Format, error) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	format, ok := formats[name]
+	if !ok {
+		return Format{}, fmt.Errorf("unknown output format %s", name)
+	}
+	return format, nil
+}
+
+// Formats returns the names of all registered formats, sorted.
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Records is a stream of records with their columns, such as an *extractor.Iterator.
+type Records interface {
+	Columns() []extractor.Column
+	Next() (extractor.Record, error)
+}
+
+// Copy writes every record of the iterator to w, calling Begin and End around them.
+// It returns the number of records written.
+func Copy(w Writer, tableName string, it Records) (int, error) {
+	return CopyContext(context.Background(), w, tableName, it)
+}
+
+// CopyContext is like Copy but stops early when ctx is done. End is still called so the output
+// remains well-formed, and the error of ctx is returned along with the number of records written.
+// The same goes for an error of the records, such as the malformed row a strict iterator stopped
+// at.
+func CopyContext(ctx context.Context, w Writer, tableName string, it Records) (int, error) {
+	if err := w.Begin(tableName, it.Columns()); err != nil {
+		return 0, err
+	}
+	count := 0
+	for {
+		if ctx.Err() != nil {
+			if err := w.End(); err != nil {
+				return count, err
+			}
+			return count, ctx.Err()
+		}
+		record, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if endErr := w.End(); endErr != nil {
+				return count, endErr
+			}
+			return count, err
+		}
+		if err := w.WriteRecord(record); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, w.End()
+}
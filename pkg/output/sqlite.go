@@ -0,0 +1,161 @@
+//go:build !js
+
+package output
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	Register(Format{
+		Name:        "sqlite",
+		Extension:   ".db",
+		ContentType: "application/vnd.sqlite3",
+		New:         func(w io.Writer) Writer { return &sqliteWriter{w: w} },
+	})
+}
+
+// sqliteWriter writes a SQLite database file holding the table. Like DuckDB, SQLite needs a file
+// it can seek in, so the database is built in a temporary directory and copied to w once
+// complete.
+//
+// Columns get the type affinity of their declared type, so SQLite stores the values of numeric
+// columns, inserted as text, as numbers. Binary columns are stored as blobs of the bytes they
+// hold. All rows are inserted in one transaction, committed by End.
+type sqliteWriter struct {
+	w io.Writer
+
+	dir    string
+	db     *sql.DB
+	tx     *sql.Tx
+	insert *sql.Stmt
+	blobs  []bool
+	values []interface{}
+}
+
+func (s *sqliteWriter) Begin(tableName string, columns []extractor.Column) error {
+	dir, err := os.MkdirTemp("", "sql-data-extractor-*")
+	if err != nil {
+		return err
+	}
+	s.dir = dir
+	if s.db, err = sql.Open("sqlite", filepath.Join(dir, "out.db")); err != nil {
+		return err
+	}
+	// The transaction holds the only connection the database needs.
+	s.db.SetMaxOpenConns(1)
+	if _, err := s.db.Exec("PRAGMA journal_mode = OFF"); err != nil {
+		return err
+	}
+
+	s.blobs = make([]bool, len(columns))
+	s.values = make([]interface{}, len(columns))
+	definitions := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	for i, col := range columns {
+		affinity := SQLiteAffinity(col.Type)
+		if s.blobs[i] = sqliteBlob(col.Type); s.blobs[i] {
+			affinity = "BLOB"
+		}
+		definitions[i] = quoteSQLite(col.Name) + " " + affinity
+		placeholders[i] = "?"
+	}
+
+	if s.tx, err = s.db.Begin(); err != nil {
+		return err
+	}
+	if _, err := s.tx.Exec(fmt.Sprintf("CREATE TABLE %s (%s)", quoteSQLite(tableName), strings.Join(definitions, ", "))); err != nil {
+		return err
+	}
+	s.insert, err = s.tx.Prepare(fmt.Sprintf("INSERT INTO %s VALUES (%s)", quoteSQLite(tableName), strings.Join(placeholders, ", ")))
+	return err
+}
+
+func (s *sqliteWriter) WriteRecord(record extractor.Record) error {
+	for i := range s.values {
+		switch {
+		case i >= len(record) || record[i].Null:
+			s.values[i] = nil
+		case s.blobs[i]:
+			s.values[i] = sqliteBlobValue(record[i].Value)
+		default:
+			s.values[i] = record[i].Value
+		}
+	}
+	_, err := s.insert.Exec(s.values...)
+	return err
+}
+
+func (s *sqliteWriter) End() error {
+	defer os.RemoveAll(s.dir)
+	if err := s.insert.Close(); err != nil {
+		return err
+	}
+	if err := s.tx.Commit(); err != nil {
+		return err
+	}
+	if err := s.db.Close(); err != nil {
+		return err
+	}
+
+	file, err := os.Open(filepath.Join(s.dir, "out.db"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(s.w, file)
+	return err
+}
+
+// SQLiteAffinity maps a column type declared in a dump to the SQLite type affinity it has,
+// following the rules of https://www.sqlite.org/datatype3.html.
+func SQLiteAffinity(declared string) string {
+	declared = strings.ToUpper(declared)
+	switch {
+	case strings.Contains(declared, "INT"):
+		return "INTEGER"
+	case strings.Contains(declared, "CHAR"), strings.Contains(declared, "TEXT"), strings.Contains(declared, "CLOB"):
+		return "TEXT"
+	case strings.Contains(declared, "REAL"), strings.Contains(declared, "FLOA"), strings.Contains(declared, "DOUB"):
+		return "REAL"
+	case strings.Contains(declared, "DEC"), strings.Contains(declared, "NUM"):
+		return "NUMERIC"
+	default:
+		return "TEXT"
+	}
+}
+
+// sqliteBlob reports whether a declared type, such as "varbinary(16)", holds binary data.
+func sqliteBlob(declared string) bool {
+	name, _, _ := strings.Cut(strings.ToLower(declared), "(")
+	switch strings.TrimSpace(name) {
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob", "bytea":
+		return true
+	}
+	return false
+}
+
+// sqliteBlobValue returns the bytes of a binary value, decoding the 0x... hexadecimal literals
+// binary data is dumped as.
+func sqliteBlobValue(value string) []byte {
+	if len(value) > 2 && (value[:2] == "0x" || value[:2] == "0X") {
+		if decoded, err := hex.DecodeString(value[2:]); err == nil {
+			return decoded
+		}
+	}
+	return []byte(value)
+}
+
+func quoteSQLite(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
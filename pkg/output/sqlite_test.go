@@ -0,0 +1,107 @@
+//go:build !js
+
+package output
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+func TestSQLiteAffinity(t *testing.T) {
+	tests := []struct {
+		declared string
+		want     string
+	}{
+		{"int(11)", "INTEGER"},
+		{"int(10) unsigned", "INTEGER"},
+		{"bigint", "INTEGER"},
+		{"tinyint(1)", "INTEGER"},
+		{"varchar(255)", "TEXT"},
+		{"character varying(64)", "TEXT"},
+		{"longtext", "TEXT"},
+		{"float", "REAL"},
+		{"double precision", "REAL"},
+		{"real", "REAL"},
+		{"decimal(10,2)", "NUMERIC"},
+		{"numeric", "NUMERIC"},
+		{"datetime", "TEXT"},
+		{"", "TEXT"},
+	}
+	for _, test := range tests {
+		if got := SQLiteAffinity(test.declared); got != test.want {
+			t.Errorf("SQLiteAffinity(%q) = %q, want %q", test.declared, got, test.want)
+		}
+	}
+}
+
+func TestSQLite(t *testing.T) {
+	format, _ := Lookup("sqlite")
+	columns := []extractor.Column{
+		{Name: "id", Type: "int(10) unsigned"},
+		{Name: "name", Type: "varchar(255)"},
+		{Name: "balance", Type: "decimal(10,2)"},
+		{Name: "score", Type: "double"},
+		{Name: "hash", Type: "varbinary(4)"},
+	}
+	out := write(t, format, columns,
+		extractor.Record{{Value: "1"}, {Value: "007"}, {Value: "12.50"}, {Value: "0.5"}, {Value: "0x41420043"}},
+		extractor.Record{{Value: "2"}, {Value: "NULL", Null: true}, {Value: "3"}, {Value: "x"}, {Value: "abc"}},
+	)
+	name := filepath.Join(t.TempDir(), "out.db")
+	if err := os.WriteFile(name, []byte(out), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var types []string
+	rows, err := db.Query(`SELECT type FROM pragma_table_info('users') ORDER BY cid`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var typ string
+		rows.Scan(&typ)
+		types = append(types, typ)
+	}
+	rows.Close()
+	if want := []string{"INTEGER", "TEXT", "NUMERIC", "REAL", "BLOB"}; !slices.Equal(types, want) {
+		t.Errorf("got column types %q, want %q", types, want)
+	}
+
+	// Values take the storage class of their column's affinity where they can.
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{`SELECT typeof(id) || ' ' || id FROM users ORDER BY rowid`, []string{"integer 1", "integer 2"}},
+		{`SELECT typeof(name) || ' ' || coalesce(name, '') FROM users ORDER BY rowid`, []string{"text 007", "null "}},
+		{`SELECT typeof(balance) || ' ' || balance FROM users ORDER BY rowid`, []string{"real 12.5", "integer 3"}},
+		{`SELECT typeof(score) || ' ' || score FROM users ORDER BY rowid`, []string{"real 0.5", "text x"}},
+		{`SELECT typeof(hash) || ' ' || hex(hash) FROM users ORDER BY rowid`, []string{"blob 41420043", "blob 616263"}},
+	}
+	for _, test := range tests {
+		var got []string
+		rows, err := db.Query(test.query)
+		if err != nil {
+			t.Fatalf("%s: %v", test.query, err)
+		}
+		for rows.Next() {
+			var value string
+			rows.Scan(&value)
+			got = append(got, value)
+		}
+		rows.Close()
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%s = %q, want %q", test.query, got, test.want)
+		}
+	}
+}
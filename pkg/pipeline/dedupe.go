@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+
+	_ "modernc.org/sqlite"
+)
+
+// dedupeSet holds the hashes of the records kept so far, in memory or, on disk, in a SQLite
+// database created once the first record is added.
+type dedupeSet struct {
+	seen map[[16]byte]struct{}
+
+	onDisk bool
+	dir    string
+	db     *sql.DB
+	tx     *sql.Tx
+	insert *sql.Stmt
+}
+
+// AddDedupe appends a stage dropping the records whose values equal those of a record kept
+// before. The hashes of those are kept in memory or, if onDisk is set, in a temporary file, for
+// tables with more distinct rows than memory holds. The file is removed once p is closed.
+func (p *Pipeline) AddDedupe(onDisk bool) {
+	s := &dedupeSet{seen: make(map[[16]byte]struct{}), onDisk: onDisk}
+	p.Add("dedupe", Stage{Reason: "duplicate", Apply: func(record extractor.Record) (extractor.Record, bool, error) {
+		added, err := s.add(record.Hash())
+		if err != nil {
+			return nil, false, fmt.Errorf("Error keeping the rows written for deduplication: %s", err)
+		}
+		return record, added, nil
+	}})
+	p.closers = append(p.closers, s.close)
+}
+
+// add adds a key to the set, reporting whether it wasn't in it already.
+func (s *dedupeSet) add(key [16]byte) (bool, error) {
+	if !s.onDisk {
+		if _, ok := s.seen[key]; ok {
+			return false, nil
+		}
+		s.seen[key] = struct{}{}
+		return true, nil
+	}
+	if s.db == nil {
+		if err := s.open(); err != nil {
+			return false, err
+		}
+	}
+	result, err := s.insert.Exec(key[:])
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+func (s *dedupeSet) open() error {
+	dir, err := os.MkdirTemp("", "sql-data-extractor-*")
+	if err != nil {
+		return err
+	}
+	s.dir = dir
+	if s.db, err = sql.Open("sqlite", filepath.Join(dir, "dedupe.db")); err != nil {
+		return err
+	}
+	s.db.SetMaxOpenConns(1)
+	// The database is thrown away at the end, and can't outlive a crash.
+	for _, pragma := range []string{"PRAGMA journal_mode = OFF", "PRAGMA synchronous = OFF", "PRAGMA cache_size = -65536"} {
+		if _, err := s.db.Exec(pragma); err != nil {
+			return err
+		}
+	}
+	if _, err := s.db.Exec("CREATE TABLE seen (key BLOB PRIMARY KEY) WITHOUT ROWID"); err != nil {
+		return err
+	}
+	if s.tx, err = s.db.Begin(); err != nil {
+		return err
+	}
+	s.insert, err = s.tx.Prepare("INSERT OR IGNORE INTO seen VALUES (?)")
+	return err
+}
+
+// close deletes the database of the set, if it has one.
+func (s *dedupeSet) close() {
+	if s.insert != nil {
+		s.insert.Close()
+	}
+	if s.tx != nil {
+		s.tx.Rollback()
+	}
+	if s.db != nil {
+		s.db.Close()
+	}
+	if s.dir != "" {
+		os.RemoveAll(s.dir)
+	}
+}
@@ -0,0 +1,24 @@
+package pipeline
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	dump := "CREATE TABLE `users` (\n  `a` varchar(255),\n  `b` varchar(255)\n);\n" +
+		"INSERT INTO `users` VALUES ('a,b','c'),('a','b,c'),('a,b','c'),('ab','c'),(NULL,'x'),('NULL','x'),(NULL,'x'),('',''),('','');\n"
+	// Values joined alike, and NULL and the text NULL, are told apart.
+	want := []string{"a,b,c", "a,b,c", "ab,c", "NULL,x", "NULL,x", ","}
+	for _, onDisk := range []bool{false, true} {
+		p := testPipeline(t, dump)
+		p.AddDedupe(onDisk)
+		if got := values(t, p); !slices.Equal(got, want) {
+			t.Errorf("on disk %v: got %q, want %q", onDisk, got, want)
+		}
+		if got, want := p.Skipped(), map[string]int{"duplicate": 3}; !maps.Equal(got, want) {
+			t.Errorf("on disk %v: Skipped() = %v, want %v", onDisk, got, want)
+		}
+	}
+}
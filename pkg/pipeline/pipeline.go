@@ -0,0 +1,202 @@
+// Package pipeline passes the records of a table through the stages between the dump and the
+// output: conditions, transforms, deduplication, offsets and limits, counting what each drops.
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// Stage is a step the records of a table go through on their way to the output. Apply returns
+// false to drop the record, which is then counted as skipped for the stage's Reason.
+type Stage struct {
+	Reason string
+	Apply  func(record extractor.Record) (extractor.Record, bool, error)
+}
+
+// Pipeline passes the records of an iterator through stages. It embeds the iterator, whose
+// counters remain available.
+type Pipeline struct {
+	*extractor.Iterator
+	// columns holds the columns of the records once a projection stage selected them, nil for
+	// those of the iterator.
+	columns []extractor.Column
+	stages  []Stage
+	// filters describes the stages for the provenance record.
+	filters []string
+	skipped map[string]int
+	// untransformed counts the values left as they were by a transform they don't apply to, by
+	// column=transform pair.
+	untransformed map[string]int
+	// limit is the number of records kept before the others are dropped, -1 for all of them,
+	// kept the number kept so far, and limited is set once Next stopped reading at the limit.
+	limit   int
+	kept    int
+	limited bool
+	// closers release what stages hold, such as temporary files.
+	closers []func()
+}
+
+// New returns a Pipeline without stages over the records of it.
+func New(it *extractor.Iterator) *Pipeline {
+	return &Pipeline{Iterator: it, skipped: make(map[string]int), untransformed: make(map[string]int), limit: -1}
+}
+
+// Add appends a stage, described by filter in provenance records.
+func (p *Pipeline) Add(filter string, stage Stage) {
+	p.filters = append(p.filters, filter)
+	p.stages = append(p.stages, stage)
+}
+
+// Columns returns the columns of the records Next returns.
+func (p *Pipeline) Columns() []extractor.Column {
+	if p.columns != nil {
+		return p.columns
+	}
+	return p.Iterator.Columns()
+}
+
+// Filters describes the stages, in order, for provenance records.
+func (p *Pipeline) Filters() []string {
+	return p.filters
+}
+
+// Skipped returns the number of records dropped by the stages, by reason.
+func (p *Pipeline) Skipped() map[string]int {
+	return p.skipped
+}
+
+// Untransformed returns the number of values left as they were by a transform they don't apply
+// to, by column=transform pair.
+func (p *Pipeline) Untransformed() map[string]int {
+	return p.untransformed
+}
+
+// CountUntransformed counts a value left as it was by the transform of column=transform pair.
+func (p *Pipeline) CountUntransformed(pair string) {
+	p.untransformed[pair]++
+}
+
+// Limited reports whether Next stopped reading once the limit was reached, leaving rows of the
+// table unread.
+func (p *Pipeline) Limited() bool {
+	return p.limited
+}
+
+// AddProjection appends a stage keeping the named columns of the records, in the order given,
+// as Options.Columns does for the iterator. Columns the table lacks are left out. The stages
+// added before see every column, so that conditions can filter on columns not written.
+func (p *Pipeline) AddProjection(names []string) {
+	var selected []int
+	columns := []extractor.Column{}
+	for _, name := range names {
+		if i := extractor.ColumnIndex(p.Columns(), name); i >= 0 && !slices.Contains(selected, i) {
+			selected = append(selected, i)
+			columns = append(columns, p.Columns()[i])
+		}
+	}
+	p.columns = columns
+	p.stages = append(p.stages, Stage{Apply: func(record extractor.Record) (extractor.Record, bool, error) {
+		projected := make(extractor.Record, len(selected))
+		for i, j := range selected {
+			projected[i] = record[j]
+		}
+		return projected, true, nil
+	}})
+}
+
+// StageError is returned by Pipeline.Next when a stage fails, to tell it apart from failures
+// reading the dump or writing the output.
+type StageError struct {
+	Err error
+}
+
+func (e *StageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// Close releases what the stages hold, once the records are written.
+func (p *Pipeline) Close() {
+	for _, closer := range p.closers {
+		closer()
+	}
+	p.closers = nil
+}
+
+// Next returns the next record making it through every stage. Once the limit is reached, it
+// returns io.EOF without reading further.
+func (p *Pipeline) Next() (extractor.Record, error) {
+	if p.limit >= 0 && p.kept >= p.limit {
+		p.limited = true
+		return nil, io.EOF
+	}
+	for {
+		record, err := p.Iterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if record, keep, err := p.Process(record); err != nil || keep {
+			return record, err
+		}
+	}
+}
+
+// Process passes a record through every stage, returning false if one drops it. It is used
+// instead of Next for records read by an extractor.TablesIterator.
+func (p *Pipeline) Process(record extractor.Record) (extractor.Record, bool, error) {
+	for _, stage := range p.stages {
+		var keep bool
+		var err error
+		if record, keep, err = stage.Apply(record); err != nil {
+			return nil, false, &StageError{err}
+		}
+		if !keep {
+			p.skipped[stage.Reason]++
+			return nil, false, nil
+		}
+	}
+	if p.limit >= 0 && p.kept >= p.limit {
+		p.skipped["limit"]++
+		return nil, false, nil
+	}
+	p.kept++
+	return record, true, nil
+}
+
+// SetLimit keeps at most n records, dropping the others under "limit" when records are passed
+// to Process. Next stops reading at the limit instead.
+func (p *Pipeline) SetLimit(n int) {
+	p.filters = append(p.filters, fmt.Sprintf("limit %d", n))
+	p.limit = n
+}
+
+// AddOffset appends a stage dropping the first n records reaching it.
+func (p *Pipeline) AddOffset(n int) {
+	dropped := 0
+	p.Add(fmt.Sprintf("offset %d", n), Stage{Reason: "offset", Apply: func(record extractor.Record) (extractor.Record, bool, error) {
+		if dropped < n {
+			dropped++
+			return record, false, nil
+		}
+		return record, true, nil
+	}})
+}
+
+// AddSkipEmpty appends a stage dropping the records in which a value is empty or NULL.
+func (p *Pipeline) AddSkipEmpty() {
+	p.Add("skip-empty", Stage{Reason: "empty", Apply: func(record extractor.Record) (extractor.Record, bool, error) {
+		for _, field := range record {
+			if field.Null || field.Value == "" {
+				return record, false, nil
+			}
+		}
+		return record, true, nil
+	}})
+}
@@ -0,0 +1,140 @@
+package pipeline
+
+import (
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+const testDump = "CREATE TABLE `users` (\n  `id` int,\n  `email` varchar(255),\n  `pass` varchar(255)\n);\n" +
+	"INSERT INTO `users` VALUES (1,'a@x.com','5f4d'),(2,'b@x.com',''),(3,'c@x.com',NULL),(4,'d@x.com','e10a');\n"
+
+// testPipeline returns a pipeline over the records of table users of dump.
+func testPipeline(t *testing.T, dump string) *Pipeline {
+	t.Helper()
+	it, err := extractor.New([]byte(dump)).Iterate("users", extractor.Options{})
+	if err != nil {
+		t.Fatalf("Iterate: %v", err)
+	}
+	t.Cleanup(func() { it.Close() })
+	p := New(it)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// values returns the values of the records making it through p, joined by commas.
+func values(t *testing.T, p *Pipeline) []string {
+	t.Helper()
+	var rows []string
+	for {
+		record, err := p.Next()
+		if err == io.EOF {
+			return rows
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		rows = append(rows, strings.Join(record.Values(), ","))
+	}
+}
+
+func TestProjection(t *testing.T) {
+	tests := []struct {
+		name        string
+		columns     []string
+		wantColumns []string
+		want        []string
+	}{
+		{"table order", []string{"id", "email"}, []string{"id", "email"}, []string{"1,a@x.com", "2,b@x.com", "3,c@x.com", "4,d@x.com"}},
+		{"given order", []string{"email", "pass"}, []string{"email", "pass"}, []string{"a@x.com,5f4d", "b@x.com,", "c@x.com,NULL", "d@x.com,e10a"}},
+		{"reversed", []string{"pass", "email", "id"}, []string{"pass", "email", "id"}, []string{"5f4d,a@x.com,1", ",b@x.com,2", "NULL,c@x.com,3", "e10a,d@x.com,4"}},
+		{"missing and repeated columns", []string{"pass", "nope", "pass", "id"}, []string{"pass", "id"}, []string{"5f4d,1", ",2", "NULL,3", "e10a,4"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := testPipeline(t, testDump)
+			p.AddProjection(test.columns)
+			var names []string
+			for _, column := range p.Columns() {
+				names = append(names, column.Name)
+			}
+			if !slices.Equal(names, test.wantColumns) {
+				t.Errorf("got columns %q, want %q", names, test.wantColumns)
+			}
+			if got := values(t, p); !slices.Equal(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSkipEmpty(t *testing.T) {
+	p := testPipeline(t, testDump)
+	p.AddProjection([]string{"email", "pass"})
+	p.AddSkipEmpty()
+	if got, want := values(t, p), []string{"a@x.com,5f4d", "d@x.com,e10a"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := p.Skipped(), map[string]int{"empty": 2}; !maps.Equal(got, want) {
+		t.Errorf("Skipped() = %v, want %v", got, want)
+	}
+}
+
+func TestOffsetLimit(t *testing.T) {
+	all := []string{"1,a@x.com,5f4d", "2,b@x.com,", "3,c@x.com,NULL", "4,d@x.com,e10a"}
+	tests := []struct {
+		name        string
+		offset      int
+		limit       int
+		want        []string
+		wantSkipped map[string]int
+		wantLimited bool
+	}{
+		{"none", 0, -1, all, map[string]int{}, false},
+		{"offset", 1, -1, all[1:], map[string]int{"offset": 1}, false},
+		{"limit", 0, 2, all[:2], map[string]int{}, true},
+		{"offset and limit", 1, 2, all[1:3], map[string]int{"offset": 1}, true},
+		{"limit zero", 0, 0, nil, map[string]int{}, true},
+		{"limit past the end", 2, 10, all[2:], map[string]int{"offset": 2}, false},
+		{"offset past the end", 10, -1, nil, map[string]int{"offset": 4}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := testPipeline(t, testDump)
+			if test.offset > 0 {
+				p.AddOffset(test.offset)
+			}
+			if test.limit >= 0 {
+				p.SetLimit(test.limit)
+			}
+			if got := values(t, p); !slices.Equal(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+			if got := p.Skipped(); !maps.Equal(got, test.wantSkipped) {
+				t.Errorf("Skipped() = %v, want %v", got, test.wantSkipped)
+			}
+			if p.Limited() != test.wantLimited {
+				t.Errorf("Limited() = %v, want %v", p.Limited(), test.wantLimited)
+			}
+		})
+	}
+}
+
+func TestProcessLimit(t *testing.T) {
+	// Records passed to Process past the limit are dropped and counted.
+	p := testPipeline(t, testDump)
+	p.SetLimit(1)
+	record := extractor.Record{{Name: "id", Value: "1"}}
+	for i, want := range []bool{true, false, false} {
+		if _, keep, err := p.Process(record); keep != want || err != nil {
+			t.Errorf("Process #%d = %v, %v, want %v, nil", i+1, keep, err, want)
+		}
+	}
+	if got, want := p.Skipped(), map[string]int{"limit": 2}; !maps.Equal(got, want) {
+		t.Errorf("Skipped() = %v, want %v", got, want)
+	}
+}
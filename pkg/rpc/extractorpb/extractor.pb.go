@@ -0,0 +1,639 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: pkg/rpc/extractorpb/extractor.proto
+
+package extractorpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Dump identifies a dump file below the server's data directory.
+type Dump struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Path of the dump, relative to the data directory.
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// SQL dialect of the dump. Defaults to mysql.
+	Dialect       string `protobuf:"bytes,2,opt,name=dialect,proto3" json:"dialect,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Dump) Reset() {
+	*x = Dump{}
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Dump) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Dump) ProtoMessage() {}
+
+func (x *Dump) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Dump.ProtoReflect.Descriptor instead.
+func (*Dump) Descriptor() ([]byte, []int) {
+	return file_pkg_rpc_extractorpb_extractor_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Dump) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Dump) GetDialect() string {
+	if x != nil {
+		return x.Dialect
+	}
+	return ""
+}
+
+type ListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Dump          *Dump                  `protobuf:"bytes,1,opt,name=dump,proto3" json:"dump,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_rpc_extractorpb_extractor_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListRequest) GetDump() *Dump {
+	if x != nil {
+		return x.Dump
+	}
+	return nil
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tables        []string               `protobuf:"bytes,1,rep,name=tables,proto3" json:"tables,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_rpc_extractorpb_extractor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListResponse) GetTables() []string {
+	if x != nil {
+		return x.Tables
+	}
+	return nil
+}
+
+type SchemaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Dump          *Dump                  `protobuf:"bytes,1,opt,name=dump,proto3" json:"dump,omitempty"`
+	Table         string                 `protobuf:"bytes,2,opt,name=table,proto3" json:"table,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SchemaRequest) Reset() {
+	*x = SchemaRequest{}
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SchemaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SchemaRequest) ProtoMessage() {}
+
+func (x *SchemaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SchemaRequest.ProtoReflect.Descriptor instead.
+func (*SchemaRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_rpc_extractorpb_extractor_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SchemaRequest) GetDump() *Dump {
+	if x != nil {
+		return x.Dump
+	}
+	return nil
+}
+
+func (x *SchemaRequest) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+type SchemaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tables        []*Table               `protobuf:"bytes,1,rep,name=tables,proto3" json:"tables,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SchemaResponse) Reset() {
+	*x = SchemaResponse{}
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SchemaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SchemaResponse) ProtoMessage() {}
+
+func (x *SchemaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SchemaResponse.ProtoReflect.Descriptor instead.
+func (*SchemaResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_rpc_extractorpb_extractor_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SchemaResponse) GetTables() []*Table {
+	if x != nil {
+		return x.Tables
+	}
+	return nil
+}
+
+type Table struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Columns       []*Column              `protobuf:"bytes,2,rep,name=columns,proto3" json:"columns,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Table) Reset() {
+	*x = Table{}
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Table) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Table) ProtoMessage() {}
+
+func (x *Table) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Table.ProtoReflect.Descriptor instead.
+func (*Table) Descriptor() ([]byte, []int) {
+	return file_pkg_rpc_extractorpb_extractor_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Table) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Table) GetColumns() []*Column {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+type Column struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Declared SQL type, e.g. varchar(255).
+	Type          string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Column) Reset() {
+	*x = Column{}
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Column) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Column) ProtoMessage() {}
+
+func (x *Column) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Column.ProtoReflect.Descriptor instead.
+func (*Column) Descriptor() ([]byte, []int) {
+	return file_pkg_rpc_extractorpb_extractor_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Column) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Column) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type ExtractRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Dump  *Dump                  `protobuf:"bytes,1,opt,name=dump,proto3" json:"dump,omitempty"`
+	Table string                 `protobuf:"bytes,2,opt,name=table,proto3" json:"table,omitempty"`
+	// Columns to include. All columns are returned when empty.
+	Columns       []string `protobuf:"bytes,3,rep,name=columns,proto3" json:"columns,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractRequest) Reset() {
+	*x = ExtractRequest{}
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractRequest) ProtoMessage() {}
+
+func (x *ExtractRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractRequest.ProtoReflect.Descriptor instead.
+func (*ExtractRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_rpc_extractorpb_extractor_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ExtractRequest) GetDump() *Dump {
+	if x != nil {
+		return x.Dump
+	}
+	return nil
+}
+
+func (x *ExtractRequest) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+func (x *ExtractRequest) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+type Record struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fields        []*Field               `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Record) Reset() {
+	*x = Record{}
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Record) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Record) ProtoMessage() {}
+
+func (x *Record) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Record.ProtoReflect.Descriptor instead.
+func (*Record) Descriptor() ([]byte, []int) {
+	return file_pkg_rpc_extractorpb_extractor_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Record) GetFields() []*Field {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type Field struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Set for SQL NULL, whose value is empty, to tell it from the empty string.
+	Null          bool `protobuf:"varint,3,opt,name=null,proto3" json:"null,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Field) Reset() {
+	*x = Field{}
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Field) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Field) ProtoMessage() {}
+
+func (x *Field) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_rpc_extractorpb_extractor_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Field.ProtoReflect.Descriptor instead.
+func (*Field) Descriptor() ([]byte, []int) {
+	return file_pkg_rpc_extractorpb_extractor_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Field) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Field) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Field) GetNull() bool {
+	if x != nil {
+		return x.Null
+	}
+	return false
+}
+
+var File_pkg_rpc_extractorpb_extractor_proto protoreflect.FileDescriptor
+
+const file_pkg_rpc_extractorpb_extractor_proto_rawDesc = "" +
+	"\n" +
+	"#pkg/rpc/extractorpb/extractor.proto\x12\x13sqldataextractor.v1\"4\n" +
+	"\x04Dump\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\adialect\x18\x02 \x01(\tR\adialect\"<\n" +
+	"\vListRequest\x12-\n" +
+	"\x04dump\x18\x01 \x01(\v2\x19.sqldataextractor.v1.DumpR\x04dump\"&\n" +
+	"\fListResponse\x12\x16\n" +
+	"\x06tables\x18\x01 \x03(\tR\x06tables\"T\n" +
+	"\rSchemaRequest\x12-\n" +
+	"\x04dump\x18\x01 \x01(\v2\x19.sqldataextractor.v1.DumpR\x04dump\x12\x14\n" +
+	"\x05table\x18\x02 \x01(\tR\x05table\"D\n" +
+	"\x0eSchemaResponse\x122\n" +
+	"\x06tables\x18\x01 \x03(\v2\x1a.sqldataextractor.v1.TableR\x06tables\"R\n" +
+	"\x05Table\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x125\n" +
+	"\acolumns\x18\x02 \x03(\v2\x1b.sqldataextractor.v1.ColumnR\acolumns\"0\n" +
+	"\x06Column\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\"o\n" +
+	"\x0eExtractRequest\x12-\n" +
+	"\x04dump\x18\x01 \x01(\v2\x19.sqldataextractor.v1.DumpR\x04dump\x12\x14\n" +
+	"\x05table\x18\x02 \x01(\tR\x05table\x12\x18\n" +
+	"\acolumns\x18\x03 \x03(\tR\acolumns\"<\n" +
+	"\x06Record\x122\n" +
+	"\x06fields\x18\x01 \x03(\v2\x1a.sqldataextractor.v1.FieldR\x06fields\"E\n" +
+	"\x05Field\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x12\n" +
+	"\x04null\x18\x03 \x01(\bR\x04null2\xfa\x01\n" +
+	"\tExtractor\x12K\n" +
+	"\x04List\x12 .sqldataextractor.v1.ListRequest\x1a!.sqldataextractor.v1.ListResponse\x12Q\n" +
+	"\x06Schema\x12\".sqldataextractor.v1.SchemaRequest\x1a#.sqldataextractor.v1.SchemaResponse\x12M\n" +
+	"\aExtract\x12#.sqldataextractor.v1.ExtractRequest\x1a\x1b.sqldataextractor.v1.Record0\x01B>Z<github.com/elvisgraho/sql-data-extractor/pkg/rpc/extractorpbb\x06proto3"
+
+var (
+	file_pkg_rpc_extractorpb_extractor_proto_rawDescOnce sync.Once
+	file_pkg_rpc_extractorpb_extractor_proto_rawDescData []byte
+)
+
+func file_pkg_rpc_extractorpb_extractor_proto_rawDescGZIP() []byte {
+	file_pkg_rpc_extractorpb_extractor_proto_rawDescOnce.Do(func() {
+		file_pkg_rpc_extractorpb_extractor_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pkg_rpc_extractorpb_extractor_proto_rawDesc), len(file_pkg_rpc_extractorpb_extractor_proto_rawDesc)))
+	})
+	return file_pkg_rpc_extractorpb_extractor_proto_rawDescData
+}
+
+var file_pkg_rpc_extractorpb_extractor_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_pkg_rpc_extractorpb_extractor_proto_goTypes = []any{
+	(*Dump)(nil),           // 0: sqldataextractor.v1.Dump
+	(*ListRequest)(nil),    // 1: sqldataextractor.v1.ListRequest
+	(*ListResponse)(nil),   // 2: sqldataextractor.v1.ListResponse
+	(*SchemaRequest)(nil),  // 3: sqldataextractor.v1.SchemaRequest
+	(*SchemaResponse)(nil), // 4: sqldataextractor.v1.SchemaResponse
+	(*Table)(nil),          // 5: sqldataextractor.v1.Table
+	(*Column)(nil),         // 6: sqldataextractor.v1.Column
+	(*ExtractRequest)(nil), // 7: sqldataextractor.v1.ExtractRequest
+	(*Record)(nil),         // 8: sqldataextractor.v1.Record
+	(*Field)(nil),          // 9: sqldataextractor.v1.Field
+}
+var file_pkg_rpc_extractorpb_extractor_proto_depIdxs = []int32{
+	0, // 0: sqldataextractor.v1.ListRequest.dump:type_name -> sqldataextractor.v1.Dump
+	0, // 1: sqldataextractor.v1.SchemaRequest.dump:type_name -> sqldataextractor.v1.Dump
+	5, // 2: sqldataextractor.v1.SchemaResponse.tables:type_name -> sqldataextractor.v1.Table
+	6, // 3: sqldataextractor.v1.Table.columns:type_name -> sqldataextractor.v1.Column
+	0, // 4: sqldataextractor.v1.ExtractRequest.dump:type_name -> sqldataextractor.v1.Dump
+	9, // 5: sqldataextractor.v1.Record.fields:type_name -> sqldataextractor.v1.Field
+	1, // 6: sqldataextractor.v1.Extractor.List:input_type -> sqldataextractor.v1.ListRequest
+	3, // 7: sqldataextractor.v1.Extractor.Schema:input_type -> sqldataextractor.v1.SchemaRequest
+	7, // 8: sqldataextractor.v1.Extractor.Extract:input_type -> sqldataextractor.v1.ExtractRequest
+	2, // 9: sqldataextractor.v1.Extractor.List:output_type -> sqldataextractor.v1.ListResponse
+	4, // 10: sqldataextractor.v1.Extractor.Schema:output_type -> sqldataextractor.v1.SchemaResponse
+	8, // 11: sqldataextractor.v1.Extractor.Extract:output_type -> sqldataextractor.v1.Record
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_pkg_rpc_extractorpb_extractor_proto_init() }
+func file_pkg_rpc_extractorpb_extractor_proto_init() {
+	if File_pkg_rpc_extractorpb_extractor_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_rpc_extractorpb_extractor_proto_rawDesc), len(file_pkg_rpc_extractorpb_extractor_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pkg_rpc_extractorpb_extractor_proto_goTypes,
+		DependencyIndexes: file_pkg_rpc_extractorpb_extractor_proto_depIdxs,
+		MessageInfos:      file_pkg_rpc_extractorpb_extractor_proto_msgTypes,
+	}.Build()
+	File_pkg_rpc_extractorpb_extractor_proto = out.File
+	file_pkg_rpc_extractorpb_extractor_proto_goTypes = nil
+	file_pkg_rpc_extractorpb_extractor_proto_depIdxs = nil
+}
@@ -0,0 +1,211 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: pkg/rpc/extractorpb/extractor.proto
+
+package extractorpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Extractor_List_FullMethodName    = "/sqldataextractor.v1.Extractor/List"
+	Extractor_Schema_FullMethodName  = "/sqldataextractor.v1.Extractor/Schema"
+	Extractor_Extract_FullMethodName = "/sqldataextractor.v1.Extractor/Extract"
+)
+
+// ExtractorClient is the client API for Extractor service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Extractor lists, describes and extracts the tables of SQL dumps stored on the server.
+type ExtractorClient interface {
+	// List returns the names of the tables created in a dump.
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	// Schema returns the column definitions of one table, or of every table when none is given.
+	Schema(ctx context.Context, in *SchemaRequest, opts ...grpc.CallOption) (*SchemaResponse, error)
+	// Extract streams the rows of a table.
+	Extract(ctx context.Context, in *ExtractRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Record], error)
+}
+
+type extractorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewExtractorClient(cc grpc.ClientConnInterface) ExtractorClient {
+	return &extractorClient{cc}
+}
+
+func (c *extractorClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, Extractor_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *extractorClient) Schema(ctx context.Context, in *SchemaRequest, opts ...grpc.CallOption) (*SchemaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SchemaResponse)
+	err := c.cc.Invoke(ctx, Extractor_Schema_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *extractorClient) Extract(ctx context.Context, in *ExtractRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Record], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Extractor_ServiceDesc.Streams[0], Extractor_Extract_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExtractRequest, Record]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Extractor_ExtractClient = grpc.ServerStreamingClient[Record]
+
+// ExtractorServer is the server API for Extractor service.
+// All implementations must embed UnimplementedExtractorServer
+// for forward compatibility.
+//
+// Extractor lists, describes and extracts the tables of SQL dumps stored on the server.
+type ExtractorServer interface {
+	// List returns the names of the tables created in a dump.
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	// Schema returns the column definitions of one table, or of every table when none is given.
+	Schema(context.Context, *SchemaRequest) (*SchemaResponse, error)
+	// Extract streams the rows of a table.
+	Extract(*ExtractRequest, grpc.ServerStreamingServer[Record]) error
+	mustEmbedUnimplementedExtractorServer()
+}
+
+// UnimplementedExtractorServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedExtractorServer struct{}
+
+func (UnimplementedExtractorServer) List(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedExtractorServer) Schema(context.Context, *SchemaRequest) (*SchemaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Schema not implemented")
+}
+func (UnimplementedExtractorServer) Extract(*ExtractRequest, grpc.ServerStreamingServer[Record]) error {
+	return status.Errorf(codes.Unimplemented, "method Extract not implemented")
+}
+func (UnimplementedExtractorServer) mustEmbedUnimplementedExtractorServer() {}
+func (UnimplementedExtractorServer) testEmbeddedByValue()                   {}
+
+// UnsafeExtractorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ExtractorServer will
+// result in compilation errors.
+type UnsafeExtractorServer interface {
+	mustEmbedUnimplementedExtractorServer()
+}
+
+func RegisterExtractorServer(s grpc.ServiceRegistrar, srv ExtractorServer) {
+	// If the following call pancis, it indicates UnimplementedExtractorServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Extractor_ServiceDesc, srv)
+}
+
+func _Extractor_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExtractorServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Extractor_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExtractorServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Extractor_Schema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExtractorServer).Schema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Extractor_Schema_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExtractorServer).Schema(ctx, req.(*SchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Extractor_Extract_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExtractRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExtractorServer).Extract(m, &grpc.GenericServerStream[ExtractRequest, Record]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Extractor_ExtractServer = grpc.ServerStreamingServer[Record]
+
+// Extractor_ServiceDesc is the grpc.ServiceDesc for Extractor service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Extractor_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sqldataextractor.v1.Extractor",
+	HandlerType: (*ExtractorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "List",
+			Handler:    _Extractor_List_Handler,
+		},
+		{
+			MethodName: "Schema",
+			Handler:    _Extractor_Schema_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Extract",
+			Handler:       _Extractor_Extract_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/rpc/extractorpb/extractor.proto",
+}
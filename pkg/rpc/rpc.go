@@ -0,0 +1,155 @@
+// Package rpc implements the Extractor gRPC service defined in extractorpb/extractor.proto.
+//
+// Dumps are referenced by path relative to the server's data directory, so the service suits
+// orchestration from other services sharing the same storage.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/metrics"
+	"github.com/elvisgraho/sql-data-extractor/pkg/rpc/extractorpb"
+)
+
+// Service serves the Extractor gRPC API for dumps below a data directory.
+type Service struct {
+	extractorpb.UnimplementedExtractorServer
+
+	dataDir string
+}
+
+// NewService creates a Service reading dumps from dataDir.
+func NewService(dataDir string) *Service {
+	return &Service{dataDir: dataDir}
+}
+
+// NewServer creates a gRPC server with the Extractor service registered.
+func NewServer(dataDir string, opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(opts...)
+	extractorpb.RegisterExtractorServer(server, NewService(dataDir))
+	return server
+}
+
+func (s *Service) List(ctx context.Context, req *extractorpb.ListRequest) (*extractorpb.ListResponse, error) {
+	dump, err := s.open(req.GetDump())
+	if err != nil {
+		return nil, err
+	}
+	tables, err := dump.Tables()
+	if err != nil {
+		return nil, status.Error(codes.DataLoss, err.Error())
+	}
+	return &extractorpb.ListResponse{Tables: tables}, nil
+}
+
+func (s *Service) Schema(ctx context.Context, req *extractorpb.SchemaRequest) (*extractorpb.SchemaResponse, error) {
+	dump, err := s.open(req.GetDump())
+	if err != nil {
+		return nil, err
+	}
+
+	var schemas []*extractor.TableSchema
+	if req.GetTable() == "" {
+		if schemas, err = dump.Schemas(); err != nil {
+			return nil, status.Error(codes.DataLoss, err.Error())
+		}
+	} else {
+		schema, err := dump.Schema(req.GetTable())
+		if err != nil {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		schemas = append(schemas, schema)
+	}
+
+	resp := &extractorpb.SchemaResponse{}
+	for _, schema := range schemas {
+		table := &extractorpb.Table{Name: schema.Name}
+		for _, column := range schema.Columns {
+			table.Columns = append(table.Columns, &extractorpb.Column{Name: column.Name, Type: column.Type})
+		}
+		resp.Tables = append(resp.Tables, table)
+	}
+	return resp, nil
+}
+
+func (s *Service) Extract(req *extractorpb.ExtractRequest, stream grpc.ServerStreamingServer[extractorpb.Record]) error {
+	dump, err := s.open(req.GetDump())
+	if err != nil {
+		return err
+	}
+
+	it, err := dump.Iterate(req.GetTable(), extractor.Options{Columns: req.GetColumns()})
+	if err != nil {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	defer it.Close()
+
+	start := time.Now()
+	defer func() {
+		metrics.RowsExtracted.Add(float64(it.Rows()))
+		metrics.ParseErrors.Add(float64(it.Malformed()), "malformed_row")
+		metrics.ExtractionDuration.ObserveSince(start)
+	}()
+	for {
+		record, err := it.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return status.Error(codes.DataLoss, err.Error())
+		}
+
+		msg := &extractorpb.Record{Fields: make([]*extractorpb.Field, len(record))}
+		for i, field := range record {
+			msg.Fields[i] = &extractorpb.Field{Name: field.Name, Value: field.Value, Null: field.Null}
+			if field.Null {
+				msg.Fields[i].Value = ""
+			}
+		}
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// open loads the referenced dump, translating failures into gRPC status errors.
+func (s *Service) open(ref *extractorpb.Dump) (*extractor.Dump, error) {
+	if ref.GetPath() == "" {
+		return nil, status.Error(codes.InvalidArgument, "dump path is required")
+	}
+	filename, err := extractor.ResolveWithin(s.dataDir, ref.GetPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	dialectName := ref.GetDialect()
+	if dialectName == "" {
+		dialectName = "mysql"
+	}
+	if dialectName != extractor.AutoDetect {
+		if _, err := extractor.LookupDialect(dialectName); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	dump, err := extractor.Open(filename)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err := dump.SetDialect(dialectName); err != nil {
+		return nil, status.Error(codes.DataLoss, err.Error())
+	}
+	return dump, nil
+}
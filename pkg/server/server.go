@@ -0,0 +1,468 @@
+// Package server exposes the extractor over an HTTP API.
+//
+// Dumps are uploaded (or referenced from a directory on the server), after which their tables
+// can be listed and extracted:
+//
+//	POST   /api/dumps?name=dump.sql         upload the request body as a dump
+//	POST   /api/dumps?path=backups/dump.sql reference a dump below the data directory
+//	GET    /api/dumps                        list loaded dumps
+//	GET    /api/dumps/{id}                   describe a dump and its tables
+//	DELETE /api/dumps/{id}                   forget a dump
+//	GET    /api/dumps/{id}/tables            list tables and their columns
+//	GET    /api/dumps/{id}/tables/{table}/extract?columns=a,b&format=json
+//	GET    /api/dumps/{id}/tables/{table}/preview?columns=a,b&rows=20
+//	GET    /api/formats                      list output formats and dialects
+//	GET    /metrics                          metrics in the Prometheus text format
+//
+// Both POST forms accept a dialect parameter. Uploads are spooled to temporary files, and dumps
+// are read from their files, decompressed if needed, on every request. Extractions are streamed
+// in the requested format.
+//
+// Every other path serves the embedded web UI, which drives the same API from a browser.
+package server
+
+import (
+	"bufio"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/metrics"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+)
+
+//go:embed ui
+var uiFiles embed.FS
+
+// Number of rows returned by the preview endpoint unless the request asks for another amount.
+const defaultPreviewRows = 20
+
+// Config configures a Server.
+type Config struct {
+	// DataDir is the directory dumps may be referenced from by path. When empty, dumps can only be uploaded.
+	DataDir string
+	// MaxUploadSize limits the size of uploaded dumps in bytes. Zero means no limit. Uploads are
+	// kept in temporary files until their dump is deleted or the server closed.
+	MaxUploadSize int64
+}
+
+// Server is an http.Handler serving the extraction API.
+type Server struct {
+	config Config
+	mux    *http.ServeMux
+
+	mu    sync.RWMutex
+	dumps map[string]*dumpEntry
+}
+
+type dumpEntry struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Dialect  string    `json:"dialect"`
+	Size     int64     `json:"size"`
+	LoadedAt time.Time `json:"loadedAt"`
+
+	dump *extractor.Dump
+	// spool is the temporary file an uploaded dump is read from, empty for referenced dumps.
+	spool string
+}
+
+type tableInfo struct {
+	Name    string       `json:"name"`
+	Columns []columnInfo `json:"columns"`
+}
+
+type columnInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// New creates a Server with the given configuration.
+func New(config Config) *Server {
+	s := &Server{
+		config: config,
+		mux:    http.NewServeMux(),
+		dumps:  make(map[string]*dumpEntry),
+	}
+	s.mux.HandleFunc("POST /api/dumps", s.handleCreateDump)
+	s.mux.HandleFunc("GET /api/dumps", s.handleListDumps)
+	s.mux.HandleFunc("GET /api/dumps/{id}", s.handleGetDump)
+	s.mux.HandleFunc("DELETE /api/dumps/{id}", s.handleDeleteDump)
+	s.mux.HandleFunc("GET /api/dumps/{id}/tables", s.handleListTables)
+	s.mux.HandleFunc("GET /api/dumps/{id}/tables/{table}/extract", s.handleExtract)
+	s.mux.HandleFunc("GET /api/dumps/{id}/tables/{table}/preview", s.handlePreview)
+	s.mux.HandleFunc("GET /api/formats", s.handleFormats)
+	s.mux.Handle("GET /metrics", metrics.Handler())
+
+	ui, _ := fs.Sub(uiFiles, "ui")
+	s.mux.Handle("GET /", http.FileServerFS(ui))
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleCreateDump(w http.ResponseWriter, r *http.Request) {
+	dialectName := queryDefault(r, "dialect", "mysql")
+	if dialectName != extractor.AutoDetect {
+		if _, err := extractor.LookupDialect(dialectName); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	start := time.Now()
+	var name, spool string
+	var source func() (io.ReadCloser, error)
+	var size int64
+	var err error
+	if path := r.URL.Query().Get("path"); path != "" {
+		name = filepath.Base(path)
+		source, size, err = s.openReferencedDump(path)
+		if err != nil {
+			metrics.FilesProcessed.Inc("failed")
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	} else {
+		name = queryDefault(r, "name", "upload.sql")
+		body := r.Body
+		if s.config.MaxUploadSize > 0 {
+			body = http.MaxBytesReader(w, r.Body, s.config.MaxUploadSize)
+		}
+		spool, size, err = spoolUpload(body)
+		if err != nil {
+			metrics.FilesProcessed.Inc("failed")
+			writeError(w, http.StatusBadRequest, fmt.Errorf("error reading upload: %w", err))
+			return
+		}
+		source = extractor.Decompress(name, func() (io.ReadCloser, error) { return os.Open(spool) })
+	}
+
+	id, err := newID()
+	if err != nil {
+		removeSpool(spool)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	dump := extractor.NewSource(source)
+	if err := dump.SetDialect(dialectName); err != nil {
+		removeSpool(spool)
+		metrics.FilesProcessed.Inc("failed")
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	entry := &dumpEntry{
+		ID:       id,
+		Name:     name,
+		Dialect:  dump.Dialect.Name(),
+		Size:     size,
+		LoadedAt: time.Now().UTC(),
+		dump:     dump,
+		spool:    spool,
+	}
+
+	s.mu.Lock()
+	s.dumps[id] = entry
+	s.mu.Unlock()
+	metrics.FilesProcessed.Inc("ok")
+	metrics.FileDuration.ObserveSince(start)
+
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+// openReferencedDump returns the opener and size of a dump below the configured data directory,
+// which is read from the file, and decompressed as extractor.Open does, on every pass. Symbolic
+// links are followed only as long as they stay in the data directory.
+func (s *Server) openReferencedDump(path string) (func() (io.ReadCloser, error), int64, error) {
+	if s.config.DataDir == "" {
+		return nil, 0, fmt.Errorf("referencing dumps by path is disabled, start the server with -data-dir")
+	}
+	filename, err := extractor.ResolveWithin(s.config.DataDir, path)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, 0, fmt.Errorf("%s is not a file", path)
+	}
+	return extractor.Decompress(filename, func() (io.ReadCloser, error) { return os.Open(filename) }), info.Size(), nil
+}
+
+// spoolUpload copies an uploaded dump to a temporary file, which the dump is then read from
+// instead of being held in memory. It returns the path of the file and the size of the upload.
+func spoolUpload(body io.Reader) (string, int64, error) {
+	file, err := os.CreateTemp("", "sql-data-extractor-upload-*")
+	if err != nil {
+		return "", 0, err
+	}
+	size, err := io.Copy(file, body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", 0, err
+	}
+	return file.Name(), size, nil
+}
+
+// removeSpool removes the temporary file of an upload, if the dump has one.
+func removeSpool(spool string) {
+	if spool == "" {
+		return
+	}
+	if err := os.Remove(spool); err != nil && !os.IsNotExist(err) {
+		slog.Warn("error removing uploaded dump", "path", spool, "error", err)
+	}
+}
+
+// Close forgets every dump, removing the temporary files of uploads.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, entry := range s.dumps {
+		removeSpool(entry.spool)
+		delete(s.dumps, id)
+	}
+	return nil
+}
+
+func (s *Server) handleListDumps(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	entries := make([]*dumpEntry, 0, len(s.dumps))
+	for _, entry := range s.dumps {
+		entries = append(entries, entry)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LoadedAt.Before(entries[j].LoadedAt)
+	})
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (s *Server) handleGetDump(w http.ResponseWriter, r *http.Request) {
+	entry, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	tables, err := entry.dump.Tables()
+	if err != nil {
+		writeError(w, dumpErrorStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		*dumpEntry
+		Tables []string `json:"tables"`
+	}{entry, tables})
+}
+
+func (s *Server) handleDeleteDump(w http.ResponseWriter, r *http.Request) {
+	entry, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	delete(s.dumps, entry.ID)
+	s.mu.Unlock()
+	removeSpool(entry.spool)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleListTables(w http.ResponseWriter, r *http.Request) {
+	entry, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	schemas, err := entry.dump.Schemas()
+	if err != nil {
+		writeError(w, dumpErrorStatus(err), err)
+		return
+	}
+	tables := []tableInfo{}
+	for _, schema := range schemas {
+		table := tableInfo{Name: schema.Name, Columns: []columnInfo{}}
+		for _, column := range schema.Columns {
+			table.Columns = append(table.Columns, columnInfo{Name: column.Name, Type: column.Type})
+		}
+		tables = append(tables, table)
+	}
+	writeJSON(w, http.StatusOK, tables)
+}
+
+func (s *Server) handleExtract(w http.ResponseWriter, r *http.Request) {
+	entry, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	format, err := output.Lookup(queryDefault(r, "format", "json"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tableName := r.PathValue("table")
+	it, err := entry.dump.Iterate(tableName, extractor.Options{Columns: queryColumns(r)})
+	if err != nil {
+		writeError(w, dumpErrorStatus(err), err)
+		return
+	}
+	defer it.Close()
+
+	w.Header().Set("Content-Type", format.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", tableName+format.Extension))
+
+	start := time.Now()
+	defer func() {
+		metrics.RowsExtracted.Add(float64(it.Rows()))
+		metrics.ParseErrors.Add(float64(it.Malformed()), "malformed_row")
+		metrics.ExtractionDuration.ObserveSince(start)
+	}()
+
+	// Once streaming has started the status can no longer change, so errors only end the response early.
+	buffered := bufio.NewWriter(w)
+	if _, err := output.Copy(format.New(buffered), tableName, it); err != nil {
+		slog.Error("extraction aborted", "dump", entry.ID, "table", tableName, "error", err)
+		return
+	}
+	buffered.Flush()
+}
+
+func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
+	entry, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	rows := defaultPreviewRows
+	if rowsParam := r.URL.Query().Get("rows"); rowsParam != "" {
+		var err error
+		rows, err = strconv.Atoi(rowsParam)
+		if err != nil || rows < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid rows parameter %s", rowsParam))
+			return
+		}
+	}
+
+	it, err := entry.dump.Iterate(r.PathValue("table"), extractor.Options{Columns: queryColumns(r)})
+	if err != nil {
+		writeError(w, dumpErrorStatus(err), err)
+		return
+	}
+	defer it.Close()
+
+	// NULL values are null, so that they aren't taken for the text NULL.
+	preview := struct {
+		Columns []columnInfo `json:"columns"`
+		Rows    [][]*string  `json:"rows"`
+	}{Columns: []columnInfo{}, Rows: [][]*string{}}
+	for _, column := range it.Columns() {
+		preview.Columns = append(preview.Columns, columnInfo{Name: column.Name, Type: column.Type})
+	}
+	for len(preview.Rows) < rows {
+		record, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeError(w, dumpErrorStatus(err), err)
+			return
+		}
+		row := make([]*string, len(record))
+		for i := range record {
+			if !record[i].Null {
+				row[i] = &record[i].Value
+			}
+		}
+		preview.Rows = append(preview.Rows, row)
+	}
+	writeJSON(w, http.StatusOK, preview)
+}
+
+func (s *Server) handleFormats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string][]string{
+		"formats":  output.Formats(),
+		"dialects": extractor.Dialects(),
+	})
+}
+
+// lookup finds the dump named by the id path parameter, replying with 404 if there is none.
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) (*dumpEntry, bool) {
+	s.mu.RLock()
+	entry, ok := s.dumps[r.PathValue("id")]
+	s.mu.RUnlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("dump %s not found", r.PathValue("id")))
+	}
+	return entry, ok
+}
+
+// dumpErrorStatus returns the status of a response failing with an error of the extractor: 404
+// for tables the dump lacks, 422 for dumps that can't be parsed and 500 for those that can't be
+// read.
+func dumpErrorStatus(err error) int {
+	var notFound *extractor.TableNotFoundError
+	var parseErr *extractor.ParseError
+	switch {
+	case errors.As(err, &notFound):
+		return http.StatusNotFound
+	case errors.As(err, &parseErr):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+func queryDefault(r *http.Request, key, defaultValue string) string {
+	if value := r.URL.Query().Get(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// queryColumns returns the comma-separated columns parameter, or nil when all columns are wanted.
+func queryColumns(r *http.Request) []string {
+	if columnsParam := r.URL.Query().Get("columns"); columnsParam != "" {
+		return strings.Split(columnsParam, ",")
+	}
+	return nil
+}
+
+func newID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDump = "CREATE TABLE `users` (\n  `id` int,\n  `email` varchar(255)\n);\nINSERT INTO `users` VALUES (1,'a@x.com'),(2,NULL);\n"
+
+// testServer returns a server whose data directory holds dump.sql, and a file outside of it.
+func testServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	root := t.TempDir()
+	dataDir := filepath.Join(root, "data")
+	if err := os.Mkdir(dataDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{filepath.Join(dataDir, "dump.sql"), filepath.Join(root, "outside.sql")} {
+		if err := os.WriteFile(name, []byte(testDump), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := New(Config{DataDir: dataDir, MaxUploadSize: 1 << 20})
+	t.Cleanup(func() { s.Close() })
+	return s, root
+}
+
+// do sends a request to s, returning the status and the decoded JSON body.
+func do(t *testing.T, s *Server, method, target, body string) (int, map[string]any) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	var decoded map[string]any
+	json.Unmarshal(w.Body.Bytes(), &decoded)
+	return w.Code, decoded
+}
+
+func TestReferencedDumps(t *testing.T) {
+	s, root := testServer(t)
+	dataDir := filepath.Join(root, "data")
+	if err := os.Symlink(filepath.Join(dataDir, "dump.sql"), filepath.Join(dataDir, "inside.sql")); err != nil {
+		t.Skipf("symbolic links unavailable: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(root, "outside.sql"), filepath.Join(dataDir, "outside.sql")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(root, filepath.Join(dataDir, "parent")); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"dump.sql", http.StatusCreated},
+		{"inside.sql", http.StatusCreated},
+		{"../outside.sql", http.StatusBadRequest},
+		{"/etc/passwd", http.StatusBadRequest},
+		{"outside.sql", http.StatusBadRequest},
+		{"parent/outside.sql", http.StatusBadRequest},
+		{"parent/data/dump.sql", http.StatusCreated},
+		{"missing.sql", http.StatusBadRequest},
+		{".", http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		if status, body := do(t, s, "POST", "/api/dumps?path="+test.path, ""); status != test.want {
+			t.Errorf("loading %s: got status %d (%v), want %d", test.path, status, body, test.want)
+		}
+	}
+}
+
+func TestStatuses(t *testing.T) {
+	s, _ := testServer(t)
+	_, created := do(t, s, "POST", "/api/dumps?name=dump.sql", testDump)
+	id, _ := created["id"].(string)
+	_, broken := do(t, s, "POST", "/api/dumps?name=broken.sql.gz", testDump)
+	brokenID, _ := broken["id"].(string)
+	_, unparsable := do(t, s, "POST", "/api/dumps?name=unparsable.sql", "CREATE TABLE `users` (\n);\n")
+	unparsableID, _ := unparsable["id"].(string)
+	tests := []struct {
+		target string
+		want   int
+	}{
+		{"/api/dumps/" + id, http.StatusOK},
+		{"/api/dumps/" + id + "/tables/users/preview", http.StatusOK},
+		{"/api/dumps/" + id + "/tables/users/extract?format=csv", http.StatusOK},
+		{"/api/dumps/" + id + "/tables/orders/preview", http.StatusNotFound},
+		{"/api/dumps/" + id + "/tables/orders/extract", http.StatusNotFound},
+		{"/api/dumps/" + id + "/tables/users/extract?format=nope", http.StatusBadRequest},
+		{"/api/dumps/nope", http.StatusNotFound},
+		{"/api/dumps/nope/tables/users/preview", http.StatusNotFound},
+		// A dump named as gzip that isn't can't be read.
+		{"/api/dumps/" + brokenID + "/tables/users/preview", http.StatusInternalServerError},
+		{"/api/dumps/" + unparsableID + "/tables/users/preview", http.StatusUnprocessableEntity},
+		{"/api/dumps/" + unparsableID + "/tables", http.StatusUnprocessableEntity},
+	}
+	for _, test := range tests {
+		if status, body := do(t, s, "GET", test.target, ""); status != test.want {
+			t.Errorf("GET %s: got status %d (%v), want %d", test.target, status, body, test.want)
+		}
+	}
+}
+
+func TestUploadLimit(t *testing.T) {
+	s := New(Config{MaxUploadSize: 16})
+	defer s.Close()
+	if status, _ := do(t, s, "POST", "/api/dumps?name=dump.sql", testDump); status != http.StatusBadRequest {
+		t.Errorf("got status %d for an upload over the limit, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestPreviewNull(t *testing.T) {
+	s, _ := testServer(t)
+	_, created := do(t, s, "POST", "/api/dumps?name=dump.sql", "CREATE TABLE `users` (\n  `id` int,\n  `email` varchar(255)\n);\nINSERT INTO `users` VALUES (1,'NULL'),(2,NULL);\n")
+	id, _ := created["id"].(string)
+	status, body := do(t, s, "GET", "/api/dumps/"+id+"/tables/users/preview", "")
+	if status != http.StatusOK {
+		t.Fatalf("got status %d (%v), want %d", status, body, http.StatusOK)
+	}
+	want := []any{[]any{"1", "NULL"}, []any{"2", nil}}
+	if got := body["rows"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got rows %#v, want %#v", got, want)
+	}
+}
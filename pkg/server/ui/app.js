@@ -0,0 +1,154 @@
+"use strict";
+
+const state = { dump: null, table: null, columns: [] };
+
+const $ = (id) => document.getElementById(id);
+
+async function api(path, options) {
+  const response = await fetch(path, options);
+  if (!response.ok) {
+    const body = await response.json().catch(() => ({}));
+    throw new Error(body.error || response.statusText);
+  }
+  return response.status === 204 ? null : response.json();
+}
+
+function fillSelect(select, values, selected) {
+  select.replaceChildren(...values.map((value) => {
+    const option = new Option(value, value);
+    option.selected = value === selected;
+    return option;
+  }));
+}
+
+async function loadOptions() {
+  const info = await api("/api/formats");
+  fillSelect($("format-select"), info.formats, "json");
+  fillSelect($("dialect-select"), info.dialects, "mysql");
+}
+
+async function refreshDumps() {
+  const dumps = await api("/api/dumps");
+  $("dump-list").replaceChildren(...dumps.map((dump) => {
+    const item = document.createElement("li");
+    item.textContent = `${dump.name} (${(dump.size / 1024).toFixed(1)} KiB, ${dump.dialect})`;
+    item.classList.toggle("selected", state.dump === dump.id);
+    item.onclick = () => selectDump(dump.id);
+    return item;
+  }));
+}
+
+async function upload(file) {
+  $("upload-status").textContent = `Uploading ${file.name}…`;
+  try {
+    const params = new URLSearchParams({ name: file.name, dialect: $("dialect-select").value });
+    const dump = await api(`/api/dumps?${params}`, { method: "POST", body: file });
+    $("upload-status").textContent = "";
+    await refreshDumps();
+    await selectDump(dump.id);
+  } catch (err) {
+    $("upload-status").textContent = `Upload failed: ${err.message}`;
+  }
+}
+
+async function selectDump(id) {
+  state.dump = id;
+  state.table = null;
+  await refreshDumps();
+  const tables = await api(`/api/dumps/${id}/tables`);
+  $("browser").hidden = false;
+  $("table-view").hidden = true;
+  $("table-list").replaceChildren(...tables.map((table) => {
+    const item = document.createElement("li");
+    item.textContent = table.name;
+    item.onclick = () => selectTable(table, item);
+    return item;
+  }));
+}
+
+function selectTable(table, item) {
+  state.table = table.name;
+  state.columns = table.columns.map((column) => column.name);
+  for (const other of $("table-list").children) {
+    other.classList.toggle("selected", other === item);
+  }
+
+  $("table-view").hidden = false;
+  $("table-name").textContent = table.name;
+  $("column-list").replaceChildren(...table.columns.map((column) => {
+    const label = document.createElement("label");
+    const checkbox = document.createElement("input");
+    checkbox.type = "checkbox";
+    checkbox.checked = true;
+    checkbox.value = column.name;
+    checkbox.onchange = updateSelection;
+    label.append(checkbox, ` ${column.name} `);
+    const type = document.createElement("small");
+    type.textContent = column.type;
+    label.append(type);
+    return label;
+  }));
+  updateSelection();
+}
+
+function selectedColumns() {
+  return [...$("column-list").querySelectorAll("input:checked")].map((checkbox) => checkbox.value);
+}
+
+async function updateSelection() {
+  const columns = selectedColumns();
+  const params = new URLSearchParams({ format: $("format-select").value });
+  if (columns.length !== state.columns.length) {
+    params.set("columns", columns.join(","));
+  }
+  const base = `/api/dumps/${state.dump}/tables/${encodeURIComponent(state.table)}`;
+  $("download").href = `${base}/extract?${params}`;
+
+  const preview = await api(`${base}/preview?${new URLSearchParams({ columns: columns.join(","), rows: 20 })}`);
+  const header = document.createElement("tr");
+  header.append(...preview.columns.map((column) => {
+    const cell = document.createElement("th");
+    cell.textContent = column.name;
+    return cell;
+  }));
+  const rows = preview.rows.map((row) => {
+    const tr = document.createElement("tr");
+    tr.append(...row.map((value) => {
+      const cell = document.createElement("td");
+      if (value === null) {
+        cell.className = "null";
+        cell.textContent = "NULL";
+      } else {
+        cell.textContent = value;
+      }
+      return cell;
+    }));
+    return tr;
+  });
+  $("preview").replaceChildren(header, ...rows);
+}
+
+function setupDropzone() {
+  const zone = $("dropzone");
+  zone.addEventListener("dragover", (event) => {
+    event.preventDefault();
+    zone.classList.add("active");
+  });
+  zone.addEventListener("dragleave", () => zone.classList.remove("active"));
+  zone.addEventListener("drop", (event) => {
+    event.preventDefault();
+    zone.classList.remove("active");
+    if (event.dataTransfer.files.length > 0) {
+      upload(event.dataTransfer.files[0]);
+    }
+  });
+  $("file-input").addEventListener("change", (event) => {
+    if (event.target.files.length > 0) {
+      upload(event.target.files[0]);
+    }
+  });
+  $("format-select").addEventListener("change", () => state.table && updateSelection());
+}
+
+setupDropzone();
+loadOptions().then(refreshDumps);
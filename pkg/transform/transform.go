@@ -0,0 +1,159 @@
+// Package transform parses lists of transforms of the values of columns, such as those given to
+// -transform, and applies them to records.
+package transform
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/pipeline"
+)
+
+// transforms maps the names of transforms to the function transforming a value, which returns
+// false for values it doesn't apply to, such as invalid hexadecimal for hexdecode.
+var transforms = map[string]func(value string) (string, bool){
+	"hexdecode":    hexDecode,
+	"base64decode": base64Decode,
+	"unescape":     unescapeBackslashes,
+	"lower":        func(value string) (string, bool) { return strings.ToLower(value), true },
+	"trim":         func(value string) (string, bool) { return strings.TrimSpace(value), true },
+}
+
+// columnTransform is a transform of the values of a column.
+type columnTransform struct {
+	column string
+	name   string
+	apply  func(value string) (string, bool)
+}
+
+// Set is a parsed list of transforms.
+type Set struct {
+	text       string
+	transforms []*columnTransform
+}
+
+// Parse parses a comma-separated list of column=transform pairs, such as
+// "pass=hexdecode,email=lower". A column may be given several times, its transforms applying in
+// order. Transforms are hexdecode, base64decode, unescape, lower and trim.
+func Parse(text string) (*Set, error) {
+	set := &Set{text: text}
+	for _, pair := range strings.Split(text, ",") {
+		column, name, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		column, name = strings.TrimSpace(column), strings.TrimSpace(name)
+		if !ok || column == "" || name == "" {
+			return nil, fmt.Errorf("invalid transforms %q: expected column=transform, got %q", text, pair)
+		}
+		apply, ok := transforms[name]
+		if !ok {
+			names := make([]string, 0, len(transforms))
+			for name := range transforms {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("invalid transforms %q: unknown transform %s, expected one of %s", text, name, strings.Join(names, ", "))
+		}
+		set.transforms = append(set.transforms, &columnTransform{column: column, name: name, apply: apply})
+	}
+	return set, nil
+}
+
+// AddTo appends the transforms to the stages of p. It returns the columns they name that the
+// records of p lack, whose transforms do nothing. Values a transform doesn't apply to are left
+// as they are, counted by Pipeline.Untransformed and warned about once per table.
+func (t *Set) AddTo(p *pipeline.Pipeline) []string {
+	var missing []string
+	for _, transform := range t.transforms {
+		if extractor.ColumnIndex(p.Columns(), transform.column) < 0 && !slices.Contains(missing, transform.column) {
+			missing = append(missing, transform.column)
+		}
+	}
+	tableName := p.Table()
+	// Values a transform doesn't apply to are warned about once per table, and counted.
+	warned := make([]bool, len(t.transforms))
+	p.Add("transform "+t.text, pipeline.Stage{Reason: "transform", Apply: func(record extractor.Record) (extractor.Record, bool, error) {
+		for j, transform := range t.transforms {
+			for i, field := range record {
+				if field.Name != transform.column || field.Null {
+					continue
+				}
+				value, ok := transform.apply(field.Value)
+				if !ok {
+					p.CountUntransformed(transform.column + "=" + transform.name)
+					if !warned[j] {
+						warned[j] = true
+						slog.Warn(fmt.Sprintf("values of column %s of table %s that %s doesn't apply to are left as they are", transform.column, tableName, transform.name))
+					}
+					continue
+				}
+				record[i].Value = value
+			}
+		}
+		return record, true, nil
+	}})
+	return missing
+}
+
+// hexDecode decodes hexadecimal text, with or without the 0x prefix of MySQL's hexadecimal
+// literals.
+func hexDecode(value string) (string, bool) {
+	digits := value
+	if len(digits) > 2 && (digits[:2] == "0x" || digits[:2] == "0X") {
+		digits = digits[2:]
+	}
+	decoded, err := hex.DecodeString(digits)
+	if err != nil {
+		return value, false
+	}
+	return string(decoded), true
+}
+
+// base64Decode decodes standard or URL-safe base64, padded or not. Decoding is strict, the bits
+// past the last byte having to be zero, so that text such as zz isn't taken for base64.
+func base64Decode(value string) (string, bool) {
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if decoded, err := encoding.Strict().DecodeString(value); err == nil {
+			return string(decoded), true
+		}
+	}
+	return value, false
+}
+
+// unescapeBackslashes resolves the backslash escapes of MySQL strings left in a value, such as
+// \n, \t, \0, \' and \\, for values that were escaped twice before being dumped.
+func unescapeBackslashes(value string) (string, bool) {
+	if !strings.Contains(value, `\`) {
+		return value, true
+	}
+	var unescaped strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '\\' || i+1 == len(value) {
+			unescaped.WriteByte(c)
+			continue
+		}
+		i++
+		switch value[i] {
+		case '0':
+			unescaped.WriteByte(0)
+		case 'b':
+			unescaped.WriteByte('\b')
+		case 'n':
+			unescaped.WriteByte('\n')
+		case 'r':
+			unescaped.WriteByte('\r')
+		case 't':
+			unescaped.WriteByte('\t')
+		case 'Z':
+			unescaped.WriteByte(0x1a)
+		default:
+			unescaped.WriteByte(value[i])
+		}
+	}
+	return unescaped.String(), true
+}
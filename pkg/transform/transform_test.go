@@ -0,0 +1,106 @@
+package transform
+
+import (
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/pipeline"
+)
+
+func TestTransforms(t *testing.T) {
+	tests := []struct {
+		transform string
+		value     string
+		want      string
+		ok        bool
+	}{
+		{"hexdecode", "0x70617373", "pass", true},
+		{"hexdecode", "0X70617373", "pass", true},
+		{"hexdecode", "70617373", "pass", true},
+		{"hexdecode", "0x7", "0x7", false},
+		{"hexdecode", "zz", "zz", false},
+		{"base64decode", "cGFzcw==", "pass", true},
+		{"base64decode", "cGFzcw", "pass", true},
+		{"base64decode", "-_8=", "\xfb\xff", true},
+		{"base64decode", "zz", "zz", false},
+		{"base64decode", "not base64!", "not base64!", false},
+		{"unescape", `a\nb\tc\\d\'e`, "a\nb\tc\\d'e", true},
+		{"unescape", `\0\Z\q`, "\x00\x1aq", true},
+		{"unescape", `a\`, `a\`, true},
+		{"unescape", "plain", "plain", true},
+		{"lower", "Alice@Example.COM", "alice@example.com", true},
+		{"trim", " \tpass\n", "pass", true},
+	}
+	for _, test := range tests {
+		got, ok := transforms[test.transform](test.value)
+		if got != test.want || ok != test.ok {
+			t.Errorf("%s(%q) = %q, %v, want %q, %v", test.transform, test.value, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		err  string
+	}{
+		{"pass=hexdecode", ""},
+		{" pass = hexdecode , email=lower,email=trim", ""},
+		{"pass", `invalid transforms "pass": expected column=transform, got "pass"`},
+		{"=lower", `invalid transforms "=lower": expected column=transform, got "=lower"`},
+		{"pass=rot13", `invalid transforms "pass=rot13": unknown transform rot13, expected one of base64decode, hexdecode, lower, trim, unescape`},
+	}
+	for _, test := range tests {
+		var got string
+		if _, err := Parse(test.text); err != nil {
+			got = err.Error()
+		}
+		if got != test.err {
+			t.Errorf("Parse(%q) = %q, want %q", test.text, got, test.err)
+		}
+	}
+}
+
+func TestAddTo(t *testing.T) {
+	dump := "CREATE TABLE `users` (\n  `email` varchar(255),\n  `pass` varchar(255)\n);\n" +
+		"INSERT INTO `users` VALUES (' Ann@X.com','0x70617373'),('bob@x.com','zz'),('cy@x.com',NULL);\n"
+	set, err := Parse("email=trim,email=lower,pass=hexdecode,name=lower")
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err := extractor.New([]byte(dump)).Iterate("users", extractor.Options{})
+	if err != nil {
+		t.Fatalf("Iterate: %v", err)
+	}
+	defer it.Close()
+	p := pipeline.New(it)
+	if missing := set.AddTo(p); !slices.Equal(missing, []string{"name"}) {
+		t.Errorf("AddTo returned missing columns %q, want [name]", missing)
+	}
+
+	var got []string
+	for {
+		record, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		got = append(got, strings.Join(record.Values(), ","))
+		if record[1].Null && record[1].Value != "NULL" {
+			t.Errorf("NULL was transformed to %q", record[1].Value)
+		}
+	}
+	// Transforms of a column apply in order, and values they don't apply to are kept.
+	if want := []string{"ann@x.com,pass", "bob@x.com,zz", "cy@x.com,NULL"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := p.Untransformed(), map[string]int{"pass=hexdecode": 1}; !maps.Equal(got, want) {
+		t.Errorf("Untransformed() = %v, want %v", got, want)
+	}
+}
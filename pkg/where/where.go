@@ -0,0 +1,405 @@
+// Package where parses conditions on the values of records, such as those given to -where, and
+// filters records with them.
+package where
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/pipeline"
+)
+
+// Filter is a parsed condition.
+type Filter struct {
+	text string
+	expr whereExpr
+}
+
+// whereExpr is a node of a condition. Rows are matched by the names of their fields.
+type whereExpr interface {
+	match(record extractor.Record) bool
+	// columns appends the columns the condition refers to.
+	columns(names []string) []string
+}
+
+type whereAnd struct{ left, right whereExpr }
+
+func (e whereAnd) match(record extractor.Record) bool {
+	return e.left.match(record) && e.right.match(record)
+}
+
+func (e whereAnd) columns(names []string) []string {
+	return e.right.columns(e.left.columns(names))
+}
+
+type whereOr struct{ left, right whereExpr }
+
+func (e whereOr) match(record extractor.Record) bool {
+	return e.left.match(record) || e.right.match(record)
+}
+
+func (e whereOr) columns(names []string) []string {
+	return e.right.columns(e.left.columns(names))
+}
+
+type whereNot struct{ expr whereExpr }
+
+func (e whereNot) match(record extractor.Record) bool {
+	return !e.expr.match(record)
+}
+
+func (e whereNot) columns(names []string) []string {
+	return e.expr.columns(names)
+}
+
+// whereComparison compares a column with a value. As in SQL, comparisons with NULL never match,
+// so columns a table lacks never match either.
+type whereComparison struct {
+	column string
+	op     string
+	value  string
+	// number is set if value is a number, in which case values of the column that are numbers
+	// are compared numerically.
+	number   float64
+	isNumber bool
+	// pattern matches LIKE patterns and CONTAINS values.
+	pattern *regexp.Regexp
+}
+
+func (e whereComparison) match(record extractor.Record) bool {
+	field, ok := findField(record, e.column)
+	if !ok || field.Null {
+		return false
+	}
+	if e.pattern != nil {
+		return e.pattern.MatchString(field.Value)
+	}
+	cmp := strings.Compare(field.Value, e.value)
+	if e.isNumber {
+		if n, err := strconv.ParseFloat(field.Value, 64); err == nil {
+			cmp = 0
+			if n < e.number {
+				cmp = -1
+			} else if n > e.number {
+				cmp = 1
+			}
+		}
+	}
+	switch e.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	}
+	return cmp >= 0
+}
+
+func (e whereComparison) columns(names []string) []string {
+	return append(names, e.column)
+}
+
+type whereIsNull struct {
+	column string
+}
+
+func (e whereIsNull) match(record extractor.Record) bool {
+	field, ok := findField(record, e.column)
+	return !ok || field.Null
+}
+
+func (e whereIsNull) columns(names []string) []string {
+	return append(names, e.column)
+}
+
+func findField(record extractor.Record, name string) (extractor.Field, bool) {
+	for _, field := range record {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return extractor.Field{}, false
+}
+
+// Parse parses a condition, such as "active = 1 AND email LIKE '%@corp.com'". Columns are
+// compared with =, != (or <>), <, <=, >, >=, LIKE, CONTAINS and IS [NOT] NULL, and comparisons
+// combined with AND, OR, NOT and parentheses.
+func Parse(text string) (*Filter, error) {
+	tokens, err := whereTokens(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %s", text, err)
+	}
+	p := &whereParser{tokens: tokens}
+	expr, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %s", text, err)
+	}
+	return &Filter{text: text, expr: expr}, nil
+}
+
+// Text returns the condition as it was given to Parse.
+func (f *Filter) Text() string {
+	return f.text
+}
+
+// Match reports whether a record matches the condition. Its fields are found by name, and
+// columns the record lacks read as NULL.
+func (f *Filter) Match(record extractor.Record) bool {
+	return f.expr.match(record)
+}
+
+// Columns returns the columns the condition names, in order, each once.
+func (f *Filter) Columns() []string {
+	var names []string
+	for _, name := range f.expr.columns(nil) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// AddTo appends the filter to the stages of p, dropping the records it doesn't match under
+// "where". It returns the columns of the condition that the records of p lack, which are read
+// as NULL.
+func (f *Filter) AddTo(p *pipeline.Pipeline) []string {
+	var missing []string
+	for _, name := range f.Columns() {
+		if extractor.ColumnIndex(p.Columns(), name) < 0 {
+			missing = append(missing, name)
+		}
+	}
+	p.Add("where "+f.text, pipeline.Stage{Reason: "where", Apply: func(record extractor.Record) (extractor.Record, bool, error) {
+		return record, f.Match(record), nil
+	}})
+	return missing
+}
+
+// whereToken is a token of a condition. Quoted strings have kind 's', other words and numbers
+// 'w', operators and parentheses 'o'.
+type whereToken struct {
+	kind byte
+	text string
+}
+
+// keyword reports whether the token is the given keyword, written in any case.
+func (t whereToken) keyword(word string) bool {
+	return t.kind == 'w' && strings.EqualFold(t.text, word)
+}
+
+func whereTokens(text string) ([]whereToken, error) {
+	var tokens []whereToken
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'' || c == '"' || c == '`':
+			// Quotes are escaped by doubling them. Backticks quote column names.
+			var value strings.Builder
+			j := i + 1
+			for ; j < len(text); j++ {
+				if text[j] == c {
+					if j+1 < len(text) && text[j+1] == c {
+						value.WriteByte(c)
+						j++
+						continue
+					}
+					break
+				}
+				value.WriteByte(text[j])
+			}
+			if j == len(text) {
+				return nil, fmt.Errorf("unterminated %c", c)
+			}
+			kind := byte('s')
+			if c == '`' {
+				kind = 'w'
+			}
+			tokens = append(tokens, whereToken{kind: kind, text: value.String()})
+			i = j + 1
+		case c == '(' || c == ')' || c == '=':
+			tokens = append(tokens, whereToken{kind: 'o', text: string(c)})
+			i++
+		case c == '!' || c == '<' || c == '>':
+			n := 1
+			if i+1 < len(text) && (text[i+1] == '=' || c == '<' && text[i+1] == '>') {
+				n = 2
+			}
+			op := text[i : i+n]
+			switch op {
+			case "!":
+				return nil, fmt.Errorf("unexpected !")
+			case "<>":
+				op = "!="
+			}
+			tokens = append(tokens, whereToken{kind: 'o', text: op})
+			i += n
+		default:
+			j := i
+			for j < len(text) && !strings.ContainsRune(" \t\n\r'\"`()=!<>", rune(text[j])) {
+				j++
+			}
+			tokens = append(tokens, whereToken{kind: 'w', text: text[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// whereParser parses conditions by recursive descent. NOT binds tighter than AND, which binds
+// tighter than OR.
+type whereParser struct {
+	tokens []whereToken
+	pos    int
+}
+
+func (p *whereParser) peek() (whereToken, bool) {
+	if p.pos == len(p.tokens) {
+		return whereToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// accept consumes the next token if it is the given keyword or operator.
+func (p *whereParser) accept(text string) bool {
+	t, ok := p.peek()
+	if ok && (t.keyword(text) || t.kind == 'o' && t.text == text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *whereParser) or() (whereExpr, error) {
+	left, err := p.and()
+	for err == nil && p.accept("OR") {
+		var right whereExpr
+		if right, err = p.and(); err == nil {
+			left = whereOr{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *whereParser) and() (whereExpr, error) {
+	left, err := p.unary()
+	for err == nil && p.accept("AND") {
+		var right whereExpr
+		if right, err = p.unary(); err == nil {
+			left = whereAnd{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *whereParser) unary() (whereExpr, error) {
+	if p.accept("NOT") {
+		expr, err := p.unary()
+		return whereNot{expr}, err
+	}
+	if p.accept("(") {
+		expr, err := p.or()
+		if err == nil && !p.accept(")") {
+			err = fmt.Errorf("missing )")
+		}
+		return expr, err
+	}
+	return p.comparison()
+}
+
+func (p *whereParser) comparison() (whereExpr, error) {
+	column, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("missing condition")
+	}
+	if column.kind != 'w' {
+		return nil, fmt.Errorf("expected a column, got %s", column.text)
+	}
+	p.pos++
+
+	if p.accept("IS") {
+		not := p.accept("NOT")
+		if !p.accept("NULL") {
+			return nil, fmt.Errorf("expected NULL after IS")
+		}
+		var expr whereExpr = whereIsNull{column: column.text}
+		if not {
+			expr = whereNot{expr}
+		}
+		return expr, nil
+	}
+	not := p.accept("NOT")
+	op, ok := p.peek()
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("missing operator after %s", column.text)
+	case op.keyword("LIKE") || op.keyword("CONTAINS"):
+		op.text = strings.ToUpper(op.text)
+	case op.kind != 'o' || op.text == "(" || op.text == ")" || not:
+		return nil, fmt.Errorf("expected an operator after %s, got %s", column.text, op.text)
+	}
+	p.pos++
+	value, ok := p.peek()
+	if !ok || value.kind == 'o' {
+		return nil, fmt.Errorf("missing value after %s", op.text)
+	}
+	p.pos++
+
+	comparison := whereComparison{column: column.text, op: op.text, value: value.text}
+	switch op.text {
+	case "LIKE":
+		comparison.pattern = likePattern(value.text)
+	case "CONTAINS":
+		comparison.pattern = regexp.MustCompile("(?is)" + regexp.QuoteMeta(value.text))
+	default:
+		if value.kind == 'w' {
+			comparison.number, comparison.isNumber = parseNumber(value.text)
+		}
+	}
+	var expr whereExpr = comparison
+	if not {
+		// NOT LIKE doesn't match NULL either.
+		expr = whereAnd{whereNot{whereIsNull{column: column.text}}, whereNot{comparison}}
+	}
+	return expr, nil
+}
+
+// likePattern compiles a LIKE pattern, in which % matches any characters, _ one character and a
+// backslash escapes the next one. Matching ignores case, as with MySQL's default collations.
+func likePattern(like string) *regexp.Regexp {
+	var pattern strings.Builder
+	pattern.WriteString("(?is)^")
+	for i := 0; i < len(like); i++ {
+		switch c := like[i]; {
+		case c == '%':
+			pattern.WriteString(".*")
+		case c == '_':
+			pattern.WriteString(".")
+		case c == '\\' && i+1 < len(like):
+			i++
+			pattern.WriteString(regexp.QuoteMeta(like[i : i+1]))
+		default:
+			pattern.WriteString(regexp.QuoteMeta(like[i : i+1]))
+		}
+	}
+	pattern.WriteString("$")
+	return regexp.MustCompile(pattern.String())
+}
+
+func parseNumber(s string) (float64, bool) {
+	n, err := strconv.ParseFloat(s, 64)
+	return n, err == nil
+}
@@ -0,0 +1,135 @@
+package where
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+)
+
+// testRecord is matched by the conditions of TestMatch. Its name column is NULL and it has no
+// column named missing.
+var testRecord = extractor.Record{
+	{Name: "id", Value: "10"},
+	{Name: "email", Value: "Ann@Corp.com"},
+	{Name: "name", Value: "NULL", Null: true},
+	{Name: "created", Value: "2019-05-01"},
+	{Name: "note", Value: "it's 100%"},
+	{Name: "odd name", Value: "x"},
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{"id = 10", true},
+		{"id = 10.0", true},
+		{"id = '10.0'", false},
+		{"id != 10", false},
+		{"id <> 9", true},
+		{"id < 9", false},
+		{"id <= 10", true},
+		{"id > 9", true},
+		{"id >= 11", false},
+		// Compared as text, "10" sorts before "9".
+		{"id < '9'", true},
+		{"created >= '2019-01-01'", true},
+		{"created < '2019-01-01'", false},
+		{"email = 'ann@corp.com'", false},
+		{"email LIKE '%@corp.com'", true},
+		{"email like 'a__@%'", true},
+		{"email LIKE '%@corp'", false},
+		{"email NOT LIKE '%@corp.com'", false},
+		{`note LIKE '%100\%'`, true},
+		{`note LIKE '%10\%'`, false},
+		{"email CONTAINS 'CORP'", true},
+		{"email CONTAINS '.*'", false},
+		{"note = 'it''s 100%'", true},
+		{`note = "it's 100%"`, true},
+		{"name IS NULL", true},
+		{"name IS NOT NULL", false},
+		{"email is not null", true},
+		{"missing IS NULL", true},
+		// Comparisons with NULL are false, whichever the operator.
+		{"name = 'NULL'", false},
+		{"name != 'x'", false},
+		{"name NOT LIKE 'x'", false},
+		{"missing = 1", false},
+		{"NOT name = 'x'", true},
+		{"`odd name` = 'x'", true},
+		{"id = 10 AND email LIKE '%@corp.com'", true},
+		{"id = 9 AND email LIKE '%@corp.com'", false},
+		{"id = 9 OR email LIKE '%@corp.com'", true},
+		{"id = 9 OR id = 8", false},
+		// AND binds tighter than OR, and NOT tighter than AND.
+		{"id = 10 OR id = 9 AND id = 8", true},
+		{"(id = 10 OR id = 9) AND id = 8", false},
+		{"NOT id = 9 AND id = 10", true},
+		{"NOT (id = 10 AND id = 9)", true},
+	}
+	for _, test := range tests {
+		filter, err := Parse(test.condition)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.condition, err)
+			continue
+		}
+		if got := filter.Match(testRecord); got != test.want {
+			t.Errorf("%q matches %v, want %v", test.condition, got, test.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		condition string
+		err       string
+	}{
+		{"", "missing condition"},
+		{"id", "missing operator after id"},
+		{"id =", "missing value after ="},
+		{"id = )", "missing value after ="},
+		{"id 10", "expected an operator after id, got 10"},
+		{"id NOT = 10", "expected an operator after id, got ="},
+		{"'id' = 10", "expected a column, got id"},
+		{"id IS 10", "expected NULL after IS"},
+		{"id ! 10", "unexpected !"},
+		{"id = 'abc", "unterminated '"},
+		{"(id = 10", "missing )"},
+		{"id = 10)", "unexpected )"},
+		{"id = 10 AND", "missing condition"},
+		{"id = 10 id = 11", "unexpected id"},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.condition)
+		if err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error containing %q", test.condition, test.err)
+			continue
+		}
+		if want := "invalid condition"; !strings.HasPrefix(err.Error(), want) || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("Parse(%q) = %v, want an error containing %q", test.condition, err, test.err)
+		}
+	}
+}
+
+func TestColumns(t *testing.T) {
+	tests := []struct {
+		condition string
+		want      []string
+	}{
+		{"id = 1", []string{"id"}},
+		{"id = 1 OR (email LIKE 'a%' AND id > 3)", []string{"id", "email"}},
+		{"NOT name IS NULL AND `odd name` = 'x'", []string{"name", "odd name"}},
+		{"note NOT LIKE 'x'", []string{"note"}},
+	}
+	for _, test := range tests {
+		filter, err := Parse(test.condition)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", test.condition, err)
+		}
+		if got := filter.Columns(); !slices.Equal(got, test.want) {
+			t.Errorf("Columns of %q = %q, want %q", test.condition, got, test.want)
+		}
+	}
+}
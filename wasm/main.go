@@ -0,0 +1,197 @@
+//go:build js && wasm
+
+// Command wasm exposes the extractor to JavaScript when compiled to WebAssembly, so dumps can be
+// processed in a browser without leaving the machine:
+//
+//	GOOS=js GOARCH=wasm go build -o sql-data-extractor.wasm ./wasm
+//
+// It registers a global sqlDataExtractor object whose functions are wrapped by
+// sql-data-extractor.js. Every function returns an object with either its result or an error
+// property, the wrapper turning the latter into exceptions.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"syscall/js"
+
+	"github.com/elvisgraho/sql-data-extractor/pkg/extractor"
+	"github.com/elvisgraho/sql-data-extractor/pkg/output"
+)
+
+// Dumps opened from JavaScript, by handle. The dump is copied into Go memory once and then
+// referred to by its handle, instead of copying it again for every call.
+var (
+	dumps      = make(map[int]*extractor.Dump)
+	nextHandle = 1
+)
+
+func main() {
+	js.Global().Set("sqlDataExtractor", js.ValueOf(map[string]interface{}{
+		"open":    export(open),
+		"close":   export(closeDump),
+		"tables":  export(tables),
+		"columns": export(columns),
+		"extract": export(extract),
+		"formats": export(formats),
+		"dialects": export(func(args []js.Value) (interface{}, error) {
+			return stringsToJS(extractor.Dialects()), nil
+		}),
+	}))
+	// Keep the exported functions alive.
+	select {}
+}
+
+// export wraps fn into a JavaScript function returning {result} or {error}.
+func export(fn func(args []js.Value) (interface{}, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		result, err := fn(args)
+		if err != nil {
+			return map[string]interface{}{"error": err.Error()}
+		}
+		return map[string]interface{}{"result": result}
+	})
+}
+
+// open(content, dialect) loads a dump given as a string or Uint8Array and returns its handle.
+func open(args []js.Value) (interface{}, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("the dump content is required")
+	}
+	var content []byte
+	if args[0].Type() == js.TypeString {
+		content = []byte(args[0].String())
+	} else {
+		content = make([]byte, args[0].Get("length").Int())
+		js.CopyBytesToGo(content, args[0])
+	}
+
+	dump := extractor.New(content)
+	if len(args) > 1 && args[1].Type() == js.TypeString && args[1].String() != "" {
+		if err := dump.SetDialect(args[1].String()); err != nil {
+			return nil, err
+		}
+	}
+
+	handle := nextHandle
+	nextHandle++
+	dumps[handle] = dump
+	return handle, nil
+}
+
+// close(handle) releases a dump.
+func closeDump(args []js.Value) (interface{}, error) {
+	if _, err := lookupDump(args); err != nil {
+		return nil, err
+	}
+	delete(dumps, args[0].Int())
+	return nil, nil
+}
+
+// tables(handle) returns the names of the tables of a dump.
+func tables(args []js.Value) (interface{}, error) {
+	dump, err := lookupDump(args)
+	if err != nil {
+		return nil, err
+	}
+	names, err := dump.Tables()
+	if err != nil {
+		return nil, err
+	}
+	return stringsToJS(names), nil
+}
+
+// columns(handle, table) returns the columns of a table as {name, type} objects.
+func columns(args []js.Value) (interface{}, error) {
+	dump, err := lookupDump(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < 2 {
+		return nil, fmt.Errorf("a table name is required")
+	}
+	cols, err := dump.Columns(args[1].String())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]interface{}, len(cols))
+	for i, col := range cols {
+		result[i] = map[string]interface{}{"name": col.Name, "type": col.Type}
+	}
+	return result, nil
+}
+
+// extract(handle, table, {columns, format}) encodes the rows of a table and returns
+// {output, rows, malformed}.
+func extract(args []js.Value) (interface{}, error) {
+	dump, err := lookupDump(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < 2 {
+		return nil, fmt.Errorf("a table name is required")
+	}
+	tableName := args[1].String()
+
+	formatName := "json"
+	var opts extractor.Options
+	if len(args) > 2 && args[2].Type() == js.TypeObject {
+		if format := args[2].Get("format"); format.Type() == js.TypeString {
+			formatName = format.String()
+		}
+		switch cols := args[2].Get("columns"); cols.Type() {
+		case js.TypeString:
+			opts.Columns = strings.Split(cols.String(), ",")
+		case js.TypeObject:
+			for i := 0; i < cols.Length(); i++ {
+				opts.Columns = append(opts.Columns, cols.Index(i).String())
+			}
+		}
+	}
+	format, err := output.Lookup(formatName)
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := dump.Iterate(tableName, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var buf bytes.Buffer
+	count, err := output.Copy(format.New(&buf), tableName, it)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"output":    buf.String(),
+		"rows":      count,
+		"malformed": it.Malformed(),
+	}, nil
+}
+
+// formats() returns the names of the output formats.
+func formats(args []js.Value) (interface{}, error) {
+	return stringsToJS(output.Formats()), nil
+}
+
+func lookupDump(args []js.Value) (*extractor.Dump, error) {
+	if len(args) < 1 || args[0].Type() != js.TypeNumber {
+		return nil, fmt.Errorf("a dump handle is required")
+	}
+	dump, ok := dumps[args[0].Int()]
+	if !ok {
+		return nil, fmt.Errorf("unknown dump handle %d", args[0].Int())
+	}
+	return dump, nil
+}
+
+// stringsToJS converts a slice for js.ValueOf, which only accepts []interface{}.
+func stringsToJS(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
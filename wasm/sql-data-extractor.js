@@ -0,0 +1,82 @@
+// JavaScript wrapper of the WebAssembly build of sql-data-extractor.
+//
+// wasm_exec.js from the Go distribution must be loaded first, it defines the global Go class:
+//
+//   <script src="wasm_exec.js"></script>
+//   <script type="module">
+//     import { load } from "./sql-data-extractor.js";
+//     const extractor = await load("sql-data-extractor.wasm");
+//     const dump = extractor.open(await file.arrayBuffer());
+//     const users = dump.extract("users", { columns: ["email", "password"], format: "hashcat" });
+//     dump.close();
+//   </script>
+
+// call invokes a function exported by the Go module, turning its error into an exception.
+function call(api, name, ...args) {
+  const { result, error } = api[name](...args);
+  if (error !== undefined) {
+    throw new Error(error);
+  }
+  return result;
+}
+
+// Dump is a dump loaded into the WebAssembly module. Call close once done to free its memory.
+export class Dump {
+  constructor(api, handle) {
+    this.api = api;
+    this.handle = handle;
+  }
+
+  // tables returns the names of the tables of the dump.
+  tables() {
+    return call(this.api, "tables", this.handle);
+  }
+
+  // columns returns the columns of a table as {name, type} objects.
+  columns(table) {
+    return call(this.api, "columns", this.handle, table);
+  }
+
+  // extract encodes the rows of a table and returns {output, rows, malformed}. options.columns
+  // restricts the output to some columns, options.format selects the output format, json by default.
+  extract(table, options = {}) {
+    return call(this.api, "extract", this.handle, table, options);
+  }
+
+  close() {
+    call(this.api, "close", this.handle);
+  }
+}
+
+// Extractor gives access to the WebAssembly module once it is running.
+export class Extractor {
+  constructor(api) {
+    this.api = api;
+  }
+
+  // open loads a dump given as a string, ArrayBuffer or Uint8Array, optionally with its SQL dialect.
+  open(content, dialect = "") {
+    if (content instanceof ArrayBuffer) {
+      content = new Uint8Array(content);
+    }
+    return new Dump(this.api, call(this.api, "open", content, dialect));
+  }
+
+  // formats returns the names of the available output formats.
+  formats() {
+    return call(this.api, "formats");
+  }
+
+  // dialects returns the names of the supported SQL dialects.
+  dialects() {
+    return call(this.api, "dialects");
+  }
+}
+
+// load fetches and starts the WebAssembly module, resolving to an Extractor.
+export async function load(url = new URL("sql-data-extractor.wasm", import.meta.url)) {
+  const go = new Go();
+  const { instance } = await WebAssembly.instantiateStreaming(fetch(url), go.importObject);
+  go.run(instance);
+  return new Extractor(globalThis.sqlDataExtractor);
+}